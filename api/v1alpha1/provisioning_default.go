@@ -0,0 +1,128 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+)
+
+const (
+	// DefaultSensorCollectionInterval is the interval (in seconds) used
+	// when the Prometheus exporter is configured without one.
+	DefaultSensorCollectionInterval = 60
+
+	// DefaultImageCacheKeepVersions is the number of OS images kept on
+	// each node by the image cache, including the current one.
+	DefaultImageCacheKeepVersions = 2
+
+	// DefaultImageCacheMinFreeSpacePercent is the free space, in percent
+	// of the disk holding the image cache, below which the operator
+	// reports itself Degraded.
+	DefaultImageCacheMinFreeSpacePercent = 10
+
+	// defaultDHCPRangeStart and defaultDHCPRangeEnd are the offsets from
+	// the network address of ProvisioningNetworkCIDR of the default DHCP
+	// range, i.e. .10 to .100.
+	defaultDHCPRangeStart = 10
+	defaultDHCPRangeEnd   = 100
+
+	// DefaultDHCPRangeAnnotation holds the DHCP range filled in by the
+	// defaults, so that it is derived again from a changed
+	// ProvisioningNetworkCIDR as long as it has not been set by the user.
+	DefaultDHCPRangeAnnotation = "baremetal.openshift.io/default-dhcp-range"
+)
+
+// SetDefaults fills in the settings of the Provisioning CR that have a
+// default value, so that the stored object shows the values the operator
+// actually uses.
+func (prov *Provisioning) SetDefaults() {
+	prov.Spec.ProvisioningNetwork = prov.getProvisioningNetworkMode()
+
+	if prov.Spec.ProvisioningNetwork == ProvisioningNetworkManaged {
+		prov.setDefaultDHCPRange()
+	}
+
+	if prov.Spec.PrometheusExporter != nil && prov.Spec.PrometheusExporter.SensorCollectionInterval == 0 {
+		prov.Spec.PrometheusExporter.SensorCollectionInterval = DefaultSensorCollectionInterval
+	}
+
+	if prov.Spec.ImageCache != nil {
+		if prov.Spec.ImageCache.KeepVersions == 0 {
+			prov.Spec.ImageCache.KeepVersions = DefaultImageCacheKeepVersions
+		}
+		if prov.Spec.ImageCache.MinFreeSpacePercent == 0 {
+			prov.Spec.ImageCache.MinFreeSpacePercent = DefaultImageCacheMinFreeSpacePercent
+		}
+	}
+}
+
+// setDefaultDHCPRange fills in the DHCP range when it is not set, and
+// derives it again when it still is the default of a previous
+// ProvisioningNetworkCIDR. A range set by the user is left alone.
+func (prov *Provisioning) setDefaultDHCPRange() {
+	dhcpRange := prov.Spec.ProvisioningDHCPRange
+	if dhcpRange != "" && dhcpRange != prov.Annotations[DefaultDHCPRangeAnnotation] {
+		delete(prov.Annotations, DefaultDHCPRangeAnnotation)
+		return
+	}
+
+	prov.Spec.ProvisioningDHCPRange = defaultDHCPRange(prov.Spec.ProvisioningNetworkCIDR)
+	if prov.Spec.ProvisioningDHCPRange == "" {
+		delete(prov.Annotations, DefaultDHCPRangeAnnotation)
+		return
+	}
+	if prov.Annotations == nil {
+		prov.Annotations = map[string]string{}
+	}
+	prov.Annotations[DefaultDHCPRangeAnnotation] = prov.Spec.ProvisioningDHCPRange
+}
+
+// defaultDHCPRange returns the range going from .10 to .100 of the given
+// CIDR, or an empty string when the CIDR cannot be parsed or is too small to
+// hold that range.
+func defaultDHCPRange(networkCIDR string) string {
+	_, cidr, err := net.ParseCIDR(networkCIDR)
+	if err != nil {
+		return ""
+	}
+
+	start := offsetIP(cidr.IP, defaultDHCPRangeStart)
+	end := offsetIP(cidr.IP, defaultDHCPRangeEnd)
+	if end == nil || !cidr.Contains(end) || isNetworkOrBroadcastAddress(end, cidr) {
+		return ""
+	}
+
+	return fmt.Sprintf("%s,%s", start, end)
+}
+
+// offsetIP returns the address found offset addresses after ip, or nil if
+// that overflows the address space.
+func offsetIP(ip net.IP, offset int64) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	value := new(big.Int).SetBytes(ip)
+	value.Add(value, big.NewInt(offset))
+	if value.BitLen() > len(ip)*8 {
+		return nil
+	}
+
+	result := make(net.IP, len(ip))
+	value.FillBytes(result)
+	return result
+}
@@ -19,6 +19,12 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	operatorv1 "github.com/openshift/api/operator/v1"
@@ -41,6 +47,292 @@ const (
 	ProvisioningSingletonName = "provisioning-configuration"
 )
 
+// IronicAgent customizes the ironic-python-agent ramdisk booted on the
+// hosts
+type IronicAgent struct {
+	// ExtraKernelParams are additional kernel parameters of the
+	// ironic-python-agent ramdisk, separated by spaces, used whenever it
+	// boots. They cannot set the parameters managed by the operator.
+	ExtraKernelParams string `json:"extraKernelParams,omitempty"`
+
+	// SSHKey authorizes SSH keys in the ramdisk in addition to those of the
+	// install-config. They are picked up when changed.
+	SSHKey *RamdiskSSHKey `json:"sshKey,omitempty"`
+
+	// DebugSSHKey authorizes an SSH key in the ramdisk in addition to the
+	// other keys for a limited time, to debug hosts. Its expiry only stops
+	// authorizing it in the ramdisks booted afterwards: ramdisks already
+	// running, and images already built for them, keep authorizing it until
+	// the hosts boot again.
+	DebugSSHKey *DebugSSHKey `json:"debugSSHKey,omitempty"`
+
+	// HardwareManagers are container images holding custom
+	// ironic-python-agent hardware managers, layered on the ramdisk by the
+	// image customization controller.
+	// +listType=map
+	// +listMapKey=name
+	HardwareManagers []HardwareManager `json:"hardwareManagers,omitempty"`
+}
+
+// RamdiskSSHKey is an SSH public key, or the Secret holding SSH public
+// keys, authorized in the ironic-python-agent ramdisk
+type RamdiskSSHKey struct {
+	// PublicKey is the SSH public key, in the authorized_keys format.
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// SecretName is the name of a Secret of the openshift-machine-api
+	// namespace holding SSH public keys under its authorized_keys key, one
+	// per line, instead of PublicKey.
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// RamdiskSSHKeySource is where an SSH key authorized in the
+// ironic-python-agent ramdisk comes from
+// +kubebuilder:validation:Enum=InstallConfig;Provisioning;Secret;Debug
+type RamdiskSSHKeySource string
+
+const (
+	// RamdiskSSHKeySourceInstallConfig is the SSH key of the
+	// install-config.
+	RamdiskSSHKeySourceInstallConfig RamdiskSSHKeySource = "InstallConfig"
+	// RamdiskSSHKeySourceProvisioning is the key of the Provisioning
+	// spec.
+	RamdiskSSHKeySourceProvisioning RamdiskSSHKeySource = "Provisioning"
+	// RamdiskSSHKeySourceSecret is the key of the referenced Secret.
+	RamdiskSSHKeySourceSecret RamdiskSSHKeySource = "Secret"
+	// RamdiskSSHKeySourceDebug is the debug SSH key.
+	RamdiskSSHKeySourceDebug RamdiskSSHKeySource = "Debug"
+)
+
+// RamdiskSSHKeyStatus is an SSH key authorized in the ironic-python-agent
+// ramdisk, or rejected
+type RamdiskSSHKeyStatus struct {
+	// Fingerprint is the SHA256 fingerprint of the key, as shown by
+	// ssh-keygen -l, unless it cannot be parsed.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Source is where the key comes from.
+	Source RamdiskSSHKeySource `json:"source"`
+
+	// Expires is the time after which the key is no longer authorized, for
+	// the debug SSH key.
+	// +optional
+	Expires *metav1.Time `json:"expires,omitempty"`
+
+	// Error is why the key is not authorized, empty when it is.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// DebugSSHKey is an SSH public key authorized in the ironic-python-agent
+// ramdisk until it expires
+type DebugSSHKey struct {
+	// PublicKey is the SSH public key, in the authorized_keys format.
+	PublicKey string `json:"publicKey"`
+
+	// Expires is the time after which the key is no longer authorized. It
+	// can be at most 7 days in the future.
+	Expires metav1.Time `json:"expires"`
+}
+
+// HardwareManager is a container image holding a custom
+// ironic-python-agent hardware manager
+type HardwareManager struct {
+	// Name identifies the hardware manager.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Image is the container image holding the hardware manager, pulled
+	// with the cluster pull secret.
+	Image string `json:"image"`
+}
+
+// InspectionCollector is an ironic-python-agent collector gathering
+// inspection data
+// +kubebuilder:validation:Enum=extra-hardware;numa-topology;dmi-decode;pci-devices;usb-devices;logs
+type InspectionCollector string
+
+// InspectionHook is an Ironic hook processing inspection data
+// +kubebuilder:validation:Enum=extra-hardware;pci-devices;accelerators;cpu-capabilities;memory;boot-mode;raid-device;physical-network
+type InspectionHook string
+
+// Inspection configures the data gathered when inspecting hosts
+type Inspection struct {
+	// Collectors are the ironic-python-agent collectors run in addition
+	// to the default one.
+	// +listType=set
+	Collectors []InspectionCollector `json:"collectors,omitempty"`
+
+	// CollectLLDP gathers the LLDP data received on the host interfaces,
+	// and processes it to find the switch ports they are connected to.
+	CollectLLDP bool `json:"collectLLDP,omitempty"`
+
+	// Hooks are the Ironic hooks processing the inspection data, run in
+	// addition to the default ones.
+	// +listType=set
+	Hooks []InspectionHook `json:"hooks,omitempty"`
+
+	// ExtraKernelParams are additional kernel parameters of the
+	// ironic-python-agent ramdisk, separated by spaces, only used when it
+	// boots to inspect a host. They cannot set the parameters managed by
+	// the operator.
+	ExtraKernelParams string `json:"extraKernelParams,omitempty"`
+}
+
+// CleaningMode selects how hosts are cleaned automatically before being
+// provisioned and after being deprovisioned
+// +kubebuilder:validation:Enum=Metadata;Full
+type CleaningMode string
+
+// CleaningMode values
+const (
+	// CleaningModeMetadata erases the partition tables and filesystem
+	// signatures of the disks.
+	CleaningModeMetadata CleaningMode = "Metadata"
+	// CleaningModeFull erases the whole content of the disks.
+	CleaningModeFull CleaningMode = "Full"
+)
+
+// EraseMethod is a method used to erase the whole content of a disk
+// +kubebuilder:validation:Enum=ATASecureErase;NVMeSecureErase;Overwrite
+type EraseMethod string
+
+// EraseMethod values
+const (
+	EraseMethodATASecureErase  EraseMethod = "ATASecureErase"
+	EraseMethodNVMeSecureErase EraseMethod = "NVMeSecureErase"
+	// EraseMethodOverwrite overwrites the disks with random data, then
+	// zeros.
+	EraseMethodOverwrite EraseMethod = "Overwrite"
+)
+
+// CleaningPolicy configures the automated cleaning of the hosts
+type CleaningPolicy struct {
+	// Mode is how the hosts are cleaned. Defaults to Metadata. Whether a
+	// host is cleaned at all is still selected by its own
+	// automatedCleaningMode.
+	Mode CleaningMode `json:"mode,omitempty"`
+
+	// EraseMethods are the methods a Full cleaning may use. Secure erase is
+	// tried first on the disks supporting it, then the disks are
+	// overwritten if allowed. All methods are allowed when empty.
+	// +listType=set
+	EraseMethods []EraseMethod `json:"eraseMethods,omitempty"`
+}
+
+// IronicTuning tunes the Ironic conductor. Each setting left unset keeps
+// the Ironic default.
+type IronicTuning struct {
+	// DeployCallbackTimeout is the time (in seconds) Ironic waits for the
+	// agent on a host being deployed to call back before failing the
+	// deployment.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	DeployCallbackTimeout int `json:"deployCallbackTimeout,omitempty"`
+
+	// CleanCallbackTimeout is the time (in seconds) Ironic waits for the
+	// agent on a host being cleaned to call back before failing the
+	// cleaning.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	CleanCallbackTimeout int `json:"cleanCallbackTimeout,omitempty"`
+
+	// PowerStateSyncInterval is how often (in seconds) Ironic checks the
+	// power state of the hosts against their BMC.
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=86400
+	PowerStateSyncInterval int `json:"powerStateSyncInterval,omitempty"`
+
+	// PowerStateChangeTimeout is the time (in seconds) Ironic waits for a
+	// host to reach the requested power state.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=600
+	PowerStateChangeTimeout int `json:"powerStateChangeTimeout,omitempty"`
+
+	// MaxConcurrentDeploys is the number of hosts Ironic deploys at the
+	// same time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	MaxConcurrentDeploys int `json:"maxConcurrentDeploys,omitempty"`
+
+	// MaxConcurrentCleans is the number of hosts Ironic cleans at the same
+	// time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	MaxConcurrentCleans int `json:"maxConcurrentCleans,omitempty"`
+}
+
+// PersistentBootDevice selects whether hosts booted from a live ISO keep
+// it as their persistent boot device
+// +kubebuilder:validation:Enum=Default;Always;Never
+type PersistentBootDevice string
+
+// PersistentBootDevice values
+const (
+	PersistentBootDeviceDefault PersistentBootDevice = "Default"
+	PersistentBootDeviceAlways  PersistentBootDevice = "Always"
+	PersistentBootDeviceNever   PersistentBootDevice = "Never"
+)
+
+// DetachedDeleteAction selects what happens to a detached host when it is
+// deleted
+// +kubebuilder:validation:Enum=delay;delete
+type DetachedDeleteAction string
+
+// DetachedDeleteAction values
+const (
+	// DetachedDeleteActionDelay delays the deletion until the host is no
+	// longer detached.
+	DetachedDeleteActionDelay DetachedDeleteAction = "delay"
+	// DetachedDeleteActionDelete deletes the host right away.
+	DetachedDeleteActionDelete DetachedDeleteAction = "delete"
+)
+
+// BaremetalOperator configures the baremetal-operator
+type BaremetalOperator struct {
+	// MaxConcurrentReconciles is the number of hosts reconciled at the
+	// same time. The baremetal-operator picks it from the number of CPUs
+	// when it is not set.
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// ProvisioningLimit is the number of hosts that may be provisioned,
+	// inspected or deprovisioned at the same time. The other hosts wait
+	// for their turn. Defaults to 20.
+	// +kubebuilder:validation:Minimum=1
+	ProvisioningLimit int `json:"provisioningLimit,omitempty"`
+
+	// LiveISOPersistentBootDevice selects whether the hosts booted from a
+	// live ISO keep it as their persistent boot device. Defaults to Never.
+	LiveISOPersistentBootDevice PersistentBootDevice `json:"liveISOPersistentBootDevice,omitempty"`
+
+	// DetachedDeleteAction is what happens to detached hosts when they are
+	// deleted. It is set as the deleteAction of the detached annotation of
+	// the hosts that are detached without one, when they are written. The
+	// baremetal-operator delays the deletion when it is not set.
+	DetachedDeleteAction DetachedDeleteAction `json:"detachedDeleteAction,omitempty"`
+
+	// LogLevel is the verbosity of the baremetal-operator logs. Defaults
+	// to Normal.
+	LogLevel operatorv1.LogLevel `json:"logLevel,omitempty"`
+}
+
+// IronicProxyMode selects whether the Ironic API is served through
+// ironic-proxy
+// +kubebuilder:validation:Enum="";Auto;Enabled;Disabled
+type IronicProxyMode string
+
+// IronicProxyMode values
+const (
+	// IronicProxyAuto uses ironic-proxy when the provisioning network is
+	// Disabled or virtual media goes over the external network.
+	IronicProxyAuto     IronicProxyMode = "Auto"
+	IronicProxyEnabled  IronicProxyMode = "Enabled"
+	IronicProxyDisabled IronicProxyMode = "Disabled"
+)
+
 // BootIsoSource is the origin of the boot iso image
 // +kubebuilder:validation:Enum=local;http
 type BootIsoSource string
@@ -97,6 +389,71 @@ type PrometheusExporter struct {
 	DisableDefaultPrometheusRules bool `json:"disableDefaultPrometheusRules,omitempty"`
 }
 
+// ImageCache defines how the image cache manages the OS images stored on
+// the control plane nodes
+type ImageCache struct {
+	// MaxSize is the disk space the cached OS images may use on each node.
+	// The oldest images are removed first when it is exceeded. The image
+	// currently configured is never removed. There is no limit when it is
+	// not set.
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+
+	// KeepVersions is the number of OS images kept on each node, including
+	// the one currently configured. Older images are removed when the
+	// download URL changes.
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=1
+	KeepVersions int `json:"keepVersions,omitempty"`
+
+	// MinFreeSpacePercent is the free space, as a percentage of the
+	// filesystem holding the cache, below which the operator reports
+	// itself Degraded.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MinFreeSpacePercent int `json:"minFreeSpacePercent,omitempty"`
+
+	// NodeSelector selects the nodes running the image cache instead of
+	// the control plane nodes, for example a set of worker or infra nodes
+	// spread over the zones of the site.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the image cache pods, so that they can run
+	// on tainted nodes selected by NodeSelector.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// TopologyAwareURLs makes the operator rewrite the image URLs of the
+	// BareMetalHosts labelled with topology.kubernetes.io/zone that point
+	// to the image cache, so that they download their image from a cache
+	// running in the same zone. Only hosts that are not provisioned yet
+	// are changed, when they are written and when the image cache
+	// endpoints change. The image set on a host is kept in its
+	// baremetal.openshift.io/image-cache-source annotation, and restored
+	// when there is no cache in its zone anymore.
+	TopologyAwareURLs bool `json:"topologyAwareURLs,omitempty"`
+
+	// AdditionalImages are OS images cached in addition to the one from
+	// ProvisioningOSDownloadURL, for example other RHCOS versions or custom
+	// images referenced by BareMetalHosts. The metal3 pod downloads them
+	// and the image cache pre-fetches them from it, so that they are
+	// served at /images/<name>/<name>, where name is the file name of the
+	// image without its compression suffix. They are never removed by the
+	// garbage collection of the cache.
+	AdditionalImages []AdditionalImage `json:"additionalImages,omitempty"`
+}
+
+// AdditionalImage is an OS image pre-fetched by the image cache
+type AdditionalImage struct {
+	// URL is the location of the image. It must end in .qcow2 or .raw,
+	// optionally followed by .gz.
+	URL string `json:"url"`
+
+	// Checksum is the sha256 or sha512 checksum of the uncompressed image,
+	// hex encoded. The downloaded image is discarded when it does not
+	// match.
+	Checksum string `json:"checksum,omitempty"`
+}
+
 // ProvisioningSpec defines the desired state of Provisioning
 type ProvisioningSpec struct {
 	// ProvisioningInterface is the name of the network interface
@@ -111,6 +468,19 @@ type ProvisioningSpec struct {
 	// of each master.
 	ProvisioningMacAddresses []string `json:"provisioningMacAddresses,omitempty"`
 
+	// ProvisioningVLANID is the ID of the VLAN carrying the provisioning
+	// network. When set, a VLAN subinterface is created on top of
+	// ProvisioningVLANParentInterface, or on top of the interface matching
+	// ProvisioningMacAddresses when no parent interface is given, and the
+	// provisioning services bind to that subinterface. ProvisioningInterface,
+	// if set, is used as the name of the VLAN subinterface.
+	ProvisioningVLANID int32 `json:"provisioningVLANID,omitempty"`
+
+	// ProvisioningVLANParentInterface is the name of the interface, for
+	// example a bond, on which the provisioning VLAN is created. It is only
+	// used together with ProvisioningVLANID.
+	ProvisioningVLANParentInterface string `json:"provisioningVLANParentInterface,omitempty"`
+
 	// ProvisioningIP is the IP address assigned to the
 	// provisioningInterface of the baremetal server. This IP
 	// address should be within the provisioning subnet, and
@@ -162,9 +532,18 @@ type ProvisioningSpec struct {
 
 	// ProvisioningOSDownloadURL is the location from which the OS
 	// Image used to boot baremetal host machines can be downloaded
-	// by the metal3 cluster.
+	// by the metal3 cluster. The image is either a qcow2 or a raw
+	// image, uncompressed or compressed with gzip (.gz) or xz (.xz),
+	// which are the compressions the image downloader handles.
 	ProvisioningOSDownloadURL string `json:"provisioningOSDownloadURL,omitempty"`
 
+	// ProvisioningOSChecksumURL is the location of a checksum file, such
+	// as SHA256SUMS or SHA512SUMS, listing the checksum of the image at
+	// ProvisioningOSDownloadURL as it is downloaded, compressed or not.
+	// When set, ProvisioningOSDownloadURL does not need a sha256 or sha512
+	// parameter.
+	ProvisioningOSChecksumURL string `json:"provisioningOSChecksumURL,omitempty"`
+
 	// ProvisioningNetwork provides a way to indicate the state of the
 	// underlying network configuration for the provisioning network.
 	// This field can have one of the following values -
@@ -245,16 +624,342 @@ type ProvisioningSpec struct {
 	// ExternalIPs are the external-facing IP addresses used to access the Ironic service.
 	// Most users will not need this set. It is recommended to leave this unset unless
 	// actually necessary.
+	// At most one address of each IP family may be given. On dual-stack
+	// clusters, the IPv4 address is used for virtual media over IPv4 and the
+	// IPv6 address for virtual media over IPv6.
 	ExternalIPs []string `json:"externalIPs,omitempty"`
+
+	// ImageCache configures where the image cache runs, the control plane
+	// nodes by default, and how it manages the OS images it stores. By
+	// default the current image and the previous one are kept, and the
+	// operator reports itself Degraded when less than 10% of the disk
+	// holding the cache is free.
+	ImageCache *ImageCache `json:"imageCache,omitempty"`
+
+	// IronicProxy selects whether the Ironic API is served by ironic-proxy,
+	// running on every control plane node and forwarding the requests to
+	// the metal3-state service, so that hosts can reach it on any of these
+	// nodes. The service, not the proxy, balances the requests across the
+	// Ironic endpoints that pass their readiness probe. Auto, the default, uses it when ProvisioningNetwork is Disabled or
+	// VirtualMediaViaExternalNetwork is set. It cannot be Enabled when the
+	// control plane is external, as with HyperShift.
+	IronicProxy IronicProxyMode `json:"ironicProxy,omitempty"`
+
+	// BaremetalOperator configures the baremetal-operator: how many hosts
+	// it reconciles and provisions at the same time, the persistent boot
+	// device of hosts booted from a live ISO, what happens to deleted
+	// detached hosts and its log level.
+	BaremetalOperator *BaremetalOperator `json:"baremetalOperator,omitempty"`
+
+	// IronicTuning tunes the Ironic conductor for large fleets: deployment
+	// and cleaning timeouts, the power state sync interval and the number
+	// of hosts deployed or cleaned at the same time. Only these settings
+	// can be changed, each within a supported range.
+	IronicTuning *IronicTuning `json:"ironicTuning,omitempty"`
+
+	// CleaningPolicy configures the automated cleaning of the hosts before
+	// they are provisioned and after they are deprovisioned: whether only
+	// the disk metadata or their whole content is erased and which erase
+	// methods may be used. It applies to the hosts whose
+	// automatedCleaningMode is not disabled. The time a cleaning may take
+	// is set by ironicTuning.cleanCallbackTimeout.
+	CleaningPolicy *CleaningPolicy `json:"cleaningPolicy,omitempty"`
+
+	// Inspection configures the data gathered when inspecting hosts, which
+	// ends up in their HardwareData: the additional ironic-python-agent
+	// collectors and Ironic hooks to run, LLDP collection and additional
+	// kernel parameters of the ironic-python-agent ramdisk booted for
+	// inspection.
+	Inspection *Inspection `json:"inspection,omitempty"`
+
+	// IronicAgent customizes the ironic-python-agent ramdisk booted on the
+	// hosts: additional kernel parameters, SSH keys authorized in it in
+	// addition to those of the install-config, an SSH key authorized for a
+	// limited time to debug hosts, which stays authorized in the ramdisks
+	// booted before it expires, and custom hardware managers layered on the
+	// ramdisk. The fingerprints of the authorized SSH keys are reported in
+	// the status.
+	IronicAgent *IronicAgent `json:"ironicAgent,omitempty"`
+}
+
+// ProvisioningVLANInterface returns the name of the interface the
+// provisioning services bind to. Without a provisioning VLAN this is simply
+// ProvisioningInterface.
+func (spec *ProvisioningSpec) ProvisioningVLANInterface() string {
+	if spec.ProvisioningVLANID == 0 || spec.ProvisioningInterface != "" {
+		return spec.ProvisioningInterface
+	}
+	if spec.ProvisioningVLANParentInterface != "" {
+		return fmt.Sprintf("%s.%d", spec.ProvisioningVLANParentInterface, spec.ProvisioningVLANID)
+	}
+	return fmt.Sprintf("vlan%d", spec.ProvisioningVLANID)
+}
+
+// ProvisioningInterfaceState is the outcome of looking for a network
+// interface usable for provisioning on a control plane node
+// +kubebuilder:validation:Enum=Found;Missing;Unknown
+type ProvisioningInterfaceState string
+
+// ProvisioningInterfaceState values
+const (
+	// ProvisioningInterfaceFound means the node has an interface matching
+	// ProvisioningInterface or one of ProvisioningMacAddresses.
+	ProvisioningInterfaceFound ProvisioningInterfaceState = "Found"
+	// ProvisioningInterfaceMissing means the hardware inventory of the node
+	// has no interface matching ProvisioningInterface or
+	// ProvisioningMacAddresses.
+	ProvisioningInterfaceMissing ProvisioningInterfaceState = "Missing"
+	// ProvisioningInterfaceUnknown means the node could not be checked,
+	// usually because no hardware inventory is available for it.
+	ProvisioningInterfaceUnknown ProvisioningInterfaceState = "Unknown"
+)
+
+// ProvisioningInterfaceStatus reports whether a control plane node has a
+// network interface that can be used for the provisioning network
+type ProvisioningInterfaceStatus struct {
+	// NodeName is the name of the control plane node.
+	NodeName string `json:"nodeName"`
+
+	// State is the result of the check for this node.
+	State ProvisioningInterfaceState `json:"state"`
+
+	// Interface is the name of the matching network interface, if any.
+	Interface string `json:"interface,omitempty"`
+
+	// MACAddress is the MAC address of the matching network interface,
+	// if any.
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// Message is a human readable explanation of the state.
+	Message string `json:"message,omitempty"`
+}
+
+// ExternalURLConsumer identifies a component receiving an external URL
+// +kubebuilder:validation:Enum=ImageCustomization;VirtualMedia;VirtualMediaIPv6
+type ExternalURLConsumer string
+
+// ExternalURLConsumer values
+const (
+	// ExternalURLConsumerImageCustomization is the Ironic API URL passed to
+	// the image customization controller, which embeds it in the images it
+	// builds.
+	ExternalURLConsumerImageCustomization ExternalURLConsumer = "ImageCustomization"
+	// ExternalURLConsumerVirtualMedia is the URL from which hosts download
+	// virtual media images over IPv4.
+	ExternalURLConsumerVirtualMedia ExternalURLConsumer = "VirtualMedia"
+	// ExternalURLConsumerVirtualMediaIPv6 is the URL from which hosts
+	// download virtual media images over IPv6.
+	ExternalURLConsumerVirtualMediaIPv6 ExternalURLConsumer = "VirtualMediaIPv6"
+)
+
+// ExternalURL is an external URL of the metal3 services together with the
+// component it is given to
+type ExternalURL struct {
+	// Consumer is the component receiving the URL.
+	Consumer ExternalURLConsumer `json:"consumer"`
+
+	// URL is the URL given to the consumer. Several URLs are separated by
+	// commas.
+	URL string `json:"url"`
+}
+
+// OSImageFormat is the disk format of the OS image
+// +kubebuilder:validation:Enum=qcow2;raw
+type OSImageFormat string
+
+// OSImageFormat values
+const (
+	OSImageFormatQCOW2 OSImageFormat = "qcow2"
+	OSImageFormatRaw   OSImageFormat = "raw"
+)
+
+// OSImageCompression is the compression applied to the OS image
+// +kubebuilder:validation:Enum=none;gzip;xz
+type OSImageCompression string
+
+// OSImageCompression values
+const (
+	OSImageCompressionNone OSImageCompression = "none"
+	OSImageCompressionGzip OSImageCompression = "gzip"
+	OSImageCompressionXZ   OSImageCompression = "xz"
+)
+
+// osImageCompressionSuffixes maps the file suffixes of compressed images to
+// their compression.
+var osImageCompressionSuffixes = map[string]OSImageCompression{
+	".gz": OSImageCompressionGzip,
+	".xz": OSImageCompressionXZ,
+}
+
+// OSImageFormatFromPath returns the format and the compression of the OS
+// image with the given file name, e.g. rhcos.qcow2.gz or rhcos.raw. It
+// returns false when the name matches no supported format.
+func OSImageFormatFromPath(name string) (OSImageFormat, OSImageCompression, bool) {
+	compression := OSImageCompressionNone
+	ext := path.Ext(name)
+	if c, ok := osImageCompressionSuffixes[ext]; ok {
+		compression = c
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	switch format := OSImageFormat(strings.TrimPrefix(path.Ext(name), ".")); format {
+	case OSImageFormatQCOW2, OSImageFormatRaw:
+		return format, compression, true
+	}
+	return "", "", false
+}
+
+// OSImageStatus describes the OS image downloaded from
+// ProvisioningOSDownloadURL
+type OSImageStatus struct {
+	// Format is the disk format of the image.
+	Format OSImageFormat `json:"format"`
+
+	// Compression is the compression of the downloaded file. The image
+	// cache serves the image uncompressed.
+	Compression OSImageCompression `json:"compression"`
+
+	// CachedName is the name under which the image cache serves the image.
+	CachedName string `json:"cachedName"`
+}
+
+// ImageCacheNodeStatus reports the disk usage of the image cache on a node
+type ImageCacheNodeStatus struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName"`
+
+	// CachedImages is the number of OS images stored on the node.
+	CachedImages int `json:"cachedImages,omitempty"`
+
+	// UsedBytes is the disk space used by the cached OS images.
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+
+	// CapacityBytes is the size of the filesystem holding the cache.
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+
+	// AvailableBytes is the free space left on the filesystem holding the
+	// cache.
+	AvailableBytes int64 `json:"availableBytes,omitempty"`
+
+	// LowDiskSpace is set when the free space is below
+	// ImageCache.MinFreeSpacePercent.
+	LowDiskSpace bool `json:"lowDiskSpace,omitempty"`
+
+	// Message explains why the disk usage of the node is unknown.
+	Message string `json:"message,omitempty"`
+
+	// Content reports the state of the image from ProvisioningOSDownloadURL
+	// on the node. The image cache of the node is only ready to serve
+	// images once it is Verified, or Ready when the image has no checksum
+	// to verify it against.
+	Content *CachedImageStatus `json:"content,omitempty"`
+
+	// Images reports the state of ImageCache.AdditionalImages on the node.
+	Images []CachedImageStatus `json:"images,omitempty"`
 }
 
+// CachedImageState is the state of an image in the image cache of a node
+// +kubebuilder:validation:Enum=Missing;Pending;Downloading;Ready;Verified;ChecksumMismatch;Failed
+type CachedImageState string
+
+const (
+	// CachedImageMissing means the image is not on the node.
+	CachedImageMissing CachedImageState = "Missing"
+	// CachedImagePending means the image is not available yet from the
+	// metal3 pod.
+	CachedImagePending CachedImageState = "Pending"
+	// CachedImageDownloading means the image is being downloaded.
+	CachedImageDownloading CachedImageState = "Downloading"
+	// CachedImageReady means the image is cached. It has no checksum to
+	// verify it against.
+	CachedImageReady CachedImageState = "Ready"
+	// CachedImageVerified means the image is cached and matches its
+	// checksum.
+	CachedImageVerified CachedImageState = "Verified"
+	// CachedImageChecksumMismatch means the downloaded image did not
+	// match its checksum and was discarded. The download is retried,
+	// waiting longer after each failure.
+	CachedImageChecksumMismatch CachedImageState = "ChecksumMismatch"
+	// CachedImageFailed means the download failed. It is retried, waiting
+	// longer after each failure.
+	CachedImageFailed CachedImageState = "Failed"
+)
+
+// CachedImageStatus reports the state of an image in the image cache of a
+// node
+type CachedImageStatus struct {
+	// Name is the name under which the image is served.
+	Name string `json:"name"`
+
+	// State is the state of the image on the node.
+	State CachedImageState `json:"state"`
+
+	// DownloadedBytes is the amount of data downloaded so far.
+	DownloadedBytes int64 `json:"downloadedBytes,omitempty"`
+
+	// TotalBytes is the size of the image, when known.
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+}
+
+// ImageCacheEndpoint lists the image cache endpoints of a zone
+type ImageCacheEndpoint struct {
+	// Zone is the value of the topology.kubernetes.io/zone label of the
+	// nodes, empty for nodes without the label.
+	Zone string `json:"zone,omitempty"`
+
+	// URLs are the base URLs of the image cache on the nodes of the zone.
+	URLs []string `json:"urls"`
+}
+
+const (
+	// OSImagesAvailableCondition is the type of the status condition
+	// reporting whether the OS images configured in the spec can be
+	// downloaded.
+	OSImagesAvailableCondition = "OSImagesAvailable"
+
+	// VerifyImageChecksumsAnnotation makes the operator download the OS
+	// images and compare them against their checksum when set to "true".
+	VerifyImageChecksumsAnnotation = "baremetal.openshift.io/verify-image-checksums"
+)
+
 // ProvisioningStatus defines the observed state of Provisioning
 type ProvisioningStatus struct {
 	operatorv1.OperatorStatus `json:",inline"`
+
+	// ProvisioningInterfaces reports, for each control plane node, whether
+	// a network interface usable for the provisioning network was found.
+	// The metal3 pod is not scheduled on nodes where the interface is
+	// Missing.
+	ProvisioningInterfaces []ProvisioningInterfaceStatus `json:"provisioningInterfaces,omitempty"`
+
+	// ExternalURLs lists the URLs derived from ExternalIPs, or from the
+	// cluster network configuration when ExternalIPs is not set, that are
+	// handed to each consumer.
+	ExternalURLs []ExternalURL `json:"externalURLs,omitempty"`
+
+	// OSImage describes the image downloaded from
+	// ProvisioningOSDownloadURL, when it is set.
+	OSImage *OSImageStatus `json:"osImage,omitempty"`
+
+	// ImageCache reports the disk usage of the image cache on each node
+	// it runs on.
+	ImageCache []ImageCacheNodeStatus `json:"imageCache,omitempty"`
+
+	// ImageCacheEndpoints lists, for each zone, the image cache endpoints
+	// ready to serve images.
+	ImageCacheEndpoints []ImageCacheEndpoint `json:"imageCacheEndpoints,omitempty"`
+
+	// IronicProxy is the ironic-proxy mode in effect, Enabled or Disabled.
+	IronicProxy IronicProxyMode `json:"ironicProxy,omitempty"`
+
+	// RamdiskSSHKeys lists the SSH keys authorized in the
+	// ironic-python-agent ramdisk, and the keys rejected.
+	RamdiskSSHKeys []RamdiskSSHKeyStatus `json:"ramdiskSSHKeys,omitempty"`
 }
 
 // +kubebuilder:resource:path=provisionings,scope=Cluster
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 
 // Provisioning contains configuration used by the Provisioning
 // service (Ironic) to provision baremetal hosts.
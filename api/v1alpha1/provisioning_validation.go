@@ -17,19 +17,44 @@ package v1alpha1
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
 	"net"
 	"net/url"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
 )
 
 type EnabledFeatures struct {
 	ProvisioningNetwork map[ProvisioningNetwork]bool
+
+	// IPFamilies are the IP families of the cluster service network. IP
+	// addresses are not checked against them when empty.
+	IPFamilies map[corev1.IPFamily]bool
+
+	// IronicProxy tells whether ironic-proxy can run, which requires the
+	// control plane nodes to be part of the cluster.
+	IronicProxy bool
 }
 
+// imageReferenceRegexp loosely matches a container image reference:
+// an optional registry host and port, a repository path, and an optional
+// tag and/or digest.
+var imageReferenceRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9][a-zA-Z0-9.-]*(?::[0-9]+)?/)?[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+
 var (
 	log = ctrl.Log.WithName("provisioning_validation")
 )
@@ -66,7 +91,39 @@ func (prov *Provisioning) ValidateBaremetalProvisioningConfig(enabledFeatures En
 	}
 
 	// They all use provisioningOSDownloadURL
-	if err := validateProvisioningOSDownloadURL(prov.Spec.ProvisioningOSDownloadURL); err != nil {
+	if err := validateProvisioningOSDownloadURL(prov.Spec.ProvisioningOSDownloadURL, prov.Spec.ProvisioningOSChecksumURL); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateProvisioningVLAN(&prov.Spec, provisioningNetworkMode); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateImageCache(prov.Spec.ImageCache, prov.Spec.ProvisioningOSDownloadURL); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateIronicProxy(prov.Spec.IronicProxy, enabledFeatures.IronicProxy); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateBaremetalOperator(prov.Spec.BaremetalOperator); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateIronicTuning(prov.Spec.IronicTuning); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateCleaningPolicy(prov.Spec.CleaningPolicy); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateInspection(prov.Spec.Inspection); err != nil {
+		errs = append(errs, err...)
+	}
+
+	if err := validateIronicAgent(prov.Spec.IronicAgent, time.Now()); err != nil {
 		errs = append(errs, err...)
 	}
 
@@ -106,6 +163,70 @@ func (prov *Provisioning) ValidateBaremetalProvisioningConfig(enabledFeatures En
 
 // isNetworkOrBroadcastAddress checks if the IP is a network or broadcast address
 // For IPv4 with masks narrower than /31, network and broadcast addresses are not usable
+// fieldCheck validates a field checked only since CRs may have been stored
+// without the check.
+type fieldCheck struct {
+	value    func(spec *ProvisioningSpec) interface{}
+	validate func(spec *ProvisioningSpec, enabledFeatures EnabledFeatures) []error
+}
+
+var fieldChecks = []fieldCheck{
+	{
+		value: func(spec *ProvisioningSpec) interface{} { return spec.ExternalIPs },
+		validate: func(spec *ProvisioningSpec, enabledFeatures EnabledFeatures) []error {
+			return validateExternalIPs(spec.ExternalIPs, enabledFeatures.IPFamilies)
+		},
+	},
+	{
+		value: func(spec *ProvisioningSpec) interface{} { return spec.AdditionalNTPServers },
+		validate: func(spec *ProvisioningSpec, _ EnabledFeatures) []error {
+			return validateNTPServers(spec.AdditionalNTPServers)
+		},
+	},
+	{
+		value: func(spec *ProvisioningSpec) interface{} { return spec.PreProvisioningOSDownloadURLs },
+		validate: func(spec *ProvisioningSpec, _ EnabledFeatures) []error {
+			return validatePreProvisioningOSDownloadURLs(&spec.PreProvisioningOSDownloadURLs)
+		},
+	},
+	{
+		value: func(spec *ProvisioningSpec) interface{} { return spec.UnsupportedConfigOverrides },
+		validate: func(spec *ProvisioningSpec, _ EnabledFeatures) []error {
+			return validateUnsupportedConfigOverrides(spec.UnsupportedConfigOverrides)
+		},
+	},
+}
+
+// ValidateChangedFields runs the checks added after Provisioning CRs may
+// have been stored without them. The fields set on creation, when old is
+// nil, or changed by an update return errors, the others only warnings, so
+// that an upgrade does not make an existing CR invalid. Passing the CR
+// itself as old only warns.
+func (prov *Provisioning) ValidateChangedFields(old *Provisioning, enabledFeatures EnabledFeatures) ([]string, error) {
+	var warnings []string
+	var errs []error
+
+	for _, check := range fieldChecks {
+		checkErrs := check.validate(&prov.Spec, enabledFeatures)
+		if old == nil || !equality.Semantic.DeepEqual(check.value(&old.Spec), check.value(&prov.Spec)) {
+			errs = append(errs, checkErrs...)
+			continue
+		}
+		for _, err := range checkErrs {
+			warnings = append(warnings, err.Error())
+		}
+	}
+
+	return warnings, errors.NewAggregate(errs)
+}
+
+func validateUnsupportedConfigOverrides(overrides *UnsupportedConfigOverrides) []error {
+	if overrides != nil && overrides.IronicAgentImage != "" && !imageReferenceRegexp.MatchString(overrides.IronicAgentImage) {
+		return []error{fmt.Errorf("unsupportedConfigOverrides.ironicAgentImage %q is not a valid image reference", overrides.IronicAgentImage)}
+	}
+	return nil
+}
+
 func isNetworkOrBroadcastAddress(ip net.IP, cidr *net.IPNet) bool {
 	ones, bits := cidr.Mask.Size()
 	// Only apply network/broadcast checks to IPv4.
@@ -145,36 +266,573 @@ func (prov *Provisioning) getProvisioningNetworkMode() ProvisioningNetwork {
 	return provisioningNetworkMode
 }
 
-func validateProvisioningOSDownloadURL(uri string) []error {
+func validateProvisioningOSDownloadURL(uri, checksumURL string) []error {
 	var errs []error
 
+	if checksumURL != "" {
+		if uri == "" {
+			errs = append(errs, fmt.Errorf("provisioningOSChecksumURL requires provisioningOSDownloadURL to be set"))
+		}
+		if _, err := validateHTTPURL("provisioningOSChecksumURL", checksumURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if uri == "" {
 		return errs
 	}
 
+	parsedURL, urlErrs := validateChecksummedURL("provisioningOSDownloadURL", uri, checksumURL == "")
+	errs = append(errs, urlErrs...)
+	if parsedURL == nil {
+		return errs
+	}
+	if checksumURL != "" && hasChecksumParameter(parsedURL) {
+		errs = append(errs, fmt.Errorf("the provisioningOSDownloadURL %q must not have a checksum parameter when provisioningOSChecksumURL is set", uri))
+	}
+	if _, _, ok := OSImageFormatFromPath(path.Base(parsedURL.Path)); !ok {
+		errs = append(errs, fmt.Errorf("the provisioningOSDownloadURL provided: %q is an OS image and must end in .qcow2 or .raw, optionally followed by .gz or .xz", uri))
+	}
+
+	return errs
+}
+
+type fieldURL struct {
+	field string
+	uri   string
+}
+
+// preProvisioningOSDownloadURLs returns the URLs set in urls along with the
+// name of their field.
+func preProvisioningOSDownloadURLs(urls *PreProvisioningOSDownloadURLs) []fieldURL {
+	var set []fieldURL
+	for _, u := range []fieldURL{
+		{"preProvisioningOSDownloadURLs.isoURL", urls.IsoURL},
+		{"preProvisioningOSDownloadURLs.kernelURL", urls.KernelURL},
+		{"preProvisioningOSDownloadURLs.initramfsURL", urls.InitramfsURL},
+		{"preProvisioningOSDownloadURLs.rootfsURL", urls.RootfsURL},
+	} {
+		if u.uri != "" {
+			set = append(set, u)
+		}
+	}
+	return set
+}
+
+// validatePreProvisioningOSDownloadURLs does not require the checksums, as
+// the CRs created before they were checked may not have them. Their absence
+// is only warned about, see MissingChecksumWarnings.
+func validatePreProvisioningOSDownloadURLs(urls *PreProvisioningOSDownloadURLs) []error {
+	var errs []error
+
+	for _, u := range preProvisioningOSDownloadURLs(urls) {
+		_, urlErrs := validateChecksummedURL(u.field, u.uri, false)
+		errs = append(errs, urlErrs...)
+	}
+
+	return errs
+}
+
+// MissingChecksumWarnings returns a warning for each pre-provisioning OS
+// image URL that carries no checksum, so its image cannot be verified.
+func (prov *Provisioning) MissingChecksumWarnings() []string {
+	var warnings []string
+	for _, u := range preProvisioningOSDownloadURLs(&prov.Spec.PreProvisioningOSDownloadURLs) {
+		if parsedURL, err := url.Parse(u.uri); err == nil && !hasChecksumParameter(parsedURL) {
+			warnings = append(warnings, fmt.Sprintf("the sha256 or sha512 parameter in the %s %q is missing, the image cannot be verified", u.field, u.uri))
+		}
+	}
+	return warnings
+}
+
+// checksumParameters maps the URL parameters that can carry the checksum of
+// an image to the length of their hex encoded value.
+var checksumParameters = []struct {
+	name   string
+	length int
+}{
+	{"sha256", 64},
+	{"sha512", 128},
+}
+
+var hexRegexp = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+func hasChecksumParameter(parsedURL *url.URL) bool {
+	for _, param := range checksumParameters {
+		if parsedURL.Query().Has(param.name) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateHTTPURL(field, uri string) (*url.URL, error) {
 	parsedURL, err := url.ParseRequestURI(uri)
 	if err != nil {
-		errs = append(errs, fmt.Errorf("the provisioningOSDownloadURL provided: %q is invalid", uri))
-		// If it's not a valid URI lets just return.
-		return errs
+		return nil, fmt.Errorf("the %s provided: %q is invalid", field, uri)
 	}
 	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		errs = append(errs, fmt.Errorf("unsupported scheme %q in provisioningOSDownloadURL %s", parsedURL.Scheme, uri))
-		// Again it's not worth it if it's not http(s)
+		return nil, fmt.Errorf("unsupported scheme %q in %s %s", parsedURL.Scheme, field, uri)
+	}
+	return parsedURL, nil
+}
+
+// validateChecksummedURL checks that uri is an http(s) URL whose checksum,
+// if any, is a valid sha256 or sha512 parameter.
+func validateChecksummedURL(field, uri string, checksumRequired bool) (*url.URL, []error) {
+	var errs []error
+
+	parsedURL, err := validateHTTPURL(field, uri)
+	if err != nil {
+		// It's not worth checking the checksum of an unusable URL.
+		return nil, append(errs, err)
+	}
+
+	for _, param := range checksumParameters {
+		values, ok := parsedURL.Query()[param.name]
+		if !ok {
+			continue
+		}
+		if len(values[0]) != param.length || !hexRegexp.MatchString(values[0]) {
+			errs = append(errs, fmt.Errorf("the %s parameter in the %s %q is invalid", param.name, field, uri))
+		}
+	}
+	if checksumRequired && !hasChecksumParameter(parsedURL) {
+		errs = append(errs, fmt.Errorf("the sha256 or sha512 parameter in the %s %q is missing", field, uri))
+	}
+
+	return parsedURL, errs
+}
+
+// cachedNameRegexp matches the names under which additional images can be
+// served by the image cache.
+var cachedNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func validateIronicProxy(mode IronicProxyMode, supported bool) error {
+	switch mode {
+	case "", IronicProxyAuto, IronicProxyDisabled:
+		return nil
+	case IronicProxyEnabled:
+		if !supported {
+			return fmt.Errorf("ironicProxy cannot be %s when the control plane is external", mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("ironicProxy %q is not one of %s, %s or %s", mode, IronicProxyAuto, IronicProxyEnabled, IronicProxyDisabled)
+	}
+}
+
+func validateBaremetalOperator(config *BaremetalOperator) []error {
+	var errs []error
+
+	if config == nil {
+		return nil
+	}
+	if config.MaxConcurrentReconciles < 0 {
+		errs = append(errs, fmt.Errorf("baremetalOperator.maxConcurrentReconciles must not be negative"))
+	}
+	if config.ProvisioningLimit < 0 {
+		errs = append(errs, fmt.Errorf("baremetalOperator.provisioningLimit must not be negative"))
+	}
+	switch config.LiveISOPersistentBootDevice {
+	case "", PersistentBootDeviceDefault, PersistentBootDeviceAlways, PersistentBootDeviceNever:
+	default:
+		errs = append(errs, fmt.Errorf("baremetalOperator.liveISOPersistentBootDevice %q is not one of %s, %s or %s", config.LiveISOPersistentBootDevice,
+			PersistentBootDeviceDefault, PersistentBootDeviceAlways, PersistentBootDeviceNever))
+	}
+	switch config.DetachedDeleteAction {
+	case "", DetachedDeleteActionDelay, DetachedDeleteActionDelete:
+	default:
+		errs = append(errs, fmt.Errorf("baremetalOperator.detachedDeleteAction %q is not one of %s or %s", config.DetachedDeleteAction,
+			DetachedDeleteActionDelay, DetachedDeleteActionDelete))
+	}
+	switch config.LogLevel {
+	case "", operatorv1.Normal, operatorv1.Debug, operatorv1.Trace, operatorv1.TraceAll:
+	default:
+		errs = append(errs, fmt.Errorf("baremetalOperator.logLevel %q is not one of %s, %s, %s or %s", config.LogLevel,
+			operatorv1.Normal, operatorv1.Debug, operatorv1.Trace, operatorv1.TraceAll))
+	}
+	return errs
+}
+
+func validateIronicTuning(tuning *IronicTuning) []error {
+	var errs []error
+
+	if tuning == nil {
+		return nil
+	}
+	// The ranges match the markers of IronicTuning.
+	for _, setting := range []struct {
+		field    string
+		value    int
+		min, max int
+	}{
+		{"deployCallbackTimeout", tuning.DeployCallbackTimeout, 60, 86400},
+		{"cleanCallbackTimeout", tuning.CleanCallbackTimeout, 60, 86400},
+		{"powerStateSyncInterval", tuning.PowerStateSyncInterval, 10, 86400},
+		{"powerStateChangeTimeout", tuning.PowerStateChangeTimeout, 2, 600},
+		{"maxConcurrentDeploys", tuning.MaxConcurrentDeploys, 1, 1000},
+		{"maxConcurrentCleans", tuning.MaxConcurrentCleans, 1, 1000},
+	} {
+		// Unset settings keep the Ironic default
+		if setting.value == 0 {
+			continue
+		}
+		if setting.value < setting.min || setting.value > setting.max {
+			errs = append(errs, fmt.Errorf("ironicTuning.%s must be between %d and %d, got %d", setting.field, setting.min, setting.max, setting.value))
+		}
+	}
+	return errs
+}
+
+func validateCleaningPolicy(policy *CleaningPolicy) []error {
+	var errs []error
+
+	if policy == nil {
+		return nil
+	}
+
+	switch policy.Mode {
+	case "", CleaningModeMetadata, CleaningModeFull:
+	default:
+		errs = append(errs, fmt.Errorf("cleaningPolicy.mode %q is not one of %s or %s", policy.Mode,
+			CleaningModeMetadata, CleaningModeFull))
+	}
+
+	if len(policy.EraseMethods) > 0 && policy.Mode != CleaningModeFull {
+		errs = append(errs, fmt.Errorf("cleaningPolicy.eraseMethods can only be set when cleaning is %s", CleaningModeFull))
+	}
+	seen := map[EraseMethod]bool{}
+	for _, method := range policy.EraseMethods {
+		switch method {
+		case EraseMethodATASecureErase, EraseMethodNVMeSecureErase, EraseMethodOverwrite:
+		default:
+			errs = append(errs, fmt.Errorf("cleaningPolicy.eraseMethods: %q is not one of %s, %s or %s", method,
+				EraseMethodATASecureErase, EraseMethodNVMeSecureErase, EraseMethodOverwrite))
+			continue
+		}
+		if seen[method] {
+			errs = append(errs, fmt.Errorf("cleaningPolicy.eraseMethods: %q is listed more than once", method))
+		}
+		seen[method] = true
+	}
+	return errs
+}
+
+var (
+	inspectionCollectors = []InspectionCollector{"extra-hardware", "numa-topology", "dmi-decode", "pci-devices", "usb-devices", "logs"}
+	inspectionHooks      = []InspectionHook{"extra-hardware", "pci-devices", "accelerators", "cpu-capabilities", "memory", "boot-mode", "raid-device", "physical-network"}
+
+	// managedKernelParams are the kernel parameters of the
+	// ironic-python-agent ramdisk set by the operator.
+	managedKernelParams = []string{"ip", "rd.net.timeout.carrier", "ipa-inspection-collectors", "ipa-collect-lldp"}
+)
+
+func validateInspection(inspection *Inspection) []error {
+	var errs []error
+
+	if inspection == nil {
+		return nil
+	}
+
+	seenCollectors := map[InspectionCollector]bool{}
+	for _, collector := range inspection.Collectors {
+		if !slices.Contains(inspectionCollectors, collector) {
+			errs = append(errs, fmt.Errorf("inspection.collectors: %q is not a supported collector", collector))
+		} else if seenCollectors[collector] {
+			errs = append(errs, fmt.Errorf("inspection.collectors: %q is listed more than once", collector))
+		}
+		seenCollectors[collector] = true
+	}
+
+	seenHooks := map[InspectionHook]bool{}
+	for _, hook := range inspection.Hooks {
+		if !slices.Contains(inspectionHooks, hook) {
+			errs = append(errs, fmt.Errorf("inspection.hooks: %q is not a supported hook", hook))
+		} else if seenHooks[hook] {
+			errs = append(errs, fmt.Errorf("inspection.hooks: %q is listed more than once", hook))
+		}
+		seenHooks[hook] = true
+	}
+
+	return append(errs, validateKernelParams("inspection.extraKernelParams", inspection.ExtraKernelParams)...)
+}
+
+// validateKernelParams checks additional kernel parameters of the
+// ironic-python-agent ramdisk.
+func validateKernelParams(field, params string) []error {
+	var errs []error
+
+	for _, param := range strings.Fields(params) {
+		name, _, _ := strings.Cut(param, "=")
+		if slices.Contains(managedKernelParams, name) {
+			errs = append(errs, fmt.Errorf("%s: %q is set by the operator", field, name))
+		}
+	}
+	if strings.ContainsAny(params, "\"'\n") {
+		errs = append(errs, fmt.Errorf("%s must not contain quotes or newlines", field))
+	}
+	return errs
+}
+
+// maxDebugSSHKeyLifetime is how far in the future the debug SSH key of the
+// ramdisk can expire.
+const maxDebugSSHKeyLifetime = 7 * 24 * time.Hour
+
+// sshKeyTypes are the types of SSH public keys accepted in the ramdisk.
+var sshKeyTypes = []string{
+	"ssh-rsa",
+	"ssh-ed25519",
+	"ecdsa-sha2-nistp256",
+	"ecdsa-sha2-nistp384",
+	"ecdsa-sha2-nistp521",
+	"sk-ssh-ed25519@openssh.com",
+	"sk-ecdsa-sha2-nistp256@openssh.com",
+}
+
+// parseSSHPublicKey parses an SSH public key in the authorized_keys format,
+// without options, and returns its wire format.
+func parseSSHPublicKey(key string) ([]byte, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 || strings.ContainsAny(key, "\r\n") {
+		return nil, fmt.Errorf("not a single key in the authorized_keys format")
+	}
+	keyType := fields[0]
+	if !slices.Contains(sshKeyTypes, keyType) {
+		return nil, fmt.Errorf("unsupported key type %q", keyType)
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid key data: %w", err)
+	}
+	// The wire format starts with the length-prefixed key type.
+	if len(blob) < 4+len(keyType) || binary.BigEndian.Uint32(blob) != uint32(len(keyType)) || string(blob[4:4+len(keyType)]) != keyType {
+		return nil, fmt.Errorf("the key data does not match the key type %q", keyType)
+	}
+	return blob, nil
+}
+
+// SSHKeyFingerprint returns the SHA256 fingerprint of an SSH public key in
+// the authorized_keys format, as shown by ssh-keygen -l.
+func SSHKeyFingerprint(key string) (string, error) {
+	blob, err := parseSSHPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
+func validateRamdiskSSHKey(key *RamdiskSSHKey) []error {
+	var errs []error
+
+	if key == nil {
+		return nil
+	}
+
+	if key.PublicKey != "" {
+		if _, err := parseSSHPublicKey(key.PublicKey); err != nil {
+			errs = append(errs, fmt.Errorf("ironicAgent.sshKey.publicKey is invalid: %w", err))
+		}
+		if key.SecretName != "" {
+			errs = append(errs, fmt.Errorf("ironicAgent.sshKey: only one of publicKey and secretName can be set"))
+		}
+	}
+	if key.SecretName != "" {
+		if msgs := validation.IsDNS1123Subdomain(key.SecretName); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("ironicAgent.sshKey.secretName: invalid name %q: %s", key.SecretName, strings.Join(msgs, ", ")))
+		}
+	}
+	return errs
+}
+
+func validateIronicAgent(agent *IronicAgent, now time.Time) []error {
+	var errs []error
+
+	if agent == nil {
+		return nil
+	}
+
+	errs = append(errs, validateKernelParams("ironicAgent.extraKernelParams", agent.ExtraKernelParams)...)
+
+	errs = append(errs, validateRamdiskSSHKey(agent.SSHKey)...)
+
+	if key := agent.DebugSSHKey; key != nil {
+		if _, err := parseSSHPublicKey(key.PublicKey); err != nil {
+			errs = append(errs, fmt.Errorf("ironicAgent.debugSSHKey.publicKey is invalid: %w", err))
+		}
+		if key.Expires.IsZero() {
+			errs = append(errs, fmt.Errorf("ironicAgent.debugSSHKey.expires is required"))
+		} else if key.Expires.Time.After(now.Add(maxDebugSSHKeyLifetime)) {
+			errs = append(errs, fmt.Errorf("ironicAgent.debugSSHKey.expires must be at most %s in the future", maxDebugSSHKeyLifetime))
+		}
+	}
+
+	seenManagers := map[string]bool{}
+	for _, manager := range agent.HardwareManagers {
+		if msgs := validation.IsDNS1123Label(manager.Name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("ironicAgent.hardwareManagers: invalid name %q: %s", manager.Name, strings.Join(msgs, ", ")))
+		} else if seenManagers[manager.Name] {
+			errs = append(errs, fmt.Errorf("ironicAgent.hardwareManagers: %q is listed more than once", manager.Name))
+		}
+		seenManagers[manager.Name] = true
+		if !imageReferenceRegexp.MatchString(manager.Image) {
+			errs = append(errs, fmt.Errorf("ironicAgent.hardwareManagers: image %q of %q is not a valid image reference", manager.Image, manager.Name))
+		}
+	}
+	return errs
+}
+
+func validateImageCache(imageCache *ImageCache, downloadURL string) []error {
+	var errs []error
+
+	if imageCache == nil {
+		return nil
+	}
+	if imageCache.MaxSize != nil && imageCache.MaxSize.Sign() <= 0 {
+		errs = append(errs, fmt.Errorf("imageCache.maxSize must be positive, got %s", imageCache.MaxSize))
+	}
+	if imageCache.KeepVersions < 0 {
+		errs = append(errs, fmt.Errorf("imageCache.keepVersions must be at least 1, got %d", imageCache.KeepVersions))
+	}
+	if imageCache.MinFreeSpacePercent < 0 || imageCache.MinFreeSpacePercent > 100 {
+		errs = append(errs, fmt.Errorf("imageCache.minFreeSpacePercent must be between 1 and 100, got %d", imageCache.MinFreeSpacePercent))
+	}
+	for key, value := range imageCache.NodeSelector {
+		if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("imageCache.nodeSelector: invalid label key %q: %s", key, strings.Join(msgs, ", ")))
+		}
+		if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("imageCache.nodeSelector: invalid label value %q: %s", value, strings.Join(msgs, ", ")))
+		}
+	}
+	if len(imageCache.AdditionalImages) > 0 && downloadURL == "" {
+		errs = append(errs, fmt.Errorf("imageCache.additionalImages requires provisioningOSDownloadURL to be set"))
+	}
+	errs = append(errs, validateAdditionalImages(imageCache.AdditionalImages)...)
+
+	return errs
+}
+
+func validateAdditionalImages(images []AdditionalImage) []error {
+	var errs []error
+	seen := map[string]bool{}
+
+	for i, image := range images {
+		field := fmt.Sprintf("imageCache.additionalImages[%d].url", i)
+		parsedURL, err := validateHTTPURL(field, image.URL)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		name := path.Base(parsedURL.Path)
+		_, compression, ok := OSImageFormatFromPath(name)
+		if !ok || (compression != OSImageCompressionNone && compression != OSImageCompressionGzip) {
+			errs = append(errs, fmt.Errorf("the %s provided: %q must end in .qcow2 or .raw, optionally followed by .gz", field, image.URL))
+			continue
+		}
+		if compression == OSImageCompressionGzip {
+			name = strings.TrimSuffix(name, path.Ext(name))
+		}
+		if !cachedNameRegexp.MatchString(name) {
+			errs = append(errs, fmt.Errorf("the %s provided: %q has an invalid file name %q", field, image.URL, name))
+		}
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("the %s provided: %q is cached as %s like another additional image", field, image.URL, name))
+		}
+		seen[name] = true
+
+		if image.Checksum != "" && ((len(image.Checksum) != 64 && len(image.Checksum) != 128) || !hexRegexp.MatchString(image.Checksum)) {
+			errs = append(errs, fmt.Errorf("imageCache.additionalImages[%d].checksum must be a hex encoded sha256 or sha512 checksum", i))
+		}
+	}
+
+	return errs
+}
+
+func validateNTPServers(servers []string) []error {
+	var errs []error
+
+	for _, server := range servers {
+		if net.ParseIP(server) != nil {
+			continue
+		}
+		if msgs := validation.IsDNS1123Subdomain(strings.ToLower(server)); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("additionalNTPServers: %q is neither an IP address nor a valid hostname: %s", server, strings.Join(msgs, ", ")))
+		}
+	}
+
+	return errs
+}
+
+func validateExternalIPs(externalIPs []string, ipFamilies map[corev1.IPFamily]bool) []error {
+	var errs []error
+	seen := map[string]bool{}
+	families := map[corev1.IPFamily]string{}
+
+	for _, ip := range externalIPs {
+		// Empty entries are ignored, like when rendering the metal3 pod
+		if ip == "" {
+			continue
+		}
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			errs = append(errs, fmt.Errorf("externalIPs: %q is not a valid IP address", ip))
+			continue
+		}
+		if seen[parsed.String()] {
+			errs = append(errs, fmt.Errorf("externalIPs: %q is listed more than once", ip))
+			continue
+		}
+		seen[parsed.String()] = true
+
+		family := corev1.IPv6Protocol
+		if parsed.To4() != nil {
+			family = corev1.IPv4Protocol
+		}
+		if len(ipFamilies) > 0 && !ipFamilies[family] {
+			errs = append(errs, fmt.Errorf("externalIPs: %q is an %s address but the cluster service network has no %s range", ip, family, family))
+			continue
+		}
+		if other, ok := families[family]; ok {
+			errs = append(errs, fmt.Errorf("externalIPs: only one %s address is allowed, got %q and %q", family, other, ip))
+			continue
+		}
+		families[family] = ip
+	}
+	return errs
+}
+
+// maxInterfaceNameLength is the longest network interface name Linux accepts
+const maxInterfaceNameLength = 15
+
+func validateProvisioningVLAN(spec *ProvisioningSpec, provisioningNetworkMode ProvisioningNetwork) []error {
+	var errs []error
+
+	if spec.ProvisioningVLANID == 0 {
+		if spec.ProvisioningVLANParentInterface != "" {
+			errs = append(errs, fmt.Errorf("provisioningVLANParentInterface %q is set but provisioningVLANID is not", spec.ProvisioningVLANParentInterface))
+		}
 		return errs
 	}
-	var sha256Checksum string
-	if sha256Checksums, ok := parsedURL.Query()["sha256"]; ok {
-		sha256Checksum = sha256Checksums[0]
+
+	if spec.ProvisioningVLANID < 1 || spec.ProvisioningVLANID > 4094 {
+		errs = append(errs, fmt.Errorf("provisioningVLANID %d is invalid, it must be between 1 and 4094", spec.ProvisioningVLANID))
 	}
-	if sha256Checksum == "" {
-		errs = append(errs, fmt.Errorf("the sha256 parameter in the provisioningOSDownloadURL %q is missing", uri))
+
+	if provisioningNetworkMode == ProvisioningNetworkDisabled {
+		errs = append(errs, fmt.Errorf("provisioningVLANID is not supported when the provisioning network is %s", provisioningNetworkMode))
+		return errs
 	}
-	if len(sha256Checksum) != 64 {
-		errs = append(errs, fmt.Errorf("the sha256 parameter in the provisioningOSDownloadURL %q is invalid", uri))
+
+	// The static IP manager looks the parent up by MAC address when it is
+	// not given by name.
+	if spec.ProvisioningVLANParentInterface == "" && len(spec.ProvisioningMacAddresses) == 0 {
+		errs = append(errs, fmt.Errorf("the parent interface of provisioning VLAN %d cannot be resolved, set either provisioningVLANParentInterface or provisioningMacAddresses", spec.ProvisioningVLANID))
 	}
-	if !strings.HasSuffix(parsedURL.Path, ".qcow2.gz") && !strings.HasSuffix(parsedURL.Path, ".qcow2.xz") {
-		errs = append(errs, fmt.Errorf("the provisioningOSDownloadURL provided: %q is an OS image and must end in .qcow2.gz or .qcow2.xz", uri))
+
+	if name := spec.ProvisioningVLANInterface(); len(name) > maxInterfaceNameLength {
+		errs = append(errs, fmt.Errorf("the provisioning VLAN interface name %q is longer than %d characters, set a shorter provisioningInterface", name, maxInterfaceNameLength))
 	}
 
 	return errs
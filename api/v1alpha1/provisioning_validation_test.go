@@ -18,12 +18,28 @@ package v1alpha1
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/ptr"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	testBaremetalProvisioningCR = "test-provisioning-configuration"
+	testDebugSSHKey             = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGjYNtTbZsTM8qKcPaXcRDnCAB4amM/JG1yzvZIAed9g debug"
 )
 
-const testBaremetalProvisioningCR = "test-provisioning-configuration"
+// validateOnCreate runs the checks the webhook applies to a new CR.
+func validateOnCreate(prov *Provisioning, enabledFeatures EnabledFeatures) error {
+	_, err := prov.ValidateChangedFields(nil, enabledFeatures)
+	return utilerrors.NewAggregate([]error{prov.ValidateBaremetalProvisioningConfig(enabledFeatures), err})
+}
 
 func TestValidateManagedProvisioningConfig(t *testing.T) {
 	baremetalCR := &Provisioning{
@@ -57,6 +73,195 @@ func TestValidateManagedProvisioningConfig(t *testing.T) {
 			expectedError: false,
 			expectedMode:  ProvisioningNetworkManaged,
 		},
+		{
+			name:          "ValidManagedDualStackExternalIPs",
+			spec:          managedProvisioning().ExternalIPs([]string{"192.168.1.100", "fd00::100"}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedInvalidExternalIP",
+			spec:          managedProvisioning().ExternalIPs([]string{"192.168.1.300"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "\"192.168.1.300\" is not a valid IP address",
+		},
+		{
+			name:          "ManagedDuplicateExternalIP",
+			spec:          managedProvisioning().ExternalIPs([]string{"fd00::100", "fd00:0::100"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "\"fd00:0::100\" is listed more than once",
+		},
+		{
+			name:          "ManagedTwoIPv4ExternalIPs",
+			spec:          managedProvisioning().ExternalIPs([]string{"192.168.1.100", "192.168.1.101"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "only one IPv4 address is allowed",
+		},
+		{
+			name:          "ValidManagedNTPServers",
+			spec:          managedProvisioning().AdditionalNTPServers([]string{"ntp.example.com", "192.168.1.1", "fd00::1"}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedInvalidNTPServer",
+			spec:          managedProvisioning().AdditionalNTPServers([]string{"ntp_server"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "additionalNTPServers: \"ntp_server\" is neither an IP address nor a valid hostname",
+		},
+		{
+			name: "ValidManagedPreProvisioningOSDownloadURLs",
+			spec: managedProvisioning().PreProvisioningOSDownloadURLs(PreProvisioningOSDownloadURLs{
+				KernelURL: "https://example.com/live-kernel?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+				IsoURL:    "http://example.com/live.iso?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+			}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedPreProvisioningOSDownloadURLWithoutChecksum",
+			spec:          managedProvisioning().PreProvisioningOSDownloadURLs(PreProvisioningOSDownloadURLs{RootfsURL: "http://example.com/live-rootfs.img"}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedPreProvisioningOSDownloadURLInvalidChecksum",
+			spec:          managedProvisioning().PreProvisioningOSDownloadURLs(PreProvisioningOSDownloadURLs{RootfsURL: "http://example.com/live-rootfs.img?sha256=1234"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "the sha256 parameter in the preProvisioningOSDownloadURLs.rootfsURL \"http://example.com/live-rootfs.img?sha256=1234\" is invalid",
+		},
+		{
+			name:          "ManagedPreProvisioningOSDownloadURLBadScheme",
+			spec:          managedProvisioning().PreProvisioningOSDownloadURLs(PreProvisioningOSDownloadURLs{InitramfsURL: "ftp://example.com/initramfs.img"}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "unsupported scheme \"ftp\" in preProvisioningOSDownloadURLs.initramfsURL",
+		},
+		{
+			name:          "ValidManagedIronicAgentImage",
+			spec:          managedProvisioning().IronicAgentImage("quay.io:443/openshift/ironic-agent:4.20@sha256:e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedInvalidIronicAgentImage",
+			spec:          managedProvisioning().IronicAgentImage("quay.io/Openshift/ironic agent").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "unsupportedConfigOverrides.ironicAgentImage \"quay.io/Openshift/ironic agent\" is not a valid image reference",
+		},
+		{
+			name:          "ValidManagedImageCache",
+			spec:          managedProvisioning().ImageCache(&ImageCache{MaxSize: ptr.To(resource.MustParse("40Gi")), KeepVersions: 1, MinFreeSpacePercent: 20}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedImageCacheZeroMaxSize",
+			spec:          managedProvisioning().ImageCache(&ImageCache{MaxSize: ptr.To(resource.MustParse("0"))}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "imageCache.maxSize must be positive, got 0",
+		},
+		{
+			name:          "ManagedImageCacheInvalidNodeSelector",
+			spec:          managedProvisioning().ImageCache(&ImageCache{NodeSelector: map[string]string{"node-role.kubernetes.io/infra": "not valid"}}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "imageCache.nodeSelector: invalid label value \"not valid\"",
+		},
+		{
+			name: "ValidManagedImageCacheAdditionalImages",
+			spec: managedProvisioning().ImageCache(&ImageCache{AdditionalImages: []AdditionalImage{
+				{URL: "http://example.com/rhcos-414.qcow2.gz", Checksum: "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234"},
+				{URL: "https://example.com/custom/custom.raw"},
+			}}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedImageCacheAdditionalImageXZ",
+			spec:          managedProvisioning().ImageCache(&ImageCache{AdditionalImages: []AdditionalImage{{URL: "http://example.com/rhcos.qcow2.xz"}}}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "must end in .qcow2 or .raw, optionally followed by .gz",
+		},
+		{
+			name: "ManagedImageCacheAdditionalImageDuplicate",
+			spec: managedProvisioning().ImageCache(&ImageCache{AdditionalImages: []AdditionalImage{
+				{URL: "http://example.com/a/rhcos.qcow2.gz"},
+				{URL: "http://example.com/b/rhcos.qcow2"},
+			}}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "is cached as rhcos.qcow2 like another additional image",
+		},
+		{
+			name:          "ManagedImageCacheAdditionalImageInvalidChecksum",
+			spec:          managedProvisioning().ImageCache(&ImageCache{AdditionalImages: []AdditionalImage{{URL: "http://example.com/rhcos.qcow2", Checksum: "abc"}}}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "imageCache.additionalImages[0].checksum must be a hex encoded sha256 or sha512 checksum",
+		},
+		{
+			name:          "ManagedImageCacheAdditionalImagesWithoutDownloadURL",
+			spec:          managedProvisioning().ProvisioningOSDownloadURL("").ImageCache(&ImageCache{AdditionalImages: []AdditionalImage{{URL: "http://example.com/rhcos.qcow2"}}}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "imageCache.additionalImages requires provisioningOSDownloadURL to be set",
+		},
+		{
+			name:          "ManagedImageCacheInvalidMinFreeSpace",
+			spec:          managedProvisioning().ImageCache(&ImageCache{MinFreeSpacePercent: 120}).build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "imageCache.minFreeSpacePercent must be between 1 and 100, got 120",
+		},
+		{
+			name:          "ValidManagedVLANOnBond",
+			spec:          managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "bond0").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ValidManagedVLANFromMACs",
+			spec:          managedProvisioning().ProvisioningVLAN(100, "").ProvisioningMacAddresses([]string{"34:b3:2d:81:f8:fb"}).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "ManagedVLANUnresolvableParent",
+			spec:          managedProvisioning().ProvisioningVLAN(100, "").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "the parent interface of provisioning VLAN 100 cannot be resolved",
+		},
+		{
+			name:          "ManagedInvalidVLANID",
+			spec:          managedProvisioning().ProvisioningVLAN(4095, "bond0").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "provisioningVLANID 4095 is invalid",
+		},
+		{
+			name:          "ManagedVLANParentWithoutID",
+			spec:          managedProvisioning().ProvisioningVLAN(0, "bond0").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "provisioningVLANID is not",
+		},
+		{
+			name:          "ManagedVLANInterfaceNameTooLong",
+			spec:          managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "bond-provisioning").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "is longer than 15 characters",
+		},
 		{
 			// Provisioning IP is in the DHCP Range
 			name:          "InvalidManagedProvisioningIPInDHCPRange",
@@ -66,7 +271,21 @@ func TestValidateManagedProvisioningConfig(t *testing.T) {
 			expectedMsg:   "value must be outside of the provisioningDHCPRange",
 		},
 		{
-			// OSDownloadURL Image must end in qcow2.gz or qcow2.xz
+			name:          "ValidManagedUncompressedQCOW2DownloadURL",
+			spec:          managedProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			// The image downloader cannot decompress zstd
+			name:          "InvalidManagedZstdRawDownloadURL",
+			spec:          managedProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-metal.x86_64.raw.zst?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "must end in .qcow2 or .raw, optionally followed by .gz or .xz",
+		},
+		{
+			// OSDownloadURL Image must be a qcow2 or raw image
 			name:          "InvalidManagedDownloadURLSuffix",
 			spec:          managedProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.zip?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
 			expectedError: true,
@@ -113,6 +332,33 @@ func TestValidateManagedProvisioningConfig(t *testing.T) {
 			expectedMode:  ProvisioningNetworkManaged,
 			expectedMsg:   "the sha256 parameter in the provisioningOSDownloadURL",
 		},
+		{
+			name:          "ValidManagedSHA512ChecksumURL",
+			spec:          managedProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.gz?sha512=" + strings.Repeat("e98f83a2b9d40437", 8)).build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedSHA512ChecksumURL",
+			spec:          managedProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.gz?sha512=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "the sha512 parameter in the provisioningOSDownloadURL",
+		},
+		{
+			name: "ValidManagedChecksumFile",
+			spec: managedProvisioning().ProvisioningOSDownloadURL("http://172.22.0.1/images/rhcos-44.81.202001171431.0-openstack.x86_64.qcow2.gz").
+				ProvisioningOSChecksumURL("http://172.22.0.1/images/SHA256SUMS").build(),
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name:          "InvalidManagedChecksumFileAndParameter",
+			spec:          managedProvisioning().ProvisioningOSChecksumURL("http://172.22.0.1/images/SHA256SUMS").build(),
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "must not have a checksum parameter when provisioningOSChecksumURL is set",
+		},
 		{
 			// DHCPRange is not part of the network CIDR
 			name:          "InvalidManagedDHCPRangeOutsideCIDR",
@@ -227,7 +473,7 @@ func TestValidateManagedProvisioningConfig(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Testing tc : %s", tc.name)
 			baremetalCR.Spec = *tc.spec
-			err := baremetalCR.ValidateBaremetalProvisioningConfig(EnabledFeatures{
+			err := validateOnCreate(baremetalCR, EnabledFeatures{
 				ProvisioningNetwork: map[ProvisioningNetwork]bool{
 					ProvisioningNetworkManaged: true,
 				},
@@ -323,7 +569,7 @@ func TestValidateUnmanagedProvisioningConfig(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Testing tc : %s", tc.name)
 			baremetalCR.Spec = *tc.spec
-			err := baremetalCR.ValidateBaremetalProvisioningConfig(EnabledFeatures{
+			err := validateOnCreate(baremetalCR, EnabledFeatures{
 				ProvisioningNetwork: map[ProvisioningNetwork]bool{
 					ProvisioningNetworkUnmanaged: true,
 				},
@@ -406,7 +652,7 @@ func TestValidateDisabledProvisioningConfig(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Testing tc : %s", tc.name)
 			baremetalCR.Spec = *tc.spec
-			err := baremetalCR.ValidateBaremetalProvisioningConfig(EnabledFeatures{
+			err := validateOnCreate(baremetalCR, EnabledFeatures{
 				ProvisioningNetwork: map[ProvisioningNetwork]bool{
 					ProvisioningNetworkDisabled: true,
 				},
@@ -487,12 +733,387 @@ func TestValidateSupportedFeatures(t *testing.T) {
 			expectedError:   true,
 			expectedMode:    ProvisioningNetworkDisabled,
 		},
+		{
+			name: "external IP matching the service network",
+			spec: managedProvisioning().ExternalIPs([]string{"192.168.1.100"}).build(),
+			enabledfeatures: EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true},
+				IPFamilies:          map[corev1.IPFamily]bool{corev1.IPv4Protocol: true},
+			},
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name: "external IP not matching the service network",
+			spec: managedProvisioning().ExternalIPs([]string{"192.168.1.100", "fd00::100"}).build(),
+			enabledfeatures: EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true},
+				IPFamilies:          map[corev1.IPFamily]bool{corev1.IPv4Protocol: true},
+			},
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "\"fd00::100\" is an IPv6 address but the cluster service network has no IPv6 range",
+		},
+		{
+			name: "ironic-proxy enabled",
+			spec: managedProvisioning().IronicProxy(IronicProxyEnabled).build(),
+			enabledfeatures: EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true},
+				IronicProxy:         true,
+			},
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkManaged,
+		},
+		{
+			name: "ironic-proxy enabled with an external control plane",
+			spec: disabledProvisioning().IronicProxy(IronicProxyEnabled).build(),
+			enabledfeatures: EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkDisabled: true},
+			},
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkDisabled,
+			expectedMsg:   "ironicProxy cannot be Enabled when the control plane is external",
+		},
+		{
+			name: "ironic-proxy disabled with an external control plane",
+			spec: disabledProvisioning().IronicProxy(IronicProxyDisabled).build(),
+			enabledfeatures: EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkDisabled: true},
+			},
+			expectedError: false,
+			expectedMode:  ProvisioningNetworkDisabled,
+		},
+		{
+			name: "invalid ironic-proxy mode",
+			spec: managedProvisioning().IronicProxy("Always").build(),
+			enabledfeatures: EnabledFeatures{
+				ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true},
+				IronicProxy:         true,
+			},
+			expectedError: true,
+			expectedMode:  ProvisioningNetworkManaged,
+			expectedMsg:   "ironicProxy \"Always\" is not one of Auto, Enabled or Disabled",
+		},
+		{
+			name: "baremetal-operator configuration",
+			spec: managedProvisioning().BaremetalOperator(&BaremetalOperator{
+				MaxConcurrentReconciles:     8,
+				ProvisioningLimit:           40,
+				LiveISOPersistentBootDevice: PersistentBootDeviceAlways,
+				DetachedDeleteAction:        DetachedDeleteActionDelay,
+				LogLevel:                    operatorv1.Debug,
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name:            "baremetal-operator with negative provisioning limit",
+			spec:            managedProvisioning().BaremetalOperator(&BaremetalOperator{ProvisioningLimit: -1}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "baremetalOperator.provisioningLimit must not be negative",
+		},
+		{
+			name:            "baremetal-operator with invalid persistent boot device",
+			spec:            managedProvisioning().BaremetalOperator(&BaremetalOperator{LiveISOPersistentBootDevice: "Sometimes"}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "baremetalOperator.liveISOPersistentBootDevice \"Sometimes\" is not one of Default, Always or Never",
+		},
+		{
+			name:            "baremetal-operator with invalid log level",
+			spec:            managedProvisioning().BaremetalOperator(&BaremetalOperator{LogLevel: "Verbose"}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "baremetalOperator.logLevel \"Verbose\" is not one of Normal, Debug, Trace or TraceAll",
+		},
+		{
+			name: "ironic tuning",
+			spec: managedProvisioning().IronicTuning(&IronicTuning{
+				DeployCallbackTimeout:  3600,
+				PowerStateSyncInterval: 300,
+				MaxConcurrentDeploys:   100,
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name:            "ironic tuning out of range",
+			spec:            managedProvisioning().IronicTuning(&IronicTuning{PowerStateSyncInterval: 5}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicTuning.powerStateSyncInterval must be between 10 and 86400, got 5",
+		},
+		{
+			name:            "ironic tuning with negative value",
+			spec:            managedProvisioning().IronicTuning(&IronicTuning{MaxConcurrentCleans: -1}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicTuning.maxConcurrentCleans must be between 1 and 1000, got -1",
+		},
+		{
+			name: "full cleaning",
+			spec: managedProvisioning().CleaningPolicy(&CleaningPolicy{
+				Mode:         CleaningModeFull,
+				EraseMethods: []EraseMethod{EraseMethodNVMeSecureErase, EraseMethodOverwrite},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name: "erase methods without full cleaning",
+			spec: managedProvisioning().CleaningPolicy(&CleaningPolicy{
+				EraseMethods: []EraseMethod{EraseMethodATASecureErase},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "cleaningPolicy.eraseMethods can only be set when cleaning is Full",
+		},
+		{
+			name: "duplicate erase method",
+			spec: managedProvisioning().CleaningPolicy(&CleaningPolicy{
+				Mode:         CleaningModeFull,
+				EraseMethods: []EraseMethod{EraseMethodOverwrite, EraseMethodOverwrite},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "cleaningPolicy.eraseMethods: \"Overwrite\" is listed more than once",
+		},
+		{
+			name:            "cleaning disabled",
+			spec:            managedProvisioning().CleaningPolicy(&CleaningPolicy{Mode: "Disabled"}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "cleaningPolicy.mode \"Disabled\" is not one of Metadata or Full",
+		},
+		{
+			name: "inspection settings",
+			spec: managedProvisioning().Inspection(&Inspection{
+				Collectors:        []InspectionCollector{"extra-hardware", "numa-topology"},
+				CollectLLDP:       true,
+				Hooks:             []InspectionHook{"extra-hardware"},
+				ExtraKernelParams: "ipa-inspection-dhcp-all-interfaces=1 console=ttyS0",
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name: "unsupported inspection collector",
+			spec: managedProvisioning().Inspection(&Inspection{
+				Collectors: []InspectionCollector{"benchmarks"},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "inspection.collectors: \"benchmarks\" is not a supported collector",
+		},
+		{
+			name: "duplicate inspection hook",
+			spec: managedProvisioning().Inspection(&Inspection{
+				Hooks: []InspectionHook{"memory", "memory"},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "inspection.hooks: \"memory\" is listed more than once",
+		},
+		{
+			name: "kernel parameter set by the operator",
+			spec: managedProvisioning().Inspection(&Inspection{
+				ExtraKernelParams: "console=ttyS0 ip=dhcp6",
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "inspection.extraKernelParams: \"ip\" is set by the operator",
+		},
+		{
+			name: "quoted kernel parameter",
+			spec: managedProvisioning().Inspection(&Inspection{
+				ExtraKernelParams: "console=\"ttyS0\"",
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "inspection.extraKernelParams must not contain quotes or newlines",
+		},
+		{
+			name: "ramdisk customizations",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				ExtraKernelParams: "console=ttyS0 ipa-debug=1",
+				DebugSSHKey: &DebugSSHKey{
+					PublicKey: testDebugSSHKey,
+					Expires:   metav1.NewTime(time.Now().Add(24 * time.Hour)),
+				},
+				HardwareManagers: []HardwareManager{
+					{Name: "vendor", Image: "quay.io/example/vendor-hardware-manager:v1"},
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name: "expired debug SSH key",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				DebugSSHKey: &DebugSSHKey{
+					PublicKey: testDebugSSHKey,
+					Expires:   metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name: "ramdisk kernel parameter set by the operator",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				ExtraKernelParams: "ipa-collect-lldp=0",
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.extraKernelParams: \"ipa-collect-lldp\" is set by the operator",
+		},
+		{
+			name: "invalid debug SSH key",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				DebugSSHKey: &DebugSSHKey{
+					PublicKey: "ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIGjYNtTbZsTM8qKcPaXcRDnCAB4amM/JG1yzvZIAed9g",
+					Expires:   metav1.NewTime(time.Now().Add(time.Hour)),
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.debugSSHKey.publicKey is invalid: the key data does not match the key type \"ssh-rsa\"",
+		},
+		{
+			name: "debug SSH key without expiration",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				DebugSSHKey: &DebugSSHKey{
+					PublicKey: testDebugSSHKey,
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.debugSSHKey.expires is required",
+		},
+		{
+			name: "debug SSH key expiring too late",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				DebugSSHKey: &DebugSSHKey{
+					PublicKey: testDebugSSHKey,
+					Expires:   metav1.NewTime(time.Now().Add(30 * 24 * time.Hour)),
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.debugSSHKey.expires must be at most 168h0m0s in the future",
+		},
+		{
+			name: "ramdisk SSH key",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				SSHKey: &RamdiskSSHKey{PublicKey: testDebugSSHKey},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name: "ramdisk SSH key Secret",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				SSHKey: &RamdiskSSHKey{SecretName: "ramdisk-ssh-key"},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   false,
+			expectedMode:    ProvisioningNetworkManaged,
+		},
+		{
+			name: "invalid ramdisk SSH key",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				SSHKey: &RamdiskSSHKey{PublicKey: "ssh-dss AAAAB3NzaC1kc3M= old"},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.sshKey.publicKey is invalid: unsupported key type \"ssh-dss\"",
+		},
+		{
+			name: "several ramdisk SSH keys",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				SSHKey: &RamdiskSSHKey{PublicKey: testDebugSSHKey + "\n" + testDebugSSHKey},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.sshKey.publicKey is invalid: not a single key in the authorized_keys format",
+		},
+		{
+			name: "ramdisk SSH key in the spec and a Secret",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				SSHKey: &RamdiskSSHKey{PublicKey: testDebugSSHKey, SecretName: "ramdisk-ssh-key"},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.sshKey: only one of publicKey and secretName can be set",
+		},
+		{
+			name: "invalid ramdisk SSH key Secret name",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				SSHKey: &RamdiskSSHKey{SecretName: "Ramdisk_Keys"},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.sshKey.secretName: invalid name \"Ramdisk_Keys\"",
+		},
+		{
+			name: "duplicate hardware manager",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				HardwareManagers: []HardwareManager{
+					{Name: "vendor", Image: "quay.io/example/vendor-hardware-manager:v1"},
+					{Name: "vendor", Image: "quay.io/example/vendor-hardware-manager:v2"},
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.hardwareManagers: \"vendor\" is listed more than once",
+		},
+		{
+			name: "invalid hardware manager image",
+			spec: managedProvisioning().IronicAgent(&IronicAgent{
+				HardwareManagers: []HardwareManager{
+					{Name: "vendor", Image: "not an image"},
+				},
+			}).build(),
+			enabledfeatures: EnabledFeatures{ProvisioningNetwork: map[ProvisioningNetwork]bool{ProvisioningNetworkManaged: true}},
+			expectedError:   true,
+			expectedMode:    ProvisioningNetworkManaged,
+			expectedMsg:     "ironicAgent.hardwareManagers: image \"not an image\" of \"vendor\" is not a valid image reference",
+		},
 	}
 	for _, tc := range tCases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Logf("Testing tc : %s", tc.name)
 			baremetalCR.Spec = *tc.spec
-			err := baremetalCR.ValidateBaremetalProvisioningConfig(tc.enabledfeatures)
+			err := validateOnCreate(baremetalCR, tc.enabledfeatures)
 			if !tc.expectedError && err != nil {
 				t.Errorf("unexpected errors: %v", err)
 				return
@@ -592,3 +1213,151 @@ func (pb *provisioningBuilder) ProvisioningNetworkGateway(value string) *provisi
 	pb.ProvisioningSpec.ProvisioningNetworkGateway = value
 	return pb
 }
+
+func (pb *provisioningBuilder) ProvisioningVLAN(id int32, parent string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningVLANID = id
+	pb.ProvisioningSpec.ProvisioningVLANParentInterface = parent
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningMacAddresses(value []string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningMacAddresses = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ExternalIPs(value []string) *provisioningBuilder {
+	pb.ProvisioningSpec.ExternalIPs = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicProxy(value IronicProxyMode) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicProxy = value
+	return pb
+}
+
+func (pb *provisioningBuilder) BaremetalOperator(value *BaremetalOperator) *provisioningBuilder {
+	pb.ProvisioningSpec.BaremetalOperator = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicTuning(value *IronicTuning) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicTuning = value
+	return pb
+}
+
+func (pb *provisioningBuilder) CleaningPolicy(value *CleaningPolicy) *provisioningBuilder {
+	pb.ProvisioningSpec.CleaningPolicy = value
+	return pb
+}
+
+func (pb *provisioningBuilder) Inspection(value *Inspection) *provisioningBuilder {
+	pb.ProvisioningSpec.Inspection = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicAgent(value *IronicAgent) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicAgent = value
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalNTPServers(value []string) *provisioningBuilder {
+	pb.ProvisioningSpec.AdditionalNTPServers = value
+	return pb
+}
+
+func (pb *provisioningBuilder) PreProvisioningOSDownloadURLs(value PreProvisioningOSDownloadURLs) *provisioningBuilder {
+	pb.ProvisioningSpec.PreProvisioningOSDownloadURLs = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicAgentImage(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.UnsupportedConfigOverrides = &UnsupportedConfigOverrides{IronicAgentImage: value}
+	return pb
+}
+
+func (pb *provisioningBuilder) DisableVirtualMediaTLS(value bool) *provisioningBuilder {
+	pb.ProvisioningSpec.DisableVirtualMediaTLS = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ImageCache(value *ImageCache) *provisioningBuilder {
+	pb.ProvisioningSpec.ImageCache = value
+	return pb
+}
+
+func (pb *provisioningBuilder) PrometheusExporter(value *PrometheusExporter) *provisioningBuilder {
+	pb.ProvisioningSpec.PrometheusExporter = value
+	return pb
+}
+
+func (pb *provisioningBuilder) ProvisioningOSChecksumURL(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningOSChecksumURL = value
+	return pb
+}
+
+func TestValidateChangedFields(t *testing.T) {
+	features := EnabledFeatures{IPFamilies: map[corev1.IPFamily]bool{corev1.IPv4Protocol: true}}
+	old := &Provisioning{Spec: *managedProvisioning().AdditionalNTPServers([]string{"ntp_server"}).build()}
+
+	// Unchanged since before the upgrade, the field is only warned about,
+	// and it still is when checked against the CR itself.
+	prov := old.DeepCopy()
+	prov.Spec.ProvisioningDHCPRange = "172.30.20.20,172.30.20.90"
+	warnings, err := prov.ValidateChangedFields(old, features)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+	warnings, err = prov.ValidateChangedFields(prov, features)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	// A changed field is rejected.
+	prov.Spec.ExternalIPs = []string{"fd00::100"}
+	warnings, err = prov.ValidateChangedFields(old, features)
+	assert.ErrorContains(t, err, "\"fd00::100\" is an IPv6 address but the cluster service network has no IPv6 range")
+	assert.Len(t, warnings, 1)
+
+	// Every field is checked on creation.
+	warnings, err = prov.ValidateChangedFields(nil, features)
+	assert.ErrorContains(t, err, "additionalNTPServers: \"ntp_server\"")
+	assert.ErrorContains(t, err, "\"fd00::100\"")
+	assert.Empty(t, warnings)
+}
+
+func TestSSHKeyFingerprint(t *testing.T) {
+	fingerprint, err := SSHKeyFingerprint(testDebugSSHKey)
+	assert.NoError(t, err)
+	// as shown by ssh-keygen -l
+	assert.Equal(t, "SHA256:PyzccvXUlxXLwrmbjR7RELJ90S/hEjP5Plk7lTxIK1k", fingerprint)
+
+	_, err = SSHKeyFingerprint("ssh-ed25519 not-base64")
+	assert.ErrorContains(t, err, "invalid key data")
+	_, err = SSHKeyFingerprint(testDebugSSHKey + "\n" + testDebugSSHKey)
+	assert.ErrorContains(t, err, "not a single key")
+}
+
+func TestOSImageFormatFromPath(t *testing.T) {
+	testCases := []struct {
+		name                string
+		expectedOK          bool
+		expectedFormat      OSImageFormat
+		expectedCompression OSImageCompression
+	}{
+		{name: "rhcos.qcow2.gz", expectedOK: true, expectedFormat: OSImageFormatQCOW2, expectedCompression: OSImageCompressionGzip},
+		{name: "rhcos.qcow2.xz", expectedOK: true, expectedFormat: OSImageFormatQCOW2, expectedCompression: OSImageCompressionXZ},
+		{name: "rhcos.qcow2", expectedOK: true, expectedFormat: OSImageFormatQCOW2, expectedCompression: OSImageCompressionNone},
+		{name: "rhcos.raw", expectedOK: true, expectedFormat: OSImageFormatRaw, expectedCompression: OSImageCompressionNone},
+		{name: "rhcos.raw.gz", expectedOK: true, expectedFormat: OSImageFormatRaw, expectedCompression: OSImageCompressionGzip},
+		{name: "rhcos.raw.zst"},
+		{name: "rhcos.qcow2.zip"},
+		{name: "rhcos.iso"},
+		{name: "rhcos.gz"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format, compression, ok := OSImageFormatFromPath(tc.name)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedFormat, format)
+			assert.Equal(t, tc.expectedCompression, compression)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -32,11 +33,28 @@ func (r *Provisioning) SetupWebhookWithManager(mgr ctrl.Manager, features Enable
 	enabledFeatures = features
 	return ctrl.NewWebhookManagedBy(mgr, r).
 		WithValidator(r).
+		WithDefaulter(r).
 		Complete()
 }
 
 // https://golangbyexample.com/go-check-if-type-implements-interface/
 var _ admission.Validator[*Provisioning] = &Provisioning{}
+var _ admission.Defaulter[*Provisioning] = &Provisioning{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the type
+func (r *Provisioning) Default(ctx context.Context, obj *Provisioning) error {
+	provisioninglog.Info("default", "name", obj.Name)
+
+	obj.SetDefaults()
+	return nil
+}
+
+// AllowDisruptiveChangesAnnotation lets an update of the Provisioning CR
+// through even though it changes settings that affect hosts that are being
+// or have been provisioned, when set to "true" by that same update. Left on
+// the CR, it does not allow later disruptive changes: it has to be removed
+// and set again.
+const AllowDisruptiveChangesAnnotation = "baremetal.openshift.io/allow-disruptive-changes"
 
 // ValidateCreate implements admission.Validator so a webhook will be registered for the type
 func (r *Provisioning) ValidateCreate(ctx context.Context, obj *Provisioning) (admission.Warnings, error) {
@@ -46,13 +64,85 @@ func (r *Provisioning) ValidateCreate(ctx context.Context, obj *Provisioning) (a
 		return nil, fmt.Errorf("Provisioning object is a singleton and must be named \"%s\"", ProvisioningSingletonName)
 	}
 
-	return nil, obj.ValidateBaremetalProvisioningConfig(enabledFeatures)
+	_, err := obj.ValidateChangedFields(nil, enabledFeatures)
+	return obj.warnings(), errors.NewAggregate([]error{obj.ValidateBaremetalProvisioningConfig(enabledFeatures), err})
 }
 
 // ValidateUpdate implements admission.Validator so a webhook will be registered for the type
 func (r *Provisioning) ValidateUpdate(ctx context.Context, oldObj, newObj *Provisioning) (admission.Warnings, error) {
 	provisioninglog.Info("validate update", "name", newObj.Name)
-	return nil, newObj.ValidateBaremetalProvisioningConfig(enabledFeatures)
+
+	warnings := newObj.warnings()
+	var errs []error
+
+	transitionErrs := validateTransition(oldObj, newObj)
+	if len(transitionErrs) > 0 && allowsDisruptiveChanges(oldObj, newObj) {
+		for _, err := range transitionErrs {
+			warnings = append(warnings, fmt.Sprintf("allowed by the %s annotation: %v", AllowDisruptiveChangesAnnotation, err))
+		}
+	} else {
+		errs = append(errs, transitionErrs...)
+	}
+
+	if err := newObj.ValidateBaremetalProvisioningConfig(enabledFeatures); err != nil {
+		errs = append(errs, err)
+	}
+
+	fieldWarnings, err := newObj.ValidateChangedFields(oldObj, enabledFeatures)
+	warnings = append(warnings, fieldWarnings...)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return warnings, errors.NewAggregate(errs)
+}
+
+// allowsDisruptiveChanges returns whether the update sets the
+// AllowDisruptiveChangesAnnotation, so that it only allows the disruptive
+// changes made along with it.
+func allowsDisruptiveChanges(oldObj, newObj *Provisioning) bool {
+	return newObj.Annotations[AllowDisruptiveChangesAnnotation] == "true" &&
+		oldObj.Annotations[AllowDisruptiveChangesAnnotation] != "true"
+}
+
+// warnings returns the admission warnings for settings that are accepted but
+// discouraged.
+func (r *Provisioning) warnings() admission.Warnings {
+	var warnings admission.Warnings
+
+	if r.Spec.ProvisioningDHCPExternal {
+		warnings = append(warnings, "provisioningDHCPExternal is deprecated, use provisioningNetwork instead")
+	}
+	if r.Spec.UnsupportedConfigOverrides != nil {
+		warnings = append(warnings, "unsupportedConfigOverrides is set, this configuration is not supported and may break the deployment")
+	}
+	warnings = append(warnings, r.MissingChecksumWarnings()...)
+
+	return warnings
+}
+
+// validateTransition checks that an update does not change settings that
+// hosts being provisioned or already provisioned depend on. Apart from
+// ProvisioningDHCPRange, the provisioning network settings are not expected
+// to change after installation.
+func validateTransition(oldObj, newObj *Provisioning) []error {
+	var errs []error
+
+	oldMode := oldObj.getProvisioningNetworkMode()
+	newMode := newObj.getProvisioningNetworkMode()
+	if oldMode != newMode && newMode == ProvisioningNetworkDisabled {
+		errs = append(errs, fmt.Errorf("provisioningNetwork cannot be changed from %s to %s, hosts relying on the provisioning network would no longer be reachable", oldMode, newMode))
+	}
+
+	if oldObj.Spec.ProvisioningNetworkCIDR != "" && oldObj.Spec.ProvisioningNetworkCIDR != newObj.Spec.ProvisioningNetworkCIDR {
+		errs = append(errs, fmt.Errorf("provisioningNetworkCIDR cannot be changed from %q to %q", oldObj.Spec.ProvisioningNetworkCIDR, newObj.Spec.ProvisioningNetworkCIDR))
+	}
+
+	if oldObj.Spec.DisableVirtualMediaTLS != newObj.Spec.DisableVirtualMediaTLS {
+		errs = append(errs, fmt.Errorf("disableVirtualMediaTLS cannot be changed, hosts may be attached to virtual media using the current setting"))
+	}
+
+	return errs
 }
 
 // ValidateDelete implements admission.Validator so a webhook will be registered for the type
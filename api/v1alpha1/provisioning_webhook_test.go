@@ -17,6 +17,7 @@ package v1alpha1
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -66,3 +67,242 @@ func TestProvisioningValidateCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestProvisioningValidateUpdate(t *testing.T) {
+	allowDisruptive := map[string]string{AllowDisruptiveChangesAnnotation: "true"}
+	tests := []struct {
+		name           string
+		oldSpec        *ProvisioningSpec
+		newSpec        *ProvisioningSpec
+		oldAnnotations map[string]string
+		annotations    map[string]string
+		wantErr        string
+		wantWarnings   int
+	}{
+		{
+			name:    "DHCP range change",
+			oldSpec: managedProvisioning().build(),
+			newSpec: managedProvisioning().ProvisioningDHCPRange("172.30.20.20, 172.30.20.90").build(),
+		},
+		{
+			name:    "managed to disabled",
+			oldSpec: managedProvisioning().build(),
+			newSpec: disabledProvisioning().build(),
+			wantErr: "provisioningNetwork cannot be changed from Managed to Disabled",
+		},
+		{
+			name:         "managed to disabled allowed by annotation",
+			oldSpec:      managedProvisioning().build(),
+			newSpec:      disabledProvisioning().build(),
+			annotations:  allowDisruptive,
+			wantWarnings: 1,
+		},
+		{
+			name:           "annotation left from an earlier update",
+			oldSpec:        managedProvisioning().build(),
+			newSpec:        disabledProvisioning().build(),
+			oldAnnotations: allowDisruptive,
+			annotations:    allowDisruptive,
+			wantErr:        "provisioningNetwork cannot be changed from Managed to Disabled",
+		},
+		{
+			name:    "disabled to managed",
+			oldSpec: disabledProvisioning().build(),
+			newSpec: managedProvisioning().build(),
+		},
+		{
+			name:    "network CIDR change",
+			oldSpec: managedProvisioning().build(),
+			newSpec: managedProvisioning().ProvisioningNetworkCIDR("172.30.0.0/16").build(),
+			wantErr: "provisioningNetworkCIDR cannot be changed from \"172.30.20.0/24\" to \"172.30.0.0/16\"",
+		},
+		{
+			name:    "virtual media TLS toggle",
+			oldSpec: managedProvisioning().build(),
+			newSpec: managedProvisioning().DisableVirtualMediaTLS(true).build(),
+			wantErr: "disableVirtualMediaTLS cannot be changed",
+		},
+		{
+			name:         "annotation does not bypass validation",
+			oldSpec:      managedProvisioning().build(),
+			newSpec:      managedProvisioning().ProvisioningNetworkCIDR("172.30.0.0/16").ProvisioningIP("10.0.0.1").build(),
+			annotations:  allowDisruptive,
+			wantErr:      "provisioningIP \"10.0.0.1\" is not in the range",
+			wantWarnings: 1,
+		},
+		{
+			name:         "deprecated DHCP external",
+			oldSpec:      unmanagedProvisioning().ProvisioningNetwork("").ProvisioningDHCPExternal(true).build(),
+			newSpec:      unmanagedProvisioning().ProvisioningNetwork("").ProvisioningDHCPExternal(true).build(),
+			wantWarnings: 1,
+		},
+		{
+			name:         "unsupported config overrides",
+			oldSpec:      managedProvisioning().build(),
+			newSpec:      managedProvisioning().IronicAgentImage("quay.io/openshift/ironic-agent:latest").build(),
+			wantWarnings: 1,
+		},
+		{
+			name:    "pre-provisioning image without checksum",
+			oldSpec: managedProvisioning().PreProvisioningOSDownloadURLs(PreProvisioningOSDownloadURLs{RootfsURL: "http://example.com/live-rootfs.img"}).build(),
+			newSpec: managedProvisioning().PreProvisioningOSDownloadURLs(PreProvisioningOSDownloadURLs{
+				KernelURL: "http://example.com/live-kernel?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+				RootfsURL: "http://example.com/live-rootfs.img",
+			}).build(),
+			wantWarnings: 1,
+		},
+		{
+			name:         "invalid NTP server stored before the upgrade",
+			oldSpec:      managedProvisioning().AdditionalNTPServers([]string{"ntp_server"}).build(),
+			newSpec:      managedProvisioning().AdditionalNTPServers([]string{"ntp_server"}).ProvisioningDHCPRange("172.30.20.20, 172.30.20.90").build(),
+			wantWarnings: 1,
+		},
+		{
+			name:    "invalid NTP server added",
+			oldSpec: managedProvisioning().build(),
+			newSpec: managedProvisioning().AdditionalNTPServers([]string{"ntp_server"}).build(),
+			wantErr: "additionalNTPServers: \"ntp_server\" is neither an IP address nor a valid hostname",
+		},
+	}
+	enabledFeatures = EnabledFeatures{
+		ProvisioningNetwork: map[ProvisioningNetwork]bool{
+			ProvisioningNetworkDisabled:  true,
+			ProvisioningNetworkUnmanaged: true,
+			ProvisioningNetworkManaged:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldObj := &Provisioning{ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration", Annotations: tt.oldAnnotations}, Spec: *tt.oldSpec}
+			newObj := &Provisioning{ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration", Annotations: tt.annotations}, Spec: *tt.newSpec}
+			warnings, err := newObj.ValidateUpdate(context.TODO(), oldObj, newObj)
+			if !errorContains(err, tt.wantErr) {
+				t.Errorf("Provisioning.ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("Provisioning.ValidateUpdate() warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestProvisioningDefault(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         *ProvisioningSpec
+		annotations  map[string]string
+		wantNetwork  ProvisioningNetwork
+		wantRange    string
+		wantDefault  string
+		wantInterval int
+		wantCache    *ImageCache
+	}{
+		{
+			name:        "managed keeps DHCP range",
+			spec:        managedProvisioning().build(),
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "172.30.20.11, 172.30.20.101",
+		},
+		{
+			name:        "network mode from DHCP external",
+			spec:        unmanagedProvisioning().ProvisioningNetwork("").ProvisioningDHCPExternal(true).build(),
+			wantNetwork: ProvisioningNetworkUnmanaged,
+		},
+		{
+			name:        "network mode defaults to managed",
+			spec:        managedProvisioning().ProvisioningNetwork("").ProvisioningDHCPRange("").build(),
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "172.30.20.10,172.30.20.100",
+			wantDefault: "172.30.20.10,172.30.20.100",
+		},
+		{
+			name:        "default DHCP range follows the CIDR",
+			spec:        managedProvisioning().ProvisioningNetworkCIDR("172.30.40.0/24").ProvisioningIP("172.30.40.3").ProvisioningDHCPRange("172.30.20.10,172.30.20.100").build(),
+			annotations: map[string]string{DefaultDHCPRangeAnnotation: "172.30.20.10,172.30.20.100"},
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "172.30.40.10,172.30.40.100",
+			wantDefault: "172.30.40.10,172.30.40.100",
+		},
+		{
+			name:        "DHCP range changed by the user",
+			spec:        managedProvisioning().ProvisioningDHCPRange("172.30.20.20,172.30.20.50").build(),
+			annotations: map[string]string{DefaultDHCPRangeAnnotation: "172.30.20.10,172.30.20.100"},
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "172.30.20.20,172.30.20.50",
+		},
+		{
+			name:        "IPv6 DHCP range",
+			spec:        managedProvisioning().ProvisioningIP("fd2e:6f44:5dd8:b856::3").ProvisioningNetworkCIDR("fd2e:6f44:5dd8:b856::/64").ProvisioningDHCPRange("").build(),
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "fd2e:6f44:5dd8:b856::a,fd2e:6f44:5dd8:b856::64",
+			wantDefault: "fd2e:6f44:5dd8:b856::a,fd2e:6f44:5dd8:b856::64",
+		},
+		{
+			name:        "CIDR too small for DHCP range",
+			spec:        managedProvisioning().ProvisioningNetworkCIDR("172.30.20.0/26").ProvisioningDHCPRange("").build(),
+			wantNetwork: ProvisioningNetworkManaged,
+		},
+		{
+			name:        "no DHCP range when unmanaged",
+			spec:        unmanagedProvisioning().build(),
+			wantNetwork: ProvisioningNetworkUnmanaged,
+		},
+		{
+			name:         "sensor collection interval",
+			spec:         managedProvisioning().PrometheusExporter(&PrometheusExporter{Enabled: true}).build(),
+			wantNetwork:  ProvisioningNetworkManaged,
+			wantRange:    "172.30.20.11, 172.30.20.101",
+			wantInterval: DefaultSensorCollectionInterval,
+		},
+		{
+			name:         "sensor collection interval set",
+			spec:         managedProvisioning().PrometheusExporter(&PrometheusExporter{Enabled: true, SensorCollectionInterval: 120}).build(),
+			wantNetwork:  ProvisioningNetworkManaged,
+			wantRange:    "172.30.20.11, 172.30.20.101",
+			wantInterval: 120,
+		},
+		{
+			name:        "image cache",
+			spec:        managedProvisioning().ImageCache(&ImageCache{KeepVersions: 3}).build(),
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "172.30.20.11, 172.30.20.101",
+			wantCache:   &ImageCache{KeepVersions: 3, MinFreeSpacePercent: DefaultImageCacheMinFreeSpacePercent},
+		},
+		{
+			name:        "image cache empty",
+			spec:        managedProvisioning().ImageCache(&ImageCache{}).build(),
+			wantNetwork: ProvisioningNetworkManaged,
+			wantRange:   "172.30.20.11, 172.30.20.101",
+			wantCache:   &ImageCache{KeepVersions: DefaultImageCacheKeepVersions, MinFreeSpacePercent: DefaultImageCacheMinFreeSpacePercent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &Provisioning{ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration", Annotations: tt.annotations}, Spec: *tt.spec}
+			if err := obj.Default(context.TODO(), obj); err != nil {
+				t.Fatalf("Provisioning.Default() error = %v", err)
+			}
+			if obj.Annotations[DefaultDHCPRangeAnnotation] != tt.wantDefault {
+				t.Errorf("Provisioning.Default() %s = %q, want %q", DefaultDHCPRangeAnnotation, obj.Annotations[DefaultDHCPRangeAnnotation], tt.wantDefault)
+			}
+			if obj.Spec.ProvisioningNetwork != tt.wantNetwork {
+				t.Errorf("Provisioning.Default() provisioningNetwork = %q, want %q", obj.Spec.ProvisioningNetwork, tt.wantNetwork)
+			}
+			if obj.Spec.ProvisioningDHCPRange != tt.wantRange {
+				t.Errorf("Provisioning.Default() provisioningDHCPRange = %q, want %q", obj.Spec.ProvisioningDHCPRange, tt.wantRange)
+			}
+			interval := 0
+			if obj.Spec.PrometheusExporter != nil {
+				interval = obj.Spec.PrometheusExporter.SensorCollectionInterval
+			}
+			if interval != tt.wantInterval {
+				t.Errorf("Provisioning.Default() sensorCollectionInterval = %d, want %d", interval, tt.wantInterval)
+			}
+			if !reflect.DeepEqual(obj.Spec.ImageCache, tt.wantCache) {
+				t.Errorf("Provisioning.Default() imageCache = %+v, want %+v", obj.Spec.ImageCache, tt.wantCache)
+			}
+		})
+	}
+}
@@ -20,9 +20,91 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalImage) DeepCopyInto(out *AdditionalImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalImage.
+func (in *AdditionalImage) DeepCopy() *AdditionalImage {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BaremetalOperator) DeepCopyInto(out *BaremetalOperator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BaremetalOperator.
+func (in *BaremetalOperator) DeepCopy() *BaremetalOperator {
+	if in == nil {
+		return nil
+	}
+	out := new(BaremetalOperator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CachedImageStatus) DeepCopyInto(out *CachedImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CachedImageStatus.
+func (in *CachedImageStatus) DeepCopy() *CachedImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CachedImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleaningPolicy) DeepCopyInto(out *CleaningPolicy) {
+	*out = *in
+	if in.EraseMethods != nil {
+		in, out := &in.EraseMethods, &out.EraseMethods
+		*out = make([]EraseMethod, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleaningPolicy.
+func (in *CleaningPolicy) DeepCopy() *CleaningPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CleaningPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugSSHKey) DeepCopyInto(out *DebugSSHKey) {
+	*out = *in
+	in.Expires.DeepCopyInto(&out.Expires)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugSSHKey.
+func (in *DebugSSHKey) DeepCopy() *DebugSSHKey {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugSSHKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnabledFeatures) DeepCopyInto(out *EnabledFeatures) {
 	*out = *in
@@ -33,6 +115,13 @@ func (in *EnabledFeatures) DeepCopyInto(out *EnabledFeatures) {
 			(*out)[key] = val
 		}
 	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make(map[v1.IPFamily]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnabledFeatures.
@@ -45,6 +134,205 @@ func (in *EnabledFeatures) DeepCopy() *EnabledFeatures {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalURL) DeepCopyInto(out *ExternalURL) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalURL.
+func (in *ExternalURL) DeepCopy() *ExternalURL {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalURL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareManager) DeepCopyInto(out *HardwareManager) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManager.
+func (in *HardwareManager) DeepCopy() *HardwareManager {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCache) DeepCopyInto(out *ImageCache) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalImages != nil {
+		in, out := &in.AdditionalImages, &out.AdditionalImages
+		*out = make([]AdditionalImage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCache.
+func (in *ImageCache) DeepCopy() *ImageCache {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCacheEndpoint) DeepCopyInto(out *ImageCacheEndpoint) {
+	*out = *in
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCacheEndpoint.
+func (in *ImageCacheEndpoint) DeepCopy() *ImageCacheEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCacheEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCacheNodeStatus) DeepCopyInto(out *ImageCacheNodeStatus) {
+	*out = *in
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = new(CachedImageStatus)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]CachedImageStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCacheNodeStatus.
+func (in *ImageCacheNodeStatus) DeepCopy() *ImageCacheNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCacheNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Inspection) DeepCopyInto(out *Inspection) {
+	*out = *in
+	if in.Collectors != nil {
+		in, out := &in.Collectors, &out.Collectors
+		*out = make([]InspectionCollector, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]InspectionHook, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Inspection.
+func (in *Inspection) DeepCopy() *Inspection {
+	if in == nil {
+		return nil
+	}
+	out := new(Inspection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IronicAgent) DeepCopyInto(out *IronicAgent) {
+	*out = *in
+	if in.SSHKey != nil {
+		in, out := &in.SSHKey, &out.SSHKey
+		*out = new(RamdiskSSHKey)
+		**out = **in
+	}
+	if in.DebugSSHKey != nil {
+		in, out := &in.DebugSSHKey, &out.DebugSSHKey
+		*out = new(DebugSSHKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HardwareManagers != nil {
+		in, out := &in.HardwareManagers, &out.HardwareManagers
+		*out = make([]HardwareManager, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IronicAgent.
+func (in *IronicAgent) DeepCopy() *IronicAgent {
+	if in == nil {
+		return nil
+	}
+	out := new(IronicAgent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IronicTuning) DeepCopyInto(out *IronicTuning) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IronicTuning.
+func (in *IronicTuning) DeepCopy() *IronicTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(IronicTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSImageStatus) DeepCopyInto(out *OSImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSImageStatus.
+func (in *OSImageStatus) DeepCopy() *OSImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OSImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreProvisioningOSDownloadURLs) DeepCopyInto(out *PreProvisioningOSDownloadURLs) {
 	*out = *in
@@ -102,6 +390,21 @@ func (in *Provisioning) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningInterfaceStatus) DeepCopyInto(out *ProvisioningInterfaceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningInterfaceStatus.
+func (in *ProvisioningInterfaceStatus) DeepCopy() *ProvisioningInterfaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningInterfaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProvisioningList) DeepCopyInto(out *ProvisioningList) {
 	*out = *in
@@ -163,6 +466,36 @@ func (in *ProvisioningSpec) DeepCopyInto(out *ProvisioningSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ImageCache != nil {
+		in, out := &in.ImageCache, &out.ImageCache
+		*out = new(ImageCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BaremetalOperator != nil {
+		in, out := &in.BaremetalOperator, &out.BaremetalOperator
+		*out = new(BaremetalOperator)
+		**out = **in
+	}
+	if in.IronicTuning != nil {
+		in, out := &in.IronicTuning, &out.IronicTuning
+		*out = new(IronicTuning)
+		**out = **in
+	}
+	if in.CleaningPolicy != nil {
+		in, out := &in.CleaningPolicy, &out.CleaningPolicy
+		*out = new(CleaningPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Inspection != nil {
+		in, out := &in.Inspection, &out.Inspection
+		*out = new(Inspection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IronicAgent != nil {
+		in, out := &in.IronicAgent, &out.IronicAgent
+		*out = new(IronicAgent)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningSpec.
@@ -179,6 +512,42 @@ func (in *ProvisioningSpec) DeepCopy() *ProvisioningSpec {
 func (in *ProvisioningStatus) DeepCopyInto(out *ProvisioningStatus) {
 	*out = *in
 	in.OperatorStatus.DeepCopyInto(&out.OperatorStatus)
+	if in.ProvisioningInterfaces != nil {
+		in, out := &in.ProvisioningInterfaces, &out.ProvisioningInterfaces
+		*out = make([]ProvisioningInterfaceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalURLs != nil {
+		in, out := &in.ExternalURLs, &out.ExternalURLs
+		*out = make([]ExternalURL, len(*in))
+		copy(*out, *in)
+	}
+	if in.OSImage != nil {
+		in, out := &in.OSImage, &out.OSImage
+		*out = new(OSImageStatus)
+		**out = **in
+	}
+	if in.ImageCache != nil {
+		in, out := &in.ImageCache, &out.ImageCache
+		*out = make([]ImageCacheNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageCacheEndpoints != nil {
+		in, out := &in.ImageCacheEndpoints, &out.ImageCacheEndpoints
+		*out = make([]ImageCacheEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RamdiskSSHKeys != nil {
+		in, out := &in.RamdiskSSHKeys, &out.RamdiskSSHKeys
+		*out = make([]RamdiskSSHKeyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningStatus.
@@ -191,6 +560,40 @@ func (in *ProvisioningStatus) DeepCopy() *ProvisioningStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RamdiskSSHKey) DeepCopyInto(out *RamdiskSSHKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RamdiskSSHKey.
+func (in *RamdiskSSHKey) DeepCopy() *RamdiskSSHKey {
+	if in == nil {
+		return nil
+	}
+	out := new(RamdiskSSHKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RamdiskSSHKeyStatus) DeepCopyInto(out *RamdiskSSHKeyStatus) {
+	*out = *in
+	if in.Expires != nil {
+		in, out := &in.Expires, &out.Expires
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RamdiskSSHKeyStatus.
+func (in *RamdiskSSHKeyStatus) DeepCopy() *RamdiskSSHKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RamdiskSSHKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnsupportedConfigOverrides) DeepCopyInto(out *UnsupportedConfigOverrides) {
 	*out = *in
@@ -0,0 +1,475 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &Provisioning{}
+
+// dhcpExternalAnnotation keeps the deprecated v1alpha1
+// ProvisioningDHCPExternal, which has no v1beta1 field, so that it survives
+// a round trip through v1beta1.
+const dhcpExternalAnnotation = "metal3.io/provisioning-dhcp-external"
+
+// ConvertTo converts this Provisioning to the Hub version (v1alpha1).
+func (src *Provisioning) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Provisioning)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	dhcpExternal := dst.Annotations[dhcpExternalAnnotation] == "true"
+	delete(dst.Annotations, dhcpExternalAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	network := &src.Spec.Network
+	dst.Spec = v1alpha1.ProvisioningSpec{
+		ProvisioningInterface:          network.Interface,
+		ProvisioningMacAddresses:       network.MACAddresses,
+		ProvisioningIP:                 network.IP,
+		ProvisioningNetworkCIDR:        network.CIDR,
+		ProvisioningDHCPRange:          joinDHCPRange(src.Spec.DHCP.RangeStart, src.Spec.DHCP.RangeEnd),
+		ProvisioningNetworkGateway:     network.Gateway,
+		ProvisioningOSDownloadURL:      src.Spec.Images.OSDownloadURL,
+		ProvisioningOSChecksumURL:      src.Spec.Images.OSChecksumURL,
+		ProvisioningNetwork:            v1alpha1.ProvisioningNetwork(network.Mode),
+		ProvisioningDHCPExternal:       dhcpExternal,
+		ProvisioningDNS:                src.Spec.DHCP.ProvideDNS,
+		AdditionalNTPServers:           network.AdditionalNTPServers,
+		WatchAllNamespaces:             src.Spec.WatchAllNamespaces,
+		BootIsoSource:                  v1alpha1.BootIsoSource(src.Spec.Images.BootIsoSource),
+		VirtualMediaViaExternalNetwork: network.VirtualMediaViaExternalNetwork,
+		PreProvisioningOSDownloadURLs: v1alpha1.PreProvisioningOSDownloadURLs{
+			IsoURL:       src.Spec.Images.PreProvisioning.IsoURL,
+			KernelURL:    src.Spec.Images.PreProvisioning.KernelURL,
+			InitramfsURL: src.Spec.Images.PreProvisioning.InitramfsURL,
+			RootfsURL:    src.Spec.Images.PreProvisioning.RootfsURL,
+		},
+		DisableVirtualMediaTLS: src.Spec.TLS.DisableVirtualMedia,
+		ExternalIPs:            network.ExternalIPs,
+		IronicProxy:            v1alpha1.IronicProxyMode(network.IronicProxy),
+	}
+	if network.VLAN != nil {
+		dst.Spec.ProvisioningVLANID = network.VLAN.ID
+		dst.Spec.ProvisioningVLANParentInterface = network.VLAN.ParentInterface
+	}
+	if src.Spec.Monitoring != nil {
+		dst.Spec.PrometheusExporter = &v1alpha1.PrometheusExporter{
+			Enabled:                       src.Spec.Monitoring.Enabled,
+			SensorCollectionInterval:      src.Spec.Monitoring.SensorCollectionInterval,
+			DisableDefaultPrometheusRules: src.Spec.Monitoring.DisableDefaultPrometheusRules,
+		}
+	}
+	if src.Spec.ImageCache != nil {
+		dst.Spec.ImageCache = &v1alpha1.ImageCache{
+			MaxSize:             src.Spec.ImageCache.MaxSize,
+			KeepVersions:        src.Spec.ImageCache.KeepVersions,
+			MinFreeSpacePercent: src.Spec.ImageCache.MinFreeSpacePercent,
+			NodeSelector:        src.Spec.ImageCache.NodeSelector,
+			Tolerations:         src.Spec.ImageCache.Tolerations,
+			TopologyAwareURLs:   src.Spec.ImageCache.TopologyAwareURLs,
+		}
+		for _, image := range src.Spec.ImageCache.AdditionalImages {
+			dst.Spec.ImageCache.AdditionalImages = append(dst.Spec.ImageCache.AdditionalImages, v1alpha1.AdditionalImage{
+				URL:      image.URL,
+				Checksum: image.Checksum,
+			})
+		}
+	}
+	if agent := src.Spec.IronicAgent; agent != nil {
+		dst.Spec.IronicAgent = &v1alpha1.IronicAgent{
+			ExtraKernelParams: agent.ExtraKernelParams,
+		}
+		if key := agent.SSHKey; key != nil {
+			dst.Spec.IronicAgent.SSHKey = &v1alpha1.RamdiskSSHKey{
+				PublicKey:  key.PublicKey,
+				SecretName: key.SecretName,
+			}
+		}
+		if key := agent.DebugSSHKey; key != nil {
+			dst.Spec.IronicAgent.DebugSSHKey = &v1alpha1.DebugSSHKey{
+				PublicKey: key.PublicKey,
+				Expires:   key.Expires,
+			}
+		}
+		for _, manager := range agent.HardwareManagers {
+			dst.Spec.IronicAgent.HardwareManagers = append(dst.Spec.IronicAgent.HardwareManagers, v1alpha1.HardwareManager{
+				Name:  manager.Name,
+				Image: manager.Image,
+			})
+		}
+	}
+	if inspection := src.Spec.Inspection; inspection != nil {
+		dst.Spec.Inspection = &v1alpha1.Inspection{
+			CollectLLDP:       inspection.CollectLLDP,
+			ExtraKernelParams: inspection.ExtraKernelParams,
+		}
+		for _, collector := range inspection.Collectors {
+			dst.Spec.Inspection.Collectors = append(dst.Spec.Inspection.Collectors, v1alpha1.InspectionCollector(collector))
+		}
+		for _, hook := range inspection.Hooks {
+			dst.Spec.Inspection.Hooks = append(dst.Spec.Inspection.Hooks, v1alpha1.InspectionHook(hook))
+		}
+	}
+	if cleaning := src.Spec.CleaningPolicy; cleaning != nil {
+		dst.Spec.CleaningPolicy = &v1alpha1.CleaningPolicy{
+			Mode: v1alpha1.CleaningMode(cleaning.Mode),
+		}
+		for _, method := range cleaning.EraseMethods {
+			dst.Spec.CleaningPolicy.EraseMethods = append(dst.Spec.CleaningPolicy.EraseMethods, v1alpha1.EraseMethod(method))
+		}
+	}
+	if tuning := src.Spec.IronicTuning; tuning != nil {
+		dst.Spec.IronicTuning = &v1alpha1.IronicTuning{
+			DeployCallbackTimeout:   tuning.DeployCallbackTimeout,
+			CleanCallbackTimeout:    tuning.CleanCallbackTimeout,
+			PowerStateSyncInterval:  tuning.PowerStateSyncInterval,
+			PowerStateChangeTimeout: tuning.PowerStateChangeTimeout,
+			MaxConcurrentDeploys:    tuning.MaxConcurrentDeploys,
+			MaxConcurrentCleans:     tuning.MaxConcurrentCleans,
+		}
+	}
+	if bmo := src.Spec.BaremetalOperator; bmo != nil {
+		dst.Spec.BaremetalOperator = &v1alpha1.BaremetalOperator{
+			MaxConcurrentReconciles:     bmo.MaxConcurrentReconciles,
+			ProvisioningLimit:           bmo.ProvisioningLimit,
+			LiveISOPersistentBootDevice: v1alpha1.PersistentBootDevice(bmo.LiveISOPersistentBootDevice),
+			DetachedDeleteAction:        v1alpha1.DetachedDeleteAction(bmo.DetachedDeleteAction),
+			LogLevel:                    bmo.LogLevel,
+		}
+	}
+	if src.Spec.UnsupportedConfigOverrides != nil {
+		dst.Spec.UnsupportedConfigOverrides = &v1alpha1.UnsupportedConfigOverrides{
+			IronicAgentImage: src.Spec.UnsupportedConfigOverrides.IronicAgentImage,
+		}
+	}
+
+	dst.Status = v1alpha1.ProvisioningStatus{
+		OperatorStatus: src.Status.OperatorStatus,
+	}
+	for _, iface := range src.Status.ProvisioningInterfaces {
+		dst.Status.ProvisioningInterfaces = append(dst.Status.ProvisioningInterfaces, v1alpha1.ProvisioningInterfaceStatus{
+			NodeName:   iface.NodeName,
+			State:      v1alpha1.ProvisioningInterfaceState(iface.State),
+			Interface:  iface.Interface,
+			MACAddress: iface.MACAddress,
+			Message:    iface.Message,
+		})
+	}
+	for _, url := range src.Status.ExternalURLs {
+		dst.Status.ExternalURLs = append(dst.Status.ExternalURLs, v1alpha1.ExternalURL{
+			Consumer: v1alpha1.ExternalURLConsumer(url.Consumer),
+			URL:      url.URL,
+		})
+	}
+	if src.Status.OSImage != nil {
+		dst.Status.OSImage = &v1alpha1.OSImageStatus{
+			Format:      v1alpha1.OSImageFormat(src.Status.OSImage.Format),
+			Compression: v1alpha1.OSImageCompression(src.Status.OSImage.Compression),
+			CachedName:  src.Status.OSImage.CachedName,
+		}
+	}
+	for _, node := range src.Status.ImageCache {
+		nodeStatus := v1alpha1.ImageCacheNodeStatus{
+			NodeName:       node.NodeName,
+			CachedImages:   node.CachedImages,
+			UsedBytes:      node.UsedBytes,
+			CapacityBytes:  node.CapacityBytes,
+			AvailableBytes: node.AvailableBytes,
+			LowDiskSpace:   node.LowDiskSpace,
+			Message:        node.Message,
+		}
+		if node.Content != nil {
+			nodeStatus.Content = &v1alpha1.CachedImageStatus{
+				Name:            node.Content.Name,
+				State:           v1alpha1.CachedImageState(node.Content.State),
+				DownloadedBytes: node.Content.DownloadedBytes,
+				TotalBytes:      node.Content.TotalBytes,
+			}
+		}
+		for _, image := range node.Images {
+			nodeStatus.Images = append(nodeStatus.Images, v1alpha1.CachedImageStatus{
+				Name:            image.Name,
+				State:           v1alpha1.CachedImageState(image.State),
+				DownloadedBytes: image.DownloadedBytes,
+				TotalBytes:      image.TotalBytes,
+			})
+		}
+		dst.Status.ImageCache = append(dst.Status.ImageCache, nodeStatus)
+	}
+	for _, endpoint := range src.Status.ImageCacheEndpoints {
+		dst.Status.ImageCacheEndpoints = append(dst.Status.ImageCacheEndpoints, v1alpha1.ImageCacheEndpoint{
+			Zone: endpoint.Zone,
+			URLs: endpoint.URLs,
+		})
+	}
+	dst.Status.IronicProxy = v1alpha1.IronicProxyMode(src.Status.IronicProxy)
+	for _, key := range src.Status.RamdiskSSHKeys {
+		dst.Status.RamdiskSSHKeys = append(dst.Status.RamdiskSSHKeys, v1alpha1.RamdiskSSHKeyStatus{
+			Fingerprint: key.Fingerprint,
+			Source:      v1alpha1.RamdiskSSHKeySource(key.Source),
+			Expires:     key.Expires,
+			Error:       key.Error,
+		})
+	}
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version.
+func (dst *Provisioning) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Provisioning)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+	if src.Spec.ProvisioningDHCPExternal {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[dhcpExternalAnnotation] = "true"
+	}
+
+	// The deprecated ProvisioningDHCPExternal only matters when
+	// ProvisioningNetwork is not set.
+	mode := ProvisioningNetworkMode(src.Spec.ProvisioningNetwork)
+	if mode == "" && src.Spec.ProvisioningDHCPExternal {
+		mode = ProvisioningNetworkModeUnmanaged
+	}
+
+	rangeStart, rangeEnd := splitDHCPRange(src.Spec.ProvisioningDHCPRange)
+	dst.Spec = ProvisioningSpec{
+		Network: NetworkSpec{
+			Mode:                           mode,
+			Interface:                      src.Spec.ProvisioningInterface,
+			MACAddresses:                   src.Spec.ProvisioningMacAddresses,
+			IP:                             src.Spec.ProvisioningIP,
+			CIDR:                           src.Spec.ProvisioningNetworkCIDR,
+			Gateway:                        src.Spec.ProvisioningNetworkGateway,
+			AdditionalNTPServers:           src.Spec.AdditionalNTPServers,
+			ExternalIPs:                    src.Spec.ExternalIPs,
+			VirtualMediaViaExternalNetwork: src.Spec.VirtualMediaViaExternalNetwork,
+			IronicProxy:                    IronicProxyMode(src.Spec.IronicProxy),
+		},
+		DHCP: DHCPSpec{
+			RangeStart: rangeStart,
+			RangeEnd:   rangeEnd,
+			ProvideDNS: src.Spec.ProvisioningDNS,
+		},
+		Images: ImagesSpec{
+			OSDownloadURL: src.Spec.ProvisioningOSDownloadURL,
+			OSChecksumURL: src.Spec.ProvisioningOSChecksumURL,
+			BootIsoSource: BootIsoSource(src.Spec.BootIsoSource),
+			PreProvisioning: LiveImagesSpec{
+				IsoURL:       src.Spec.PreProvisioningOSDownloadURLs.IsoURL,
+				KernelURL:    src.Spec.PreProvisioningOSDownloadURLs.KernelURL,
+				InitramfsURL: src.Spec.PreProvisioningOSDownloadURLs.InitramfsURL,
+				RootfsURL:    src.Spec.PreProvisioningOSDownloadURLs.RootfsURL,
+			},
+		},
+		TLS: TLSSpec{
+			DisableVirtualMedia: src.Spec.DisableVirtualMediaTLS,
+		},
+		WatchAllNamespaces: src.Spec.WatchAllNamespaces,
+	}
+	if src.Spec.ProvisioningVLANID != 0 {
+		dst.Spec.Network.VLAN = &VLANSpec{
+			ID:              src.Spec.ProvisioningVLANID,
+			ParentInterface: src.Spec.ProvisioningVLANParentInterface,
+		}
+	}
+	if src.Spec.PrometheusExporter != nil {
+		dst.Spec.Monitoring = &MonitoringSpec{
+			Enabled:                       src.Spec.PrometheusExporter.Enabled,
+			SensorCollectionInterval:      src.Spec.PrometheusExporter.SensorCollectionInterval,
+			DisableDefaultPrometheusRules: src.Spec.PrometheusExporter.DisableDefaultPrometheusRules,
+		}
+	}
+	if src.Spec.ImageCache != nil {
+		dst.Spec.ImageCache = &ImageCacheSpec{
+			MaxSize:             src.Spec.ImageCache.MaxSize,
+			KeepVersions:        src.Spec.ImageCache.KeepVersions,
+			MinFreeSpacePercent: src.Spec.ImageCache.MinFreeSpacePercent,
+			NodeSelector:        src.Spec.ImageCache.NodeSelector,
+			Tolerations:         src.Spec.ImageCache.Tolerations,
+			TopologyAwareURLs:   src.Spec.ImageCache.TopologyAwareURLs,
+		}
+		for _, image := range src.Spec.ImageCache.AdditionalImages {
+			dst.Spec.ImageCache.AdditionalImages = append(dst.Spec.ImageCache.AdditionalImages, AdditionalImage{
+				URL:      image.URL,
+				Checksum: image.Checksum,
+			})
+		}
+	}
+	if agent := src.Spec.IronicAgent; agent != nil {
+		dst.Spec.IronicAgent = &IronicAgentSpec{
+			ExtraKernelParams: agent.ExtraKernelParams,
+		}
+		if key := agent.SSHKey; key != nil {
+			dst.Spec.IronicAgent.SSHKey = &RamdiskSSHKey{
+				PublicKey:  key.PublicKey,
+				SecretName: key.SecretName,
+			}
+		}
+		if key := agent.DebugSSHKey; key != nil {
+			dst.Spec.IronicAgent.DebugSSHKey = &DebugSSHKey{
+				PublicKey: key.PublicKey,
+				Expires:   key.Expires,
+			}
+		}
+		for _, manager := range agent.HardwareManagers {
+			dst.Spec.IronicAgent.HardwareManagers = append(dst.Spec.IronicAgent.HardwareManagers, HardwareManager{
+				Name:  manager.Name,
+				Image: manager.Image,
+			})
+		}
+	}
+	if inspection := src.Spec.Inspection; inspection != nil {
+		dst.Spec.Inspection = &InspectionSpec{
+			CollectLLDP:       inspection.CollectLLDP,
+			ExtraKernelParams: inspection.ExtraKernelParams,
+		}
+		for _, collector := range inspection.Collectors {
+			dst.Spec.Inspection.Collectors = append(dst.Spec.Inspection.Collectors, InspectionCollector(collector))
+		}
+		for _, hook := range inspection.Hooks {
+			dst.Spec.Inspection.Hooks = append(dst.Spec.Inspection.Hooks, InspectionHook(hook))
+		}
+	}
+	if cleaning := src.Spec.CleaningPolicy; cleaning != nil {
+		dst.Spec.CleaningPolicy = &CleaningPolicySpec{
+			Mode: CleaningMode(cleaning.Mode),
+		}
+		for _, method := range cleaning.EraseMethods {
+			dst.Spec.CleaningPolicy.EraseMethods = append(dst.Spec.CleaningPolicy.EraseMethods, EraseMethod(method))
+		}
+	}
+	if tuning := src.Spec.IronicTuning; tuning != nil {
+		dst.Spec.IronicTuning = &IronicTuningSpec{
+			DeployCallbackTimeout:   tuning.DeployCallbackTimeout,
+			CleanCallbackTimeout:    tuning.CleanCallbackTimeout,
+			PowerStateSyncInterval:  tuning.PowerStateSyncInterval,
+			PowerStateChangeTimeout: tuning.PowerStateChangeTimeout,
+			MaxConcurrentDeploys:    tuning.MaxConcurrentDeploys,
+			MaxConcurrentCleans:     tuning.MaxConcurrentCleans,
+		}
+	}
+	if bmo := src.Spec.BaremetalOperator; bmo != nil {
+		dst.Spec.BaremetalOperator = &BaremetalOperatorSpec{
+			MaxConcurrentReconciles:     bmo.MaxConcurrentReconciles,
+			ProvisioningLimit:           bmo.ProvisioningLimit,
+			LiveISOPersistentBootDevice: PersistentBootDevice(bmo.LiveISOPersistentBootDevice),
+			DetachedDeleteAction:        DetachedDeleteAction(bmo.DetachedDeleteAction),
+			LogLevel:                    bmo.LogLevel,
+		}
+	}
+	if src.Spec.UnsupportedConfigOverrides != nil {
+		dst.Spec.UnsupportedConfigOverrides = &UnsupportedConfigOverrides{
+			IronicAgentImage: src.Spec.UnsupportedConfigOverrides.IronicAgentImage,
+		}
+	}
+
+	dst.Status = ProvisioningStatus{
+		OperatorStatus: src.Status.OperatorStatus,
+	}
+	for _, iface := range src.Status.ProvisioningInterfaces {
+		dst.Status.ProvisioningInterfaces = append(dst.Status.ProvisioningInterfaces, ProvisioningInterfaceStatus{
+			NodeName:   iface.NodeName,
+			State:      ProvisioningInterfaceState(iface.State),
+			Interface:  iface.Interface,
+			MACAddress: iface.MACAddress,
+			Message:    iface.Message,
+		})
+	}
+	for _, url := range src.Status.ExternalURLs {
+		dst.Status.ExternalURLs = append(dst.Status.ExternalURLs, ExternalURL{
+			Consumer: ExternalURLConsumer(url.Consumer),
+			URL:      url.URL,
+		})
+	}
+	if src.Status.OSImage != nil {
+		dst.Status.OSImage = &OSImageStatus{
+			Format:      OSImageFormat(src.Status.OSImage.Format),
+			Compression: OSImageCompression(src.Status.OSImage.Compression),
+			CachedName:  src.Status.OSImage.CachedName,
+		}
+	}
+	for _, node := range src.Status.ImageCache {
+		nodeStatus := ImageCacheNodeStatus{
+			NodeName:       node.NodeName,
+			CachedImages:   node.CachedImages,
+			UsedBytes:      node.UsedBytes,
+			CapacityBytes:  node.CapacityBytes,
+			AvailableBytes: node.AvailableBytes,
+			LowDiskSpace:   node.LowDiskSpace,
+			Message:        node.Message,
+		}
+		if node.Content != nil {
+			nodeStatus.Content = &CachedImageStatus{
+				Name:            node.Content.Name,
+				State:           CachedImageState(node.Content.State),
+				DownloadedBytes: node.Content.DownloadedBytes,
+				TotalBytes:      node.Content.TotalBytes,
+			}
+		}
+		for _, image := range node.Images {
+			nodeStatus.Images = append(nodeStatus.Images, CachedImageStatus{
+				Name:            image.Name,
+				State:           CachedImageState(image.State),
+				DownloadedBytes: image.DownloadedBytes,
+				TotalBytes:      image.TotalBytes,
+			})
+		}
+		dst.Status.ImageCache = append(dst.Status.ImageCache, nodeStatus)
+	}
+	for _, endpoint := range src.Status.ImageCacheEndpoints {
+		dst.Status.ImageCacheEndpoints = append(dst.Status.ImageCacheEndpoints, ImageCacheEndpoint{
+			Zone: endpoint.Zone,
+			URLs: endpoint.URLs,
+		})
+	}
+	dst.Status.IronicProxy = IronicProxyMode(src.Status.IronicProxy)
+	for _, key := range src.Status.RamdiskSSHKeys {
+		dst.Status.RamdiskSSHKeys = append(dst.Status.RamdiskSSHKeys, RamdiskSSHKeyStatus{
+			Fingerprint: key.Fingerprint,
+			Source:      RamdiskSSHKeySource(key.Source),
+			Expires:     key.Expires,
+			Error:       key.Error,
+		})
+	}
+
+	return nil
+}
+
+// splitDHCPRange splits a v1alpha1 "start,end" DHCP range. A range that
+// cannot be split is kept as is in the start address, so that it survives a
+// round trip and is still reported by validation.
+func splitDHCPRange(dhcpRange string) (string, string) {
+	parts := strings.Split(dhcpRange, ",")
+	if len(parts) != 2 {
+		return dhcpRange, ""
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+func joinDHCPRange(start, end string) string {
+	if end == "" {
+		return start
+	}
+	return start + "," + end
+}
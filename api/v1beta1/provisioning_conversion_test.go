@@ -0,0 +1,297 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func fullV1beta1Provisioning() *Provisioning {
+	return &Provisioning{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "provisioning-configuration",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: ProvisioningSpec{
+			Network: NetworkSpec{
+				Mode:                           ProvisioningNetworkModeManaged,
+				Interface:                      "prov",
+				MACAddresses:                   []string{"34:b3:2d:81:f8:fb"},
+				VLAN:                           &VLANSpec{ID: 100, ParentInterface: "bond0"},
+				IP:                             "172.30.20.3",
+				CIDR:                           "172.30.20.0/24",
+				Gateway:                        "172.30.20.1",
+				AdditionalNTPServers:           []string{"ntp.example.com"},
+				ExternalIPs:                    []string{"192.168.1.100", "fd00::100"},
+				VirtualMediaViaExternalNetwork: true,
+				IronicProxy:                    IronicProxyEnabled,
+			},
+			DHCP: DHCPSpec{
+				RangeStart: "172.30.20.11",
+				RangeEnd:   "172.30.20.101",
+				ProvideDNS: true,
+			},
+			Images: ImagesSpec{
+				OSDownloadURL: "http://172.22.0.1/images/rhcos.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+				BootIsoSource: BootIsoSourceHttp,
+				PreProvisioning: LiveImagesSpec{
+					IsoURL:       "http://172.22.0.1/images/live.iso",
+					KernelURL:    "http://172.22.0.1/images/live-kernel",
+					InitramfsURL: "http://172.22.0.1/images/live-initramfs.img",
+					RootfsURL:    "http://172.22.0.1/images/live-rootfs.img",
+				},
+			},
+			TLS: TLSSpec{DisableVirtualMedia: true},
+			Monitoring: &MonitoringSpec{
+				Enabled:                       true,
+				SensorCollectionInterval:      120,
+				DisableDefaultPrometheusRules: true,
+			},
+			ImageCache: &ImageCacheSpec{
+				MaxSize:             ptr.To(resource.MustParse("50Gi")),
+				KeepVersions:        3,
+				MinFreeSpacePercent: 15,
+				NodeSelector:        map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations: []corev1.Toleration{
+					{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+				},
+				TopologyAwareURLs: true,
+				AdditionalImages: []AdditionalImage{
+					{URL: "http://example.com/rhcos-414.qcow2.gz", Checksum: "a3b7d9c1e5f2a3b7d9c1e5f2a3b7d9c1e5f2a3b7d9c1e5f2a3b7d9c1e5f2a3b7"},
+					{URL: "http://example.com/custom.raw"},
+				},
+			},
+			WatchAllNamespaces: true,
+			BaremetalOperator: &BaremetalOperatorSpec{
+				MaxConcurrentReconciles:     8,
+				ProvisioningLimit:           40,
+				LiveISOPersistentBootDevice: PersistentBootDeviceAlways,
+				DetachedDeleteAction:        DetachedDeleteActionDelay,
+				LogLevel:                    operatorv1.Debug,
+			},
+			IronicTuning: &IronicTuningSpec{
+				DeployCallbackTimeout:   3600,
+				CleanCallbackTimeout:    7200,
+				PowerStateSyncInterval:  300,
+				PowerStateChangeTimeout: 120,
+				MaxConcurrentDeploys:    100,
+				MaxConcurrentCleans:     50,
+			},
+			CleaningPolicy: &CleaningPolicySpec{
+				Mode:         CleaningModeFull,
+				EraseMethods: []EraseMethod{EraseMethodNVMeSecureErase, EraseMethodOverwrite},
+			},
+			IronicAgent: &IronicAgentSpec{
+				ExtraKernelParams: "console=ttyS0",
+				SSHKey: &RamdiskSSHKey{
+					SecretName: "ramdisk-ssh-keys",
+				},
+				DebugSSHKey: &DebugSSHKey{
+					PublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGjYNtTbZsTM8qKcPaXcRDnCAB4amM/JG1yzvZIAed9g debug",
+					Expires:   metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+				},
+				HardwareManagers: []HardwareManager{
+					{Name: "vendor", Image: "quay.io/example/vendor-hardware-manager:v1"},
+				},
+			},
+			Inspection: &InspectionSpec{
+				Collectors:        []InspectionCollector{"extra-hardware", "numa-topology"},
+				CollectLLDP:       true,
+				Hooks:             []InspectionHook{"extra-hardware", "pci-devices"},
+				ExtraKernelParams: "ipa-inspection-dhcp-all-interfaces=1",
+			},
+			UnsupportedConfigOverrides: &UnsupportedConfigOverrides{IronicAgentImage: "quay.io/openshift/ironic-agent:latest"},
+		},
+		Status: ProvisioningStatus{
+			OperatorStatus: operatorv1.OperatorStatus{ObservedGeneration: 3},
+			ProvisioningInterfaces: []ProvisioningInterfaceStatus{
+				{NodeName: "master-0", State: "Found", Interface: "eth1", MACAddress: "34:b3:2d:81:f8:fb"},
+			},
+			ExternalURLs: []ExternalURL{
+				{Consumer: "VirtualMedia", URL: "http://192.168.1.100:6180"},
+			},
+			ImageCache: []ImageCacheNodeStatus{
+				{
+					NodeName: "master-0", CachedImages: 2, UsedBytes: 2 << 30, CapacityBytes: 100 << 30, AvailableBytes: 5 << 30, LowDiskSpace: true,
+					Content: &CachedImageStatus{Name: "rhcos.qcow2", State: "Verified", DownloadedBytes: 2 << 30, TotalBytes: 2 << 30},
+					Images: []CachedImageStatus{
+						{Name: "rhcos-414.qcow2", State: "Downloading", DownloadedBytes: 1 << 30, TotalBytes: 2 << 30},
+						{Name: "custom.raw", State: "Ready"},
+					},
+				},
+				{NodeName: "master-1", Message: "image cache pod is not running"},
+			},
+			ImageCacheEndpoints: []ImageCacheEndpoint{
+				{Zone: "rack-1", URLs: []string{"http://192.168.1.20:6181", "http://192.168.1.21:6181"}},
+			},
+			IronicProxy: IronicProxyEnabled,
+			RamdiskSSHKeys: []RamdiskSSHKeyStatus{
+				{Fingerprint: "SHA256:kG4VzAWLLk5GCvNO3Fa0Ygg8vQE56mJQ9XCY4zYbRyY", Source: RamdiskSSHKeySourceSecret},
+				{Fingerprint: "SHA256:q2a1bmKrWiP7/7c1D2v3uW5+vGMMiIwh8PBVm9fDTcg", Source: RamdiskSSHKeySourceDebug, Expires: ptr.To(metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))},
+			},
+		},
+	}
+}
+
+func TestRoundTripFromSpoke(t *testing.T) {
+	for name, src := range map[string]*Provisioning{
+		"full":  fullV1beta1Provisioning(),
+		"empty": {ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hub := &v1alpha1.Provisioning{}
+			require.NoError(t, src.ConvertTo(hub))
+
+			dst := &Provisioning{}
+			require.NoError(t, dst.ConvertFrom(hub))
+			assert.Equal(t, src, dst)
+		})
+	}
+}
+
+func TestRoundTripFromHub(t *testing.T) {
+	src := &v1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: "provisioning-configuration"},
+		Spec: v1alpha1.ProvisioningSpec{
+			ProvisioningInterface:     "eth0",
+			ProvisioningMacAddresses:  []string{"34:b3:2d:81:f8:fb"},
+			ProvisioningVLANID:        42,
+			ProvisioningIP:            "fd00::3",
+			ProvisioningNetworkCIDR:   "fd00::/64",
+			ProvisioningDHCPRange:     "fd00::10,fd00::ff",
+			ProvisioningOSDownloadURL: "http://172.22.0.1/images/rhcos.qcow2.gz",
+			ProvisioningOSChecksumURL: "http://172.22.0.1/images/SHA256SUMS",
+			ProvisioningNetwork:       v1alpha1.ProvisioningNetworkUnmanaged,
+			BootIsoSource:             v1alpha1.BootIsoSourceLocal,
+			PrometheusExporter:        &v1alpha1.PrometheusExporter{Enabled: true, SensorCollectionInterval: 60},
+		},
+		Status: v1alpha1.ProvisioningStatus{
+			ExternalURLs: []v1alpha1.ExternalURL{
+				{Consumer: v1alpha1.ExternalURLConsumerImageCustomization, URL: "https://[fd00::3]:6385"},
+			},
+			OSImage: &v1alpha1.OSImageStatus{
+				Format:      v1alpha1.OSImageFormatQCOW2,
+				Compression: v1alpha1.OSImageCompressionGzip,
+				CachedName:  "rhcos.qcow2",
+			},
+		},
+	}
+
+	spoke := &Provisioning{}
+	require.NoError(t, spoke.ConvertFrom(src))
+	assert.Equal(t, &VLANSpec{ID: 42}, spoke.Spec.Network.VLAN)
+	assert.Equal(t, "fd00::10", spoke.Spec.DHCP.RangeStart)
+	assert.Equal(t, "fd00::ff", spoke.Spec.DHCP.RangeEnd)
+
+	dst := &v1alpha1.Provisioning{}
+	require.NoError(t, spoke.ConvertTo(dst))
+	assert.Equal(t, src, dst)
+}
+
+func TestRoundTripFromHubDHCPExternal(t *testing.T) {
+	src := &v1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "provisioning-configuration",
+			Annotations: map[string]string{"example.com/other": "value"},
+		},
+		Spec: v1alpha1.ProvisioningSpec{
+			ProvisioningNetwork:      v1alpha1.ProvisioningNetworkManaged,
+			ProvisioningDHCPExternal: true,
+		},
+	}
+
+	spoke := &Provisioning{}
+	require.NoError(t, spoke.ConvertFrom(src))
+	assert.Equal(t, ProvisioningNetworkModeManaged, spoke.Spec.Network.Mode)
+	assert.Equal(t, "true", spoke.Annotations[dhcpExternalAnnotation])
+	assert.NotContains(t, src.Annotations, dhcpExternalAnnotation)
+
+	dst := &v1alpha1.Provisioning{}
+	require.NoError(t, spoke.ConvertTo(dst))
+	assert.Equal(t, src, dst)
+}
+
+func TestConvertFromHubNormalizesLegacyFields(t *testing.T) {
+	testCases := []struct {
+		name          string
+		spec          v1alpha1.ProvisioningSpec
+		expectedMode  ProvisioningNetworkMode
+		expectedStart string
+		expectedEnd   string
+		expectedRange string
+	}{
+		{
+			name:         "DHCPExternal",
+			spec:         v1alpha1.ProvisioningSpec{ProvisioningDHCPExternal: true},
+			expectedMode: ProvisioningNetworkModeUnmanaged,
+		},
+		{
+			name:         "DHCPExternal ignored with an explicit network mode",
+			spec:         v1alpha1.ProvisioningSpec{ProvisioningDHCPExternal: true, ProvisioningNetwork: v1alpha1.ProvisioningNetworkManaged},
+			expectedMode: ProvisioningNetworkModeManaged,
+		},
+		{
+			name:          "DHCP range with a space",
+			spec:          v1alpha1.ProvisioningSpec{ProvisioningDHCPRange: "172.30.20.11, 172.30.20.101"},
+			expectedStart: "172.30.20.11",
+			expectedEnd:   "172.30.20.101",
+			expectedRange: "172.30.20.11,172.30.20.101",
+		},
+		{
+			name:          "malformed DHCP range",
+			spec:          v1alpha1.ProvisioningSpec{ProvisioningDHCPRange: "172.30.20.11"},
+			expectedStart: "172.30.20.11",
+			expectedRange: "172.30.20.11",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spoke := &Provisioning{}
+			require.NoError(t, spoke.ConvertFrom(&v1alpha1.Provisioning{Spec: tc.spec}))
+			assert.Equal(t, tc.expectedMode, spoke.Spec.Network.Mode)
+			assert.Equal(t, tc.expectedStart, spoke.Spec.DHCP.RangeStart)
+			assert.Equal(t, tc.expectedEnd, spoke.Spec.DHCP.RangeEnd)
+
+			hub := &v1alpha1.Provisioning{}
+			require.NoError(t, spoke.ConvertTo(hub))
+			assert.Equal(t, tc.spec.ProvisioningDHCPExternal, hub.Spec.ProvisioningDHCPExternal)
+			assert.Equal(t, tc.expectedRange, hub.Spec.ProvisioningDHCPRange)
+		})
+	}
+}
+
+func TestIsConvertible(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	require.NoError(t, AddToScheme(scheme))
+
+	ok, err := conversion.IsConvertible(scheme, &v1alpha1.Provisioning{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
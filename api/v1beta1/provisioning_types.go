@@ -0,0 +1,760 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+// ProvisioningNetworkMode is the boot mode of the system
+// +kubebuilder:validation:Enum=Managed;Unmanaged;Disabled
+type ProvisioningNetworkMode string
+
+// ProvisioningNetworkMode values
+const (
+	ProvisioningNetworkModeManaged   ProvisioningNetworkMode = "Managed"
+	ProvisioningNetworkModeUnmanaged ProvisioningNetworkMode = "Unmanaged"
+	ProvisioningNetworkModeDisabled  ProvisioningNetworkMode = "Disabled"
+)
+
+// IronicAgent customizes the ironic-python-agent ramdisk booted on the
+// hosts
+type IronicAgentSpec struct {
+	// ExtraKernelParams are additional kernel parameters of the
+	// ironic-python-agent ramdisk, separated by spaces, used whenever it
+	// boots. They cannot set the parameters managed by the operator.
+	ExtraKernelParams string `json:"extraKernelParams,omitempty"`
+
+	// SSHKey authorizes SSH keys in the ramdisk in addition to those of the
+	// install-config. They are picked up when changed.
+	SSHKey *RamdiskSSHKey `json:"sshKey,omitempty"`
+
+	// DebugSSHKey authorizes an SSH key in the ramdisk in addition to the
+	// other keys for a limited time, to debug hosts. Its expiry only stops
+	// authorizing it in the ramdisks booted afterwards: ramdisks already
+	// running, and images already built for them, keep authorizing it until
+	// the hosts boot again.
+	DebugSSHKey *DebugSSHKey `json:"debugSSHKey,omitempty"`
+
+	// HardwareManagers are container images holding custom
+	// ironic-python-agent hardware managers, layered on the ramdisk by the
+	// image customization controller.
+	// +listType=map
+	// +listMapKey=name
+	HardwareManagers []HardwareManager `json:"hardwareManagers,omitempty"`
+}
+
+// RamdiskSSHKey is an SSH public key, or the Secret holding SSH public
+// keys, authorized in the ironic-python-agent ramdisk
+type RamdiskSSHKey struct {
+	// PublicKey is the SSH public key, in the authorized_keys format.
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// SecretName is the name of a Secret of the openshift-machine-api
+	// namespace holding SSH public keys under its authorized_keys key, one
+	// per line, instead of PublicKey.
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// RamdiskSSHKeySource is where an SSH key authorized in the
+// ironic-python-agent ramdisk comes from
+// +kubebuilder:validation:Enum=InstallConfig;Provisioning;Secret;Debug
+type RamdiskSSHKeySource string
+
+const (
+	// RamdiskSSHKeySourceInstallConfig is the SSH key of the
+	// install-config.
+	RamdiskSSHKeySourceInstallConfig RamdiskSSHKeySource = "InstallConfig"
+	// RamdiskSSHKeySourceProvisioning is the key of the Provisioning
+	// spec.
+	RamdiskSSHKeySourceProvisioning RamdiskSSHKeySource = "Provisioning"
+	// RamdiskSSHKeySourceSecret is the key of the referenced Secret.
+	RamdiskSSHKeySourceSecret RamdiskSSHKeySource = "Secret"
+	// RamdiskSSHKeySourceDebug is the debug SSH key.
+	RamdiskSSHKeySourceDebug RamdiskSSHKeySource = "Debug"
+)
+
+// RamdiskSSHKeyStatus is an SSH key authorized in the ironic-python-agent
+// ramdisk, or rejected
+type RamdiskSSHKeyStatus struct {
+	// Fingerprint is the SHA256 fingerprint of the key, as shown by
+	// ssh-keygen -l, unless it cannot be parsed.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Source is where the key comes from.
+	Source RamdiskSSHKeySource `json:"source"`
+
+	// Expires is the time after which the key is no longer authorized, for
+	// the debug SSH key.
+	// +optional
+	Expires *metav1.Time `json:"expires,omitempty"`
+
+	// Error is why the key is not authorized, empty when it is.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// DebugSSHKey is an SSH public key authorized in the ironic-python-agent
+// ramdisk until it expires
+type DebugSSHKey struct {
+	// PublicKey is the SSH public key, in the authorized_keys format.
+	PublicKey string `json:"publicKey"`
+
+	// Expires is the time after which the key is no longer authorized. It
+	// can be at most 7 days in the future.
+	Expires metav1.Time `json:"expires"`
+}
+
+// HardwareManager is a container image holding a custom
+// ironic-python-agent hardware manager
+type HardwareManager struct {
+	// Name identifies the hardware manager.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Image is the container image holding the hardware manager, pulled
+	// with the cluster pull secret.
+	Image string `json:"image"`
+}
+
+// InspectionCollector is an ironic-python-agent collector gathering
+// inspection data
+// +kubebuilder:validation:Enum=extra-hardware;numa-topology;dmi-decode;pci-devices;usb-devices;logs
+type InspectionCollector string
+
+// InspectionHook is an Ironic hook processing inspection data
+// +kubebuilder:validation:Enum=extra-hardware;pci-devices;accelerators;cpu-capabilities;memory;boot-mode;raid-device;physical-network
+type InspectionHook string
+
+// InspectionSpec configures the data gathered when inspecting hosts
+type InspectionSpec struct {
+	// Collectors are the ironic-python-agent collectors run in addition
+	// to the default one.
+	// +listType=set
+	Collectors []InspectionCollector `json:"collectors,omitempty"`
+
+	// CollectLLDP gathers the LLDP data received on the host interfaces,
+	// and processes it to find the switch ports they are connected to.
+	CollectLLDP bool `json:"collectLLDP,omitempty"`
+
+	// Hooks are the Ironic hooks processing the inspection data, run in
+	// addition to the default ones.
+	// +listType=set
+	Hooks []InspectionHook `json:"hooks,omitempty"`
+
+	// ExtraKernelParams are additional kernel parameters of the
+	// ironic-python-agent ramdisk, separated by spaces, only used when it
+	// boots to inspect a host. They cannot set the parameters managed by
+	// the operator.
+	ExtraKernelParams string `json:"extraKernelParams,omitempty"`
+}
+
+// CleaningMode selects how hosts are cleaned automatically before being
+// provisioned and after being deprovisioned
+// +kubebuilder:validation:Enum=Metadata;Full
+type CleaningMode string
+
+// CleaningMode values
+const (
+	// CleaningModeMetadata erases the partition tables and filesystem
+	// signatures of the disks.
+	CleaningModeMetadata CleaningMode = "Metadata"
+	// CleaningModeFull erases the whole content of the disks.
+	CleaningModeFull CleaningMode = "Full"
+)
+
+// EraseMethod is a method used to erase the whole content of a disk
+// +kubebuilder:validation:Enum=ATASecureErase;NVMeSecureErase;Overwrite
+type EraseMethod string
+
+// EraseMethod values
+const (
+	EraseMethodATASecureErase  EraseMethod = "ATASecureErase"
+	EraseMethodNVMeSecureErase EraseMethod = "NVMeSecureErase"
+	// EraseMethodOverwrite overwrites the disks with random data, then
+	// zeros.
+	EraseMethodOverwrite EraseMethod = "Overwrite"
+)
+
+// CleaningPolicySpec configures the automated cleaning of the hosts
+type CleaningPolicySpec struct {
+	// Mode is how the hosts are cleaned. Defaults to Metadata. Whether a
+	// host is cleaned at all is still selected by its own
+	// automatedCleaningMode.
+	Mode CleaningMode `json:"mode,omitempty"`
+
+	// EraseMethods are the methods a Full cleaning may use. Secure erase is
+	// tried first on the disks supporting it, then the disks are
+	// overwritten if allowed. All methods are allowed when empty.
+	// +listType=set
+	EraseMethods []EraseMethod `json:"eraseMethods,omitempty"`
+}
+
+// IronicTuningSpec tunes the Ironic conductor. Each setting left unset keeps
+// the Ironic default.
+type IronicTuningSpec struct {
+	// DeployCallbackTimeout is the time (in seconds) Ironic waits for the
+	// agent on a host being deployed to call back before failing the
+	// deployment.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	DeployCallbackTimeout int `json:"deployCallbackTimeout,omitempty"`
+
+	// CleanCallbackTimeout is the time (in seconds) Ironic waits for the
+	// agent on a host being cleaned to call back before failing the
+	// cleaning.
+	// +kubebuilder:validation:Minimum=60
+	// +kubebuilder:validation:Maximum=86400
+	CleanCallbackTimeout int `json:"cleanCallbackTimeout,omitempty"`
+
+	// PowerStateSyncInterval is how often (in seconds) Ironic checks the
+	// power state of the hosts against their BMC.
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=86400
+	PowerStateSyncInterval int `json:"powerStateSyncInterval,omitempty"`
+
+	// PowerStateChangeTimeout is the time (in seconds) Ironic waits for a
+	// host to reach the requested power state.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=600
+	PowerStateChangeTimeout int `json:"powerStateChangeTimeout,omitempty"`
+
+	// MaxConcurrentDeploys is the number of hosts Ironic deploys at the
+	// same time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	MaxConcurrentDeploys int `json:"maxConcurrentDeploys,omitempty"`
+
+	// MaxConcurrentCleans is the number of hosts Ironic cleans at the same
+	// time.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	MaxConcurrentCleans int `json:"maxConcurrentCleans,omitempty"`
+}
+
+// PersistentBootDevice selects whether hosts booted from a live ISO keep
+// it as their persistent boot device
+// +kubebuilder:validation:Enum=Default;Always;Never
+type PersistentBootDevice string
+
+// PersistentBootDevice values
+const (
+	PersistentBootDeviceDefault PersistentBootDevice = "Default"
+	PersistentBootDeviceAlways  PersistentBootDevice = "Always"
+	PersistentBootDeviceNever   PersistentBootDevice = "Never"
+)
+
+// DetachedDeleteAction selects what happens to a detached host when it is
+// deleted
+// +kubebuilder:validation:Enum=delay;delete
+type DetachedDeleteAction string
+
+// DetachedDeleteAction values
+const (
+	// DetachedDeleteActionDelay delays the deletion until the host is no
+	// longer detached.
+	DetachedDeleteActionDelay DetachedDeleteAction = "delay"
+	// DetachedDeleteActionDelete deletes the host right away.
+	DetachedDeleteActionDelete DetachedDeleteAction = "delete"
+)
+
+// BaremetalOperatorSpec configures the baremetal-operator
+type BaremetalOperatorSpec struct {
+	// MaxConcurrentReconciles is the number of hosts reconciled at the
+	// same time. The baremetal-operator picks it from the number of CPUs
+	// when it is not set.
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// ProvisioningLimit is the number of hosts that may be provisioned,
+	// inspected or deprovisioned at the same time. The other hosts wait
+	// for their turn. Defaults to 20.
+	// +kubebuilder:validation:Minimum=1
+	ProvisioningLimit int `json:"provisioningLimit,omitempty"`
+
+	// LiveISOPersistentBootDevice selects whether the hosts booted from a
+	// live ISO keep it as their persistent boot device. Defaults to Never.
+	LiveISOPersistentBootDevice PersistentBootDevice `json:"liveISOPersistentBootDevice,omitempty"`
+
+	// DetachedDeleteAction is what happens to detached hosts when they are
+	// deleted. It is set as the deleteAction of the detached annotation of
+	// the hosts that are detached without one, when they are written. The
+	// baremetal-operator delays the deletion when it is not set.
+	DetachedDeleteAction DetachedDeleteAction `json:"detachedDeleteAction,omitempty"`
+
+	// LogLevel is the verbosity of the baremetal-operator logs. Defaults
+	// to Normal.
+	LogLevel operatorv1.LogLevel `json:"logLevel,omitempty"`
+}
+
+// IronicProxyMode selects whether the Ironic API is served through
+// ironic-proxy
+// +kubebuilder:validation:Enum="";Auto;Enabled;Disabled
+type IronicProxyMode string
+
+// IronicProxyMode values
+const (
+	// IronicProxyAuto uses ironic-proxy when the provisioning network is
+	// Disabled or virtual media goes over the external network.
+	IronicProxyAuto     IronicProxyMode = "Auto"
+	IronicProxyEnabled  IronicProxyMode = "Enabled"
+	IronicProxyDisabled IronicProxyMode = "Disabled"
+)
+
+// BootIsoSource is the origin of the boot iso image
+// +kubebuilder:validation:Enum=local;http
+type BootIsoSource string
+
+// BootIsoSource values
+const (
+	BootIsoSourceLocal BootIsoSource = "local"
+	BootIsoSourceHttp  BootIsoSource = "http"
+)
+
+// VLANSpec describes the VLAN carrying the provisioning network
+type VLANSpec struct {
+	// ID is the ID of the VLAN.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	ID int32 `json:"id"`
+
+	// ParentInterface is the name of the interface, for example a bond, on
+	// which the VLAN is created. When not set, the interface matching
+	// MACAddresses is used.
+	ParentInterface string `json:"parentInterface,omitempty"`
+}
+
+// NetworkSpec describes the provisioning network and how the provisioning
+// services are reached
+type NetworkSpec struct {
+	// Mode is the provisioning network mode. Managed when not set.
+	Mode ProvisioningNetworkMode `json:"mode,omitempty"`
+
+	// Interface is the name of the network interface on a control plane
+	// node connected to the provisioning network, or the name of the VLAN
+	// subinterface when VLAN is set.
+	Interface string `json:"interface,omitempty"`
+
+	// MACAddresses are the MAC addresses of the provisioning interfaces of
+	// the control plane nodes, used instead of Interface to allow
+	// interfaces of different names.
+	MACAddresses []string `json:"macAddresses,omitempty"`
+
+	// VLAN, if set, puts the provisioning network on a VLAN.
+	VLAN *VLANSpec `json:"vlan,omitempty"`
+
+	// IP is the IP address assigned to the provisioning interface of the
+	// control plane node running the metal3 pod.
+	IP string `json:"ip,omitempty"`
+
+	// CIDR is the provisioning network CIDR.
+	CIDR string `json:"cidr,omitempty"`
+
+	// Gateway is the gateway advertised to hosts on the provisioning
+	// network. Only supported in Managed mode.
+	Gateway string `json:"gateway,omitempty"`
+
+	// AdditionalNTPServers is a list of NTP servers to be used by the
+	// provisioning service.
+	AdditionalNTPServers []string `json:"additionalNTPServers,omitempty"`
+
+	// ExternalIPs are the external-facing IP addresses used to access the
+	// Ironic service, at most one per IP family.
+	ExternalIPs []string `json:"externalIPs,omitempty"`
+
+	// VirtualMediaViaExternalNetwork serves virtual media over the external
+	// network even when a provisioning network is configured.
+	VirtualMediaViaExternalNetwork bool `json:"virtualMediaViaExternalNetwork,omitempty"`
+
+	// IronicProxy selects whether the Ironic API is served by ironic-proxy
+	// on every control plane node. Auto, the default, uses it when Mode is
+	// Disabled or VirtualMediaViaExternalNetwork is set.
+	IronicProxy IronicProxyMode `json:"ironicProxy,omitempty"`
+}
+
+// DHCPSpec configures the DHCP server running on the provisioning network
+// in Managed mode
+type DHCPSpec struct {
+	// RangeStart is the first address handed out by the DHCP server.
+	RangeStart string `json:"rangeStart,omitempty"`
+
+	// RangeEnd is the last address handed out by the DHCP server.
+	RangeEnd string `json:"rangeEnd,omitempty"`
+
+	// ProvideDNS sends the DNS information via DHCP.
+	ProvideDNS bool `json:"provideDNS,omitempty"`
+}
+
+// LiveImagesSpec is a set of URLs of CoreOS Live images
+type LiveImagesSpec struct {
+	// IsoURL is the image URL used for Live ISO deployments.
+	IsoURL string `json:"isoURL,omitempty"`
+
+	// KernelURL is the kernel URL used for PXE deployments.
+	KernelURL string `json:"kernelURL,omitempty"`
+
+	// InitramfsURL is the initramfs URL used for PXE deployments.
+	InitramfsURL string `json:"initramfsURL,omitempty"`
+
+	// RootfsURL is the rootfs URL used for PXE deployments.
+	RootfsURL string `json:"rootfsURL,omitempty"`
+}
+
+// ImagesSpec describes the images used to provision hosts
+type ImagesSpec struct {
+	// OSDownloadURL is the location from which the OS image used to boot
+	// baremetal host machines can be downloaded by the metal3 cluster.
+	OSDownloadURL string `json:"osDownloadURL,omitempty"`
+
+	// OSChecksumURL is the location of a checksum file, such as SHA256SUMS
+	// or SHA512SUMS, listing the checksum of the image at OSDownloadURL as
+	// it is downloaded, compressed or not.
+	OSChecksumURL string `json:"osChecksumURL,omitempty"`
+
+	// BootIsoSource provides a way to set the location where the iso image
+	// to boot the nodes will be served from.
+	BootIsoSource BootIsoSource `json:"bootIsoSource,omitempty"`
+
+	// PreProvisioning are the CoreOS Live images needed to provision a
+	// worker either using virtual media or PXE.
+	PreProvisioning LiveImagesSpec `json:"preProvisioning,omitempty"`
+}
+
+// TLSSpec configures TLS for the provisioning services
+type TLSSpec struct {
+	// DisableVirtualMedia turns off TLS for virtual media deployments.
+	DisableVirtualMedia bool `json:"disableVirtualMedia,omitempty"`
+}
+
+// MonitoringSpec configures sensor data collection and Prometheus metrics
+// export
+type MonitoringSpec struct {
+	// Enabled controls whether sensor data collection is active.
+	Enabled bool `json:"enabled"`
+
+	// SensorCollectionInterval defines how often (in seconds) sensor data
+	// is collected from BMCs using Ironic. Must be at least 60 seconds.
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=60
+	SensorCollectionInterval int `json:"sensorCollectionInterval,omitempty"`
+
+	// DisableDefaultPrometheusRules controls whether default hardware health
+	// alerting rules should NOT be deployed alongside the prometheus exporter.
+	DisableDefaultPrometheusRules bool `json:"disableDefaultPrometheusRules,omitempty"`
+}
+
+// ImageCacheSpec defines how the image cache manages the OS images stored
+// on the control plane nodes
+type ImageCacheSpec struct {
+	// MaxSize is the disk space the cached OS images may use on each node.
+	// The image currently configured is never removed.
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+
+	// KeepVersions is the number of OS images kept on each node, including
+	// the one currently configured.
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=1
+	KeepVersions int `json:"keepVersions,omitempty"`
+
+	// MinFreeSpacePercent is the free space, as a percentage of the
+	// filesystem holding the cache, below which the operator reports
+	// itself Degraded.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MinFreeSpacePercent int `json:"minFreeSpacePercent,omitempty"`
+
+	// NodeSelector selects the nodes running the image cache instead of
+	// the control plane nodes, for example a set of worker or infra nodes
+	// spread over the zones of the site.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the image cache pods, so that they can run
+	// on tainted nodes selected by NodeSelector.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// TopologyAwareURLs makes the operator rewrite the image URLs of the
+	// BareMetalHosts labelled with topology.kubernetes.io/zone that point
+	// to the image cache, so that they download their image from a cache
+	// running in the same zone. Only hosts that are not provisioned yet
+	// are changed, when they are written and when the image cache
+	// endpoints change. The image set on a host is kept in its
+	// baremetal.openshift.io/image-cache-source annotation, and restored
+	// when there is no cache in its zone anymore.
+	TopologyAwareURLs bool `json:"topologyAwareURLs,omitempty"`
+
+	// AdditionalImages are OS images cached in addition to the one from
+	// Images.OSDownloadURL. The metal3 pod downloads them and the image
+	// cache pre-fetches them from it.
+	AdditionalImages []AdditionalImage `json:"additionalImages,omitempty"`
+}
+
+// AdditionalImage is an OS image pre-fetched by the image cache
+type AdditionalImage struct {
+	// URL is the location of the image. It must end in .qcow2 or .raw,
+	// optionally followed by .gz.
+	URL string `json:"url"`
+
+	// Checksum is the sha256 or sha512 checksum of the uncompressed image,
+	// hex encoded.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// UnsupportedConfigOverrides define possible overrides that are not officially
+// supported and may break the deployment
+type UnsupportedConfigOverrides struct {
+	// Override for the IPA container image.
+	IronicAgentImage string `json:"ironicAgentImage,omitempty"`
+}
+
+// ProvisioningSpec defines the desired state of Provisioning
+type ProvisioningSpec struct {
+	// Network describes the provisioning network.
+	Network NetworkSpec `json:"network,omitempty"`
+
+	// DHCP configures the DHCP server of a Managed provisioning network.
+	DHCP DHCPSpec `json:"dhcp,omitempty"`
+
+	// Images describes the images used to provision hosts.
+	Images ImagesSpec `json:"images,omitempty"`
+
+	// TLS configures TLS for the provisioning services.
+	TLS TLSSpec `json:"tls,omitempty"`
+
+	// Monitoring configures sensor data collection and Prometheus metrics
+	// export.
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+
+	// ImageCache configures how the image cache manages the OS images it
+	// stores on the control plane nodes.
+	ImageCache *ImageCacheSpec `json:"imageCache,omitempty"`
+
+	// BaremetalOperator configures the baremetal-operator.
+	BaremetalOperator *BaremetalOperatorSpec `json:"baremetalOperator,omitempty"`
+
+	// IronicTuning tunes the Ironic conductor.
+	IronicTuning *IronicTuningSpec `json:"ironicTuning,omitempty"`
+
+	// CleaningPolicy configures the automated cleaning of the hosts.
+	CleaningPolicy *CleaningPolicySpec `json:"cleaningPolicy,omitempty"`
+
+	// Inspection configures the data gathered when inspecting hosts.
+	Inspection *InspectionSpec `json:"inspection,omitempty"`
+
+	// IronicAgent customizes the ironic-python-agent ramdisk.
+	IronicAgent *IronicAgentSpec `json:"ironicAgent,omitempty"`
+
+	// WatchAllNamespaces provides a way to explicitly allow use of this
+	// Provisioning configuration across all Namespaces.
+	WatchAllNamespaces bool `json:"watchAllNamespaces,omitempty"`
+
+	// UnsupportedConfigOverrides are overrides that are not officially
+	// supported and may break the deployment.
+	UnsupportedConfigOverrides *UnsupportedConfigOverrides `json:"unsupportedConfigOverrides,omitempty"`
+}
+
+// ProvisioningInterfaceState is the outcome of looking for a network
+// interface usable for provisioning on a control plane node
+// +kubebuilder:validation:Enum=Found;Missing;Unknown
+type ProvisioningInterfaceState string
+
+// ProvisioningInterfaceStatus reports whether a control plane node has a
+// network interface that can be used for the provisioning network
+type ProvisioningInterfaceStatus struct {
+	// NodeName is the name of the control plane node.
+	NodeName string `json:"nodeName"`
+
+	// State is the result of the check for this node.
+	State ProvisioningInterfaceState `json:"state"`
+
+	// Interface is the name of the matching network interface, if any.
+	Interface string `json:"interface,omitempty"`
+
+	// MACAddress is the MAC address of the matching network interface,
+	// if any.
+	MACAddress string `json:"macAddress,omitempty"`
+
+	// Message is a human readable explanation of the state.
+	Message string `json:"message,omitempty"`
+}
+
+// ExternalURLConsumer identifies a component receiving an external URL
+// +kubebuilder:validation:Enum=ImageCustomization;VirtualMedia;VirtualMediaIPv6
+type ExternalURLConsumer string
+
+// ExternalURL is an external URL of the metal3 services together with the
+// component it is given to
+type ExternalURL struct {
+	// Consumer is the component receiving the URL.
+	Consumer ExternalURLConsumer `json:"consumer"`
+
+	// URL is the URL given to the consumer. Several URLs are separated by
+	// commas.
+	URL string `json:"url"`
+}
+
+// OSImageFormat is the disk format of the OS image
+// +kubebuilder:validation:Enum=qcow2;raw
+type OSImageFormat string
+
+// OSImageCompression is the compression applied to the OS image
+// +kubebuilder:validation:Enum=none;gzip;xz
+type OSImageCompression string
+
+// OSImageStatus describes the OS image downloaded from
+// Images.OSDownloadURL
+type OSImageStatus struct {
+	// Format is the disk format of the image.
+	Format OSImageFormat `json:"format"`
+
+	// Compression is the compression of the downloaded file.
+	Compression OSImageCompression `json:"compression"`
+
+	// CachedName is the name under which the image cache serves the image.
+	CachedName string `json:"cachedName"`
+}
+
+// ImageCacheNodeStatus reports the disk usage of the image cache on a node
+type ImageCacheNodeStatus struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName"`
+
+	// CachedImages is the number of OS images stored on the node.
+	CachedImages int `json:"cachedImages,omitempty"`
+
+	// UsedBytes is the disk space used by the cached OS images.
+	UsedBytes int64 `json:"usedBytes,omitempty"`
+
+	// CapacityBytes is the size of the filesystem holding the cache.
+	CapacityBytes int64 `json:"capacityBytes,omitempty"`
+
+	// AvailableBytes is the free space left on the filesystem holding the
+	// cache.
+	AvailableBytes int64 `json:"availableBytes,omitempty"`
+
+	// LowDiskSpace is set when the free space is below
+	// ImageCache.MinFreeSpacePercent.
+	LowDiskSpace bool `json:"lowDiskSpace,omitempty"`
+
+	// Message explains why the disk usage of the node is unknown.
+	Message string `json:"message,omitempty"`
+
+	// Content reports the state of the image from Images.OSDownloadURL
+	// on the node. The image cache of the node is only ready to serve
+	// images once it is Verified, or Ready when the image has no checksum
+	// to verify it against.
+	Content *CachedImageStatus `json:"content,omitempty"`
+
+	// Images reports the state of ImageCache.AdditionalImages on the node.
+	Images []CachedImageStatus `json:"images,omitempty"`
+}
+
+// CachedImageState is the state of an image in the image cache of a node
+// +kubebuilder:validation:Enum=Missing;Pending;Downloading;Ready;Verified;ChecksumMismatch;Failed
+type CachedImageState string
+
+// CachedImageStatus reports the state of an image in the image cache of a
+// node
+type CachedImageStatus struct {
+	// Name is the name under which the image is served.
+	Name string `json:"name"`
+
+	// State is the state of the image on the node.
+	State CachedImageState `json:"state"`
+
+	// DownloadedBytes is the amount of data downloaded so far.
+	DownloadedBytes int64 `json:"downloadedBytes,omitempty"`
+
+	// TotalBytes is the size of the image, when known.
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+}
+
+// ImageCacheEndpoint lists the image cache endpoints of a zone
+type ImageCacheEndpoint struct {
+	// Zone is the value of the topology.kubernetes.io/zone label of the
+	// nodes, empty for nodes without the label.
+	Zone string `json:"zone,omitempty"`
+
+	// URLs are the base URLs of the image cache on the nodes of the zone.
+	URLs []string `json:"urls"`
+}
+
+// ProvisioningStatus defines the observed state of Provisioning
+type ProvisioningStatus struct {
+	operatorv1.OperatorStatus `json:",inline"`
+
+	// ProvisioningInterfaces reports, for each control plane node, whether
+	// a network interface usable for the provisioning network was found.
+	ProvisioningInterfaces []ProvisioningInterfaceStatus `json:"provisioningInterfaces,omitempty"`
+
+	// ExternalURLs lists the URLs handed to each consumer of the metal3
+	// services.
+	ExternalURLs []ExternalURL `json:"externalURLs,omitempty"`
+
+	// OSImage describes the image downloaded from Images.OSDownloadURL.
+	OSImage *OSImageStatus `json:"osImage,omitempty"`
+
+	// ImageCache reports the disk usage of the image cache on each node
+	// it runs on.
+	ImageCache []ImageCacheNodeStatus `json:"imageCache,omitempty"`
+
+	// ImageCacheEndpoints lists, for each zone, the image cache endpoints
+	// ready to serve images.
+	ImageCacheEndpoints []ImageCacheEndpoint `json:"imageCacheEndpoints,omitempty"`
+
+	// IronicProxy is the ironic-proxy mode in effect, Enabled or Disabled.
+	IronicProxy IronicProxyMode `json:"ironicProxy,omitempty"`
+
+	// RamdiskSSHKeys lists the SSH keys authorized in the
+	// ironic-python-agent ramdisk, and the keys rejected.
+	RamdiskSSHKeys []RamdiskSSHKeyStatus `json:"ramdiskSSHKeys,omitempty"`
+}
+
+// +kubebuilder:resource:path=provisionings,scope=Cluster
+// +kubebuilder:subresource:status
+
+// Provisioning contains configuration used by the Provisioning
+// service (Ironic) to provision baremetal hosts.
+// This CR is a singleton, created by the installer and consumed by the
+// cluster-baremetal-operator to bring up and update containers in a metal3
+// cluster.
+// +kubebuilder:object:root=true
+type Provisioning struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProvisioningSpec   `json:"spec,omitempty"`
+	Status ProvisioningStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProvisioningList contains a list of Provisioning
+type ProvisioningList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Provisioning `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Provisioning{}, &ProvisioningList{})
+}
@@ -0,0 +1,665 @@
+//go:build !ignore_autogenerated
+
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/api/core/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalImage) DeepCopyInto(out *AdditionalImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalImage.
+func (in *AdditionalImage) DeepCopy() *AdditionalImage {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BaremetalOperatorSpec) DeepCopyInto(out *BaremetalOperatorSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BaremetalOperatorSpec.
+func (in *BaremetalOperatorSpec) DeepCopy() *BaremetalOperatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BaremetalOperatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CachedImageStatus) DeepCopyInto(out *CachedImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CachedImageStatus.
+func (in *CachedImageStatus) DeepCopy() *CachedImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CachedImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CleaningPolicySpec) DeepCopyInto(out *CleaningPolicySpec) {
+	*out = *in
+	if in.EraseMethods != nil {
+		in, out := &in.EraseMethods, &out.EraseMethods
+		*out = make([]EraseMethod, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CleaningPolicySpec.
+func (in *CleaningPolicySpec) DeepCopy() *CleaningPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CleaningPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DHCPSpec) DeepCopyInto(out *DHCPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DHCPSpec.
+func (in *DHCPSpec) DeepCopy() *DHCPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DHCPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugSSHKey) DeepCopyInto(out *DebugSSHKey) {
+	*out = *in
+	in.Expires.DeepCopyInto(&out.Expires)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugSSHKey.
+func (in *DebugSSHKey) DeepCopy() *DebugSSHKey {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugSSHKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalURL) DeepCopyInto(out *ExternalURL) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalURL.
+func (in *ExternalURL) DeepCopy() *ExternalURL {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalURL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareManager) DeepCopyInto(out *HardwareManager) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareManager.
+func (in *HardwareManager) DeepCopy() *HardwareManager {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCacheEndpoint) DeepCopyInto(out *ImageCacheEndpoint) {
+	*out = *in
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCacheEndpoint.
+func (in *ImageCacheEndpoint) DeepCopy() *ImageCacheEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCacheEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCacheNodeStatus) DeepCopyInto(out *ImageCacheNodeStatus) {
+	*out = *in
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = new(CachedImageStatus)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]CachedImageStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCacheNodeStatus.
+func (in *ImageCacheNodeStatus) DeepCopy() *ImageCacheNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCacheNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageCacheSpec) DeepCopyInto(out *ImageCacheSpec) {
+	*out = *in
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AdditionalImages != nil {
+		in, out := &in.AdditionalImages, &out.AdditionalImages
+		*out = make([]AdditionalImage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageCacheSpec.
+func (in *ImageCacheSpec) DeepCopy() *ImageCacheSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageCacheSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagesSpec) DeepCopyInto(out *ImagesSpec) {
+	*out = *in
+	out.PreProvisioning = in.PreProvisioning
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagesSpec.
+func (in *ImagesSpec) DeepCopy() *ImagesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InspectionSpec) DeepCopyInto(out *InspectionSpec) {
+	*out = *in
+	if in.Collectors != nil {
+		in, out := &in.Collectors, &out.Collectors
+		*out = make([]InspectionCollector, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]InspectionHook, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InspectionSpec.
+func (in *InspectionSpec) DeepCopy() *InspectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InspectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IronicAgentSpec) DeepCopyInto(out *IronicAgentSpec) {
+	*out = *in
+	if in.SSHKey != nil {
+		in, out := &in.SSHKey, &out.SSHKey
+		*out = new(RamdiskSSHKey)
+		**out = **in
+	}
+	if in.DebugSSHKey != nil {
+		in, out := &in.DebugSSHKey, &out.DebugSSHKey
+		*out = new(DebugSSHKey)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HardwareManagers != nil {
+		in, out := &in.HardwareManagers, &out.HardwareManagers
+		*out = make([]HardwareManager, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IronicAgentSpec.
+func (in *IronicAgentSpec) DeepCopy() *IronicAgentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IronicAgentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IronicTuningSpec) DeepCopyInto(out *IronicTuningSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IronicTuningSpec.
+func (in *IronicTuningSpec) DeepCopy() *IronicTuningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IronicTuningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveImagesSpec) DeepCopyInto(out *LiveImagesSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveImagesSpec.
+func (in *LiveImagesSpec) DeepCopy() *LiveImagesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveImagesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
+	*out = *in
+	if in.MACAddresses != nil {
+		in, out := &in.MACAddresses, &out.MACAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VLAN != nil {
+		in, out := &in.VLAN, &out.VLAN
+		*out = new(VLANSpec)
+		**out = **in
+	}
+	if in.AdditionalNTPServers != nil {
+		in, out := &in.AdditionalNTPServers, &out.AdditionalNTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalIPs != nil {
+		in, out := &in.ExternalIPs, &out.ExternalIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
+func (in *NetworkSpec) DeepCopy() *NetworkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OSImageStatus) DeepCopyInto(out *OSImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OSImageStatus.
+func (in *OSImageStatus) DeepCopy() *OSImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OSImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Provisioning) DeepCopyInto(out *Provisioning) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Provisioning.
+func (in *Provisioning) DeepCopy() *Provisioning {
+	if in == nil {
+		return nil
+	}
+	out := new(Provisioning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Provisioning) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningInterfaceStatus) DeepCopyInto(out *ProvisioningInterfaceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningInterfaceStatus.
+func (in *ProvisioningInterfaceStatus) DeepCopy() *ProvisioningInterfaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningInterfaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningList) DeepCopyInto(out *ProvisioningList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Provisioning, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningList.
+func (in *ProvisioningList) DeepCopy() *ProvisioningList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProvisioningList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningSpec) DeepCopyInto(out *ProvisioningSpec) {
+	*out = *in
+	in.Network.DeepCopyInto(&out.Network)
+	out.DHCP = in.DHCP
+	out.Images = in.Images
+	out.TLS = in.TLS
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		**out = **in
+	}
+	if in.ImageCache != nil {
+		in, out := &in.ImageCache, &out.ImageCache
+		*out = new(ImageCacheSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BaremetalOperator != nil {
+		in, out := &in.BaremetalOperator, &out.BaremetalOperator
+		*out = new(BaremetalOperatorSpec)
+		**out = **in
+	}
+	if in.IronicTuning != nil {
+		in, out := &in.IronicTuning, &out.IronicTuning
+		*out = new(IronicTuningSpec)
+		**out = **in
+	}
+	if in.CleaningPolicy != nil {
+		in, out := &in.CleaningPolicy, &out.CleaningPolicy
+		*out = new(CleaningPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Inspection != nil {
+		in, out := &in.Inspection, &out.Inspection
+		*out = new(InspectionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IronicAgent != nil {
+		in, out := &in.IronicAgent, &out.IronicAgent
+		*out = new(IronicAgentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UnsupportedConfigOverrides != nil {
+		in, out := &in.UnsupportedConfigOverrides, &out.UnsupportedConfigOverrides
+		*out = new(UnsupportedConfigOverrides)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningSpec.
+func (in *ProvisioningSpec) DeepCopy() *ProvisioningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningStatus) DeepCopyInto(out *ProvisioningStatus) {
+	*out = *in
+	in.OperatorStatus.DeepCopyInto(&out.OperatorStatus)
+	if in.ProvisioningInterfaces != nil {
+		in, out := &in.ProvisioningInterfaces, &out.ProvisioningInterfaces
+		*out = make([]ProvisioningInterfaceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalURLs != nil {
+		in, out := &in.ExternalURLs, &out.ExternalURLs
+		*out = make([]ExternalURL, len(*in))
+		copy(*out, *in)
+	}
+	if in.OSImage != nil {
+		in, out := &in.OSImage, &out.OSImage
+		*out = new(OSImageStatus)
+		**out = **in
+	}
+	if in.ImageCache != nil {
+		in, out := &in.ImageCache, &out.ImageCache
+		*out = make([]ImageCacheNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageCacheEndpoints != nil {
+		in, out := &in.ImageCacheEndpoints, &out.ImageCacheEndpoints
+		*out = make([]ImageCacheEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RamdiskSSHKeys != nil {
+		in, out := &in.RamdiskSSHKeys, &out.RamdiskSSHKeys
+		*out = make([]RamdiskSSHKeyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningStatus.
+func (in *ProvisioningStatus) DeepCopy() *ProvisioningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RamdiskSSHKey) DeepCopyInto(out *RamdiskSSHKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RamdiskSSHKey.
+func (in *RamdiskSSHKey) DeepCopy() *RamdiskSSHKey {
+	if in == nil {
+		return nil
+	}
+	out := new(RamdiskSSHKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RamdiskSSHKeyStatus) DeepCopyInto(out *RamdiskSSHKeyStatus) {
+	*out = *in
+	if in.Expires != nil {
+		in, out := &in.Expires, &out.Expires
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RamdiskSSHKeyStatus.
+func (in *RamdiskSSHKeyStatus) DeepCopy() *RamdiskSSHKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RamdiskSSHKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSSpec) DeepCopyInto(out *TLSSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSSpec.
+func (in *TLSSpec) DeepCopy() *TLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnsupportedConfigOverrides) DeepCopyInto(out *UnsupportedConfigOverrides) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnsupportedConfigOverrides.
+func (in *UnsupportedConfigOverrides) DeepCopy() *UnsupportedConfigOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(UnsupportedConfigOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLANSpec) DeepCopyInto(out *VLANSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLANSpec.
+func (in *VLANSpec) DeepCopy() *VLANSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VLANSpec)
+	in.DeepCopyInto(out)
+	return out
+}
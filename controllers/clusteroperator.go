@@ -56,6 +56,14 @@ const (
 	// ReasonProvisioningCRNotFound indicates that the provsioning CR is not found
 	ReasonProvisioningCRNotFound StatusReason = "WaitingForProvisioningCR"
 
+	// ReasonImageCacheLowDiskSpace indicates that the disk holding the image
+	// cache is running out of space on some nodes
+	ReasonImageCacheLowDiskSpace StatusReason = "ImageCacheLowDiskSpace"
+
+	// ReasonImageCacheContentInvalid indicates that the image cache cannot
+	// serve the OS image on some nodes
+	ReasonImageCacheContentInvalid StatusReason = "ImageCacheContentInvalid"
+
 	// ReasonUnsupported is an unsupported StatusReason
 	ReasonUnsupported StatusReason = "UnsupportedPlatform"
 )
@@ -304,10 +312,15 @@ func (r *ProvisioningReconciler) updateCOStatus(newReason StatusReason, msg, pro
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionFalse, string(newReason), ""), clk)
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionFalse, string(ReasonEmpty), ""), clk)
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionTrue, string(newReason), progressMsg), clk)
-	case ReasonInvalidConfiguration, ReasonDeployTimedOut:
+	case ReasonInvalidConfiguration, ReasonDeployTimedOut, ReasonImageCacheContentInvalid:
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(newReason), msg), clk)
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonEmpty), ""), clk)
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionTrue, string(newReason), progressMsg), clk)
+	case ReasonImageCacheLowDiskSpace:
+		// The operands are running, progressMsg says which ones.
+		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(newReason), msg), clk)
+		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonComplete), progressMsg), clk)
+		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(newReason), ""), clk)
 	case ReasonDeploymentCrashLooping:
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(newReason), msg), clk)
 		v1helpers.SetStatusCondition(&conds, setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionFalse, string(newReason), msg), clk)
@@ -61,6 +61,32 @@ func TestUpdateCOStatus(t *testing.T) {
 				setStatusCondition(OperatorDisabled, osconfigv1.ConditionFalse, "", ""),
 			},
 		},
+		{
+			name:        "ImageCacheLowDiskSpace",
+			reason:      ReasonImageCacheLowDiskSpace,
+			msg:         "low disk space for the image cache on master-0",
+			progressMsg: "metal3 pod and image cache running",
+			expectedConditions: []osconfigv1.ClusterOperatorStatusCondition{
+				setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(ReasonImageCacheLowDiskSpace), "low disk space for the image cache on master-0"),
+				setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionFalse, string(ReasonImageCacheLowDiskSpace), ""),
+				setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, string(ReasonComplete), "metal3 pod and image cache running"),
+				setStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, "", ""),
+				setStatusCondition(OperatorDisabled, osconfigv1.ConditionFalse, "", ""),
+			},
+		},
+		{
+			name:        "ImageCacheContentInvalid",
+			reason:      ReasonImageCacheContentInvalid,
+			msg:         "metal3 image cache is not ready, the OS image cannot be served on master-1 (ChecksumMismatch)",
+			progressMsg: "",
+			expectedConditions: []osconfigv1.ClusterOperatorStatusCondition{
+				setStatusCondition(osconfigv1.OperatorDegraded, osconfigv1.ConditionTrue, string(ReasonImageCacheContentInvalid), "metal3 image cache is not ready, the OS image cannot be served on master-1 (ChecksumMismatch)"),
+				setStatusCondition(osconfigv1.OperatorProgressing, osconfigv1.ConditionTrue, string(ReasonImageCacheContentInvalid), ""),
+				setStatusCondition(osconfigv1.OperatorAvailable, osconfigv1.ConditionTrue, "", ""),
+				setStatusCondition(osconfigv1.OperatorUpgradeable, osconfigv1.ConditionTrue, "", ""),
+				setStatusCondition(OperatorDisabled, osconfigv1.ConditionFalse, "", ""),
+			},
+		},
 	}
 
 	reconciler := newFakeProvisioningReconciler(setUpSchemeForReconciler(), &osconfigv1.Infrastructure{})
@@ -0,0 +1,123 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/klog/v2"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/cluster-baremetal-operator/provisioning"
+)
+
+const (
+	// imageCacheStatusInterval is how often the disk usage of the image
+	// cache is read from the nodes. Reconciles happening in between reuse
+	// the status of the Provisioning CR, which would otherwise be updated
+	// on every reconcile as the free space changes.
+	imageCacheStatusInterval = 5 * time.Minute
+	// imageCacheDownloadStatusInterval replaces imageCacheStatusInterval
+	// while images are being downloaded, to follow their progress.
+	imageCacheDownloadStatusInterval = time.Minute
+	// imageCacheStatusTimeout bounds the request to each node.
+	imageCacheStatusTimeout = 10 * time.Second
+)
+
+// imageCacheStatusPeriod returns how often the image cache status is read
+// from the nodes, given the last one.
+func imageCacheStatusPeriod(statuses []metal3iov1alpha1.ImageCacheNodeStatus) time.Duration {
+	for _, status := range statuses {
+		images := status.Images
+		if status.Content != nil {
+			images = append([]metal3iov1alpha1.CachedImageStatus{*status.Content}, images...)
+		}
+		for _, image := range images {
+			switch image.State {
+			case metal3iov1alpha1.CachedImageMissing, metal3iov1alpha1.CachedImagePending, metal3iov1alpha1.CachedImageDownloading:
+				return imageCacheDownloadStatusInterval
+			}
+		}
+	}
+	return imageCacheStatusInterval
+}
+
+// imageCacheContentErrors lists the nodes where the image from
+// ProvisioningOSDownloadURL cannot be served, with its state.
+func imageCacheContentErrors(statuses []metal3iov1alpha1.ImageCacheNodeStatus) []string {
+	var nodes []string
+	for _, status := range statuses {
+		if status.Content == nil {
+			continue
+		}
+		switch status.Content.State {
+		case metal3iov1alpha1.CachedImageVerified, metal3iov1alpha1.CachedImageReady:
+		default:
+			nodes = append(nodes, fmt.Sprintf("%s (%s)", status.NodeName, status.Content.State))
+		}
+	}
+	return nodes
+}
+
+// updateImageCacheStatus records the disk usage of the image cache on each
+// node, including the state of the additional images, and the endpoints of
+// the image cache in each zone, in the status of the Provisioning CR, which
+// the caller writes. It returns the names of the nodes running low on disk
+// space.
+func (r *ProvisioningReconciler) updateImageCacheStatus(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, imageCacheState appsv1.DaemonSetConditionType) ([]string, error) {
+	statuses := provConfig.Status.ImageCache
+	var endpoints []metal3iov1alpha1.ImageCacheEndpoint
+
+	if imageCacheState == provisioning.DaemonSetDisabled {
+		statuses = nil
+		r.imageCacheChecked = time.Time{}
+	} else {
+		var err error
+		if time.Since(r.imageCacheChecked) >= imageCacheStatusPeriod(statuses) {
+			client := &http.Client{Timeout: imageCacheStatusTimeout}
+			statuses, err = provisioning.GetImageCacheNodeStatuses(ctx, r.KubeClient.CoreV1(), client, ComponentNamespace, &provConfig.Spec)
+			if err != nil {
+				return nil, errors.Wrap(err, "unable to read the image cache disk usage")
+			}
+			r.imageCacheChecked = time.Now()
+		}
+
+		// Unlike the disk usage, the endpoints only change when pods come
+		// and go, which triggers a reconcile through the DaemonSet.
+		endpoints, err = provisioning.GetImageCacheEndpoints(ctx, r.KubeClient.CoreV1(), r.Client, ComponentNamespace)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to determine the image cache endpoints")
+		}
+	}
+
+	provConfig.Status.ImageCache = statuses
+	provConfig.Status.ImageCacheEndpoints = endpoints
+
+	var lowDiskSpace []string
+	for _, status := range statuses {
+		if status.LowDiskSpace {
+			klog.Warningf("node %s is low on disk space for the image cache: %d bytes available out of %d",
+				status.NodeName, status.AvailableBytes, status.CapacityBytes)
+			lowDiskSpace = append(lowDiskSpace, status.NodeName)
+		}
+	}
+	return lowDiskSpace, nil
+}
@@ -0,0 +1,98 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/cluster-baremetal-operator/provisioning"
+)
+
+func TestUpdateImageCacheStatus(t *testing.T) {
+	sc := setUpSchemeForReconciler()
+	baremetalCR := &metal3iov1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+		Status: metal3iov1alpha1.ProvisioningStatus{
+			ImageCache: []metal3iov1alpha1.ImageCacheNodeStatus{
+				{NodeName: "master-0", CapacityBytes: 1000, AvailableBytes: 50, LowDiskSpace: true},
+				{NodeName: "master-1", CapacityBytes: 1000, AvailableBytes: 500},
+			},
+		},
+	}
+	r := &ProvisioningReconciler{
+		Scheme: sc,
+		Client: fakeclient.NewClientBuilder().WithScheme(sc).WithRuntimeObjects(baremetalCR).
+			WithStatusSubresource(&metal3iov1alpha1.Provisioning{}).Build(),
+		KubeClient:        fakekube.NewSimpleClientset(),
+		imageCacheChecked: time.Now(),
+	}
+
+	// Recently checked, the known status is kept.
+	lowDiskSpace, err := r.updateImageCacheStatus(context.TODO(), baremetalCR, provisioning.DaemonSetAvailable)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master-0"}, lowDiskSpace)
+	assert.Len(t, baremetalCR.Status.ImageCache, 2)
+
+	// Without image cache pods, there is nothing to report.
+	r.imageCacheChecked = time.Time{}
+	lowDiskSpace, err = r.updateImageCacheStatus(context.TODO(), baremetalCR, provisioning.DaemonSetAvailable)
+	assert.NoError(t, err)
+	assert.Empty(t, lowDiskSpace)
+	assert.Empty(t, baremetalCR.Status.ImageCache)
+	assert.False(t, r.imageCacheChecked.IsZero())
+
+	// A disabled image cache clears the status.
+	baremetalCR.Status.ImageCache = []metal3iov1alpha1.ImageCacheNodeStatus{{NodeName: "master-0", LowDiskSpace: true}}
+	lowDiskSpace, err = r.updateImageCacheStatus(context.TODO(), baremetalCR, provisioning.DaemonSetDisabled)
+	assert.NoError(t, err)
+	assert.Empty(t, lowDiskSpace)
+	assert.Empty(t, baremetalCR.Status.ImageCache)
+}
+
+func TestImageCacheStatusPeriod(t *testing.T) {
+	assert.Equal(t, imageCacheStatusInterval, imageCacheStatusPeriod(nil))
+	assert.Equal(t, imageCacheStatusInterval, imageCacheStatusPeriod([]metal3iov1alpha1.ImageCacheNodeStatus{
+		{NodeName: "master-0", Images: []metal3iov1alpha1.CachedImageStatus{{Name: "custom.raw", State: metal3iov1alpha1.CachedImageVerified}}},
+	}))
+	assert.Equal(t, imageCacheDownloadStatusInterval, imageCacheStatusPeriod([]metal3iov1alpha1.ImageCacheNodeStatus{
+		{NodeName: "master-0", Images: []metal3iov1alpha1.CachedImageStatus{{Name: "custom.raw", State: metal3iov1alpha1.CachedImageVerified}}},
+		{NodeName: "master-1", Images: []metal3iov1alpha1.CachedImageStatus{{Name: "custom.raw", State: metal3iov1alpha1.CachedImageDownloading}}},
+	}))
+}
+
+func TestImageCacheContentErrors(t *testing.T) {
+	content := func(state metal3iov1alpha1.CachedImageState) *metal3iov1alpha1.CachedImageStatus {
+		return &metal3iov1alpha1.CachedImageStatus{Name: "rhcos.qcow2", State: state}
+	}
+	statuses := []metal3iov1alpha1.ImageCacheNodeStatus{
+		{NodeName: "master-0", Content: content(metal3iov1alpha1.CachedImageVerified)},
+		{NodeName: "master-1", Content: content(metal3iov1alpha1.CachedImageChecksumMismatch)},
+		{NodeName: "master-2", Content: content(metal3iov1alpha1.CachedImageMissing)},
+		{NodeName: "worker-0", Content: content(metal3iov1alpha1.CachedImageReady)},
+		{NodeName: "worker-1", Message: "image cache pod is not running"},
+	}
+
+	assert.Equal(t, []string{"master-1 (ChecksumMismatch)", "master-2 (Missing)"}, imageCacheContentErrors(statuses))
+	assert.Equal(t, imageCacheDownloadStatusInterval, imageCacheStatusPeriod(statuses))
+}
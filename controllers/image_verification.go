@@ -0,0 +1,196 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/openshift/library-go/pkg/operator/v1helpers"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/cluster-baremetal-operator/provisioning"
+)
+
+const (
+	// imageVerificationInterval is how long the results of a verification
+	// are kept before the images are checked again.
+	imageVerificationInterval = 30 * time.Minute
+	// imageVerificationTimeout bounds a verification, including the
+	// download of the images when their checksum is verified.
+	imageVerificationTimeout = 20 * time.Minute
+	// imageVerificationPoll is how often a running verification is polled.
+	imageVerificationPoll = 15 * time.Second
+)
+
+// imageURLVerifier checks the OS image URLs in the background, so that slow
+// image servers do not hold up the reconcile loop.
+type imageURLVerifier struct {
+	mu      sync.Mutex
+	key     string
+	running bool
+	checked time.Time
+	results []provisioning.ImageURLResult
+}
+
+// get returns the results of the last verification done for key. It starts
+// a new verification when there is none for key or when its results are
+// stale. done is false until results for key are available.
+func (v *imageURLVerifier) get(key string, verify func() []provisioning.ImageURLResult) (results []provisioning.ImageURLResult, done bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	known := v.key == key && !v.checked.IsZero()
+	if !v.running && (!known || time.Since(v.checked) >= imageVerificationInterval) {
+		v.running = true
+		go func() {
+			results := verify()
+
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			v.key = key
+			v.results = results
+			v.checked = time.Now()
+			v.running = false
+		}()
+	}
+
+	if !known {
+		return nil, false
+	}
+	return v.results, true
+}
+
+// osImagesCondition summarizes the verification of the OS image URLs.
+func osImagesCondition(results []provisioning.ImageURLResult, done bool, verifyChecksum bool) operatorv1.OperatorCondition {
+	condition := operatorv1.OperatorCondition{Type: metal3iov1alpha1.OSImagesAvailableCondition}
+	if !done {
+		condition.Status = operatorv1.ConditionUnknown
+		condition.Reason = "Verifying"
+		condition.Message = "checking that the OS images can be downloaded"
+		return condition
+	}
+
+	var failures []string
+	verified := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", redactURL(result.URL), result.Err))
+		}
+		if result.ChecksumVerified {
+			verified++
+		}
+	}
+
+	if len(failures) > 0 {
+		condition.Status = operatorv1.ConditionFalse
+		condition.Reason = "ImageUnavailable"
+		condition.Message = strings.Join(failures, "; ")
+		return condition
+	}
+
+	condition.Status = operatorv1.ConditionTrue
+	condition.Reason = "ImagesAvailable"
+	condition.Message = fmt.Sprintf("%d OS images can be downloaded", len(results))
+	if verifyChecksum {
+		condition.Message += fmt.Sprintf(", %d checksums verified", verified)
+	}
+	return condition
+}
+
+// redactURL hides the password of URLs embedding credentials.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// updateOSImagesCondition records in the status of the Provisioning CR, which
+// the caller writes, whether the OS images it references can be downloaded
+// through the cluster proxy. It returns when the reconcile should be requeued to pick up
+// the results of a running or stale verification.
+func (r *ProvisioningReconciler) updateOSImagesCondition(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, proxy *osconfigv1.Proxy) (time.Duration, error) {
+	conditions := append([]operatorv1.OperatorCondition{}, provConfig.Status.Conditions...)
+
+	urls := provisioning.OSImageURLs(&provConfig.Spec)
+	osImageURL := provConfig.Spec.ProvisioningOSDownloadURL
+	checksumURL := provConfig.Spec.ProvisioningOSChecksumURL
+	verifyChecksum := provConfig.Annotations[metal3iov1alpha1.VerifyImageChecksumsAnnotation] == "true"
+	var requeueAfter time.Duration
+
+	if len(urls) == 0 {
+		v1helpers.RemoveOperatorCondition(&conditions, metal3iov1alpha1.OSImagesAvailableCondition)
+	} else {
+		caBundle, err := provisioning.ReadTrustedCABundle(ctx, r.KubeClient.CoreV1(), ComponentNamespace)
+		if err != nil {
+			return 0, errors.Wrap(err, "unable to read the trusted CA bundle")
+		}
+
+		var proxyStatus osconfigv1.ProxyStatus
+		if proxy != nil {
+			proxyStatus = proxy.Status
+		}
+		key := fmt.Sprintf("%q %t %q %q %q %x", urls, verifyChecksum,
+			proxyStatus.HTTPProxy, proxyStatus.HTTPSProxy, proxyStatus.NoProxy, sha256.Sum256(caBundle))
+
+		results, done := r.imageVerifier.get(key, func() []provisioning.ImageURLResult {
+			ctx, cancel := context.WithTimeout(context.Background(), imageVerificationTimeout)
+			defer cancel()
+
+			results := make([]provisioning.ImageURLResult, 0, len(urls))
+			client, err := provisioning.NewImageHTTPClient(proxy, caBundle)
+			for _, u := range urls {
+				if err != nil {
+					results = append(results, provisioning.ImageURLResult{URL: u, Size: -1, Err: err})
+					continue
+				}
+				imageChecksumURL := ""
+				if u == osImageURL {
+					imageChecksumURL = checksumURL
+				}
+				results = append(results, provisioning.VerifyImageURL(ctx, client, u, imageChecksumURL, verifyChecksum))
+			}
+			return results
+		})
+
+		condition := osImagesCondition(results, done, verifyChecksum)
+		if condition.Status == operatorv1.ConditionFalse {
+			klog.Warningf("OS images cannot be downloaded: %s", condition.Message)
+		}
+		v1helpers.SetOperatorCondition(&conditions, condition)
+
+		requeueAfter = imageVerificationInterval
+		if !done {
+			requeueAfter = imageVerificationPoll
+		}
+	}
+
+	provConfig.Status.Conditions = conditions
+
+	return requeueAfter, nil
+}
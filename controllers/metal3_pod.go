@@ -0,0 +1,60 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/openshift/cluster-baremetal-operator/provisioning"
+)
+
+func isMetal3Pod(object client.Object) bool {
+	return object.GetNamespace() == ComponentNamespace &&
+		labels.SelectorFromSet(provisioning.Metal3PodLabels()).Matches(labels.Set(object.GetLabels()))
+}
+
+// metal3PodIPsChanged filters the events of the metal3 pod down to the ones
+// changing the IPs Ironic is reached on: the URLs handed to the dependent
+// components may embed them.
+func metal3PodIPsChanged() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isMetal3Pod(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, ok := e.ObjectOld.(*corev1.Pod)
+			if !ok || !isMetal3Pod(e.ObjectNew) {
+				return false
+			}
+			newPod, ok := e.ObjectNew.(*corev1.Pod)
+			if !ok {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldPod.Status.PodIPs, newPod.Status.PodIPs)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isMetal3Pod(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
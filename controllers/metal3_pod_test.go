@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/cluster-baremetal-operator/provisioning"
+)
+
+func metal3Pod(ips ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metal3-7d9b7c5d4f-x8k2p",
+			Namespace: ComponentNamespace,
+			Labels:    provisioning.Metal3PodLabels(),
+		},
+	}
+	for _, ip := range ips {
+		pod.Status.PodIPs = append(pod.Status.PodIPs, corev1.PodIP{IP: ip})
+	}
+	return pod
+}
+
+func TestMetal3PodIPsChanged(t *testing.T) {
+	otherPod := metal3Pod("192.168.111.21")
+	otherPod.Labels = map[string]string{"k8s-app": "metal3"}
+	otherNamespacePod := metal3Pod("192.168.111.21")
+	otherNamespacePod.Namespace = "default"
+	movedPod := metal3Pod("192.168.111.22")
+	movedPod.Status.Phase = corev1.PodRunning
+	runningPod := metal3Pod("192.168.111.21")
+	runningPod.Status.Phase = corev1.PodRunning
+
+	testCases := []struct {
+		name     string
+		event    func() bool
+		expected bool
+	}{
+		{
+			name:     "metal3 pod created",
+			event:    func() bool { return metal3PodIPsChanged().Create(event.CreateEvent{Object: metal3Pod()}) },
+			expected: true,
+		},
+		{
+			name:     "other pod created",
+			event:    func() bool { return metal3PodIPsChanged().Create(event.CreateEvent{Object: otherPod}) },
+			expected: false,
+		},
+		{
+			name:     "metal3 pod in another namespace created",
+			event:    func() bool { return metal3PodIPsChanged().Create(event.CreateEvent{Object: otherNamespacePod}) },
+			expected: false,
+		},
+		{
+			name: "metal3 pod gets its IPs",
+			event: func() bool {
+				return metal3PodIPsChanged().Update(event.UpdateEvent{ObjectOld: metal3Pod(), ObjectNew: metal3Pod("192.168.111.21")})
+			},
+			expected: true,
+		},
+		{
+			name: "metal3 pod IPs change",
+			event: func() bool {
+				return metal3PodIPsChanged().Update(event.UpdateEvent{ObjectOld: metal3Pod("192.168.111.21"), ObjectNew: movedPod})
+			},
+			expected: true,
+		},
+		{
+			name: "metal3 pod status changes without IP change",
+			event: func() bool {
+				return metal3PodIPsChanged().Update(event.UpdateEvent{ObjectOld: metal3Pod("192.168.111.21"), ObjectNew: runningPod})
+			},
+			expected: false,
+		},
+		{
+			name: "metal3 pod deleted",
+			event: func() bool {
+				return metal3PodIPsChanged().Delete(event.DeleteEvent{Object: metal3Pod("192.168.111.21")})
+			},
+			expected: true,
+		},
+		{
+			name: "generic event",
+			event: func() bool {
+				return metal3PodIPsChanged().Generic(event.GenericEvent{Object: metal3Pod("192.168.111.21")})
+			},
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.event())
+		})
+	}
+}
+
+// TestMetal3PodMoveUpdatesIronicURLs checks that reconciling after the metal3
+// pod moved, as triggered by the pod watch, hands the new Ironic URLs to the
+// dependent components.
+func TestMetal3PodMoveUpdatesIronicURLs(t *testing.T) {
+	kubeClient := fakekube.NewSimpleClientset(metal3Pod("192.168.111.21"))
+	info := &provisioning.ProvisioningInfo{
+		Client:        kubeClient,
+		EventRecorder: events.NewLoggingEventRecorder("test", clock.RealClock{}),
+		Scheme:        setUpSchemeForReconciler(),
+		Namespace:     ComponentNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+			Spec: metal3iov1alpha1.ProvisioningSpec{
+				ProvisioningNetwork: metal3iov1alpha1.ProvisioningNetworkDisabled,
+			},
+		},
+		OSClient: fakeconfigclientset.NewSimpleClientset(&osconfigv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: osconfigv1.InfrastructureStatus{
+				PlatformStatus: &osconfigv1.PlatformStatus{Type: osconfigv1.NonePlatformType},
+			},
+		}),
+	}
+	ironicBaseURL := func() string {
+		configMap, err := kubeClient.CoreV1().ConfigMaps(ComponentNamespace).Get(context.Background(), "metal3-ironic-urls", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return configMap.Data["IRONIC_BASE_URL"]
+	}
+
+	_, err := provisioning.EnsureIronicURLs(info)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://192.168.111.21:6385", ironicBaseURL())
+
+	oldPod := metal3Pod("192.168.111.21")
+	newPod := metal3Pod("192.168.111.22")
+	_, err = kubeClient.CoreV1().Pods(ComponentNamespace).UpdateStatus(context.Background(), newPod, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.True(t, metal3PodIPsChanged().Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: newPod}))
+
+	updated, err := provisioning.EnsureIronicURLs(info)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, "https://192.168.111.22:6385", ironicBaseURL())
+
+	externalURLs, err := provisioning.GetExternalURLs(info)
+	assert.NoError(t, err)
+	assert.Contains(t, externalURLs, metal3iov1alpha1.ExternalURL{
+		Consumer: metal3iov1alpha1.ExternalURLConsumerImageCustomization,
+		URL:      "https://192.168.111.22:6385",
+	})
+}
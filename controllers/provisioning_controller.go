@@ -23,6 +23,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ghodss/yaml"
@@ -30,6 +31,7 @@ import (
 	"github.com/stretchr/stew/slice"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -70,13 +72,17 @@ const (
 	// ComponentName is the full name of CBO
 	ComponentName = "cluster-baremetal-operator"
 	// install-config access details
-	clusterConfigName      = "cluster-config-v1"
+	ClusterConfigName      = "cluster-config-v1"
 	clusterConfigKey       = "install-config"
-	clusterConfigNamespace = "kube-system"
+	ClusterConfigNamespace = "kube-system"
 	// Annotation linking a machine to a host
 	HostAnnotation = "metal3.io/BareMetalHost"
 )
 
+// metal3PodIPsRequeueAfter is how long until checking again whether the
+// metal3 pod has IPs, in case the event of the pod is missed.
+const metal3PodIPsRequeueAfter = 30 * time.Second
+
 // ProvisioningReconciler reconciles a Provisioning object
 type ProvisioningReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
@@ -92,6 +98,12 @@ type ProvisioningReconciler struct {
 	NetworkStack    provisioning.NetworkStackType
 	EnabledFeatures metal3iov1alpha1.EnabledFeatures
 	ResourceCache   resourceapply.ResourceCache
+
+	imageVerifier     imageURLVerifier
+	imageCacheChecked time.Time
+	// ramdiskSSHKeySecretName is the name of the Secret holding the SSH key
+	// of the ramdisk, as last read from the Provisioning spec.
+	ramdiskSSHKeySecretName atomic.Pointer[string]
 }
 
 type ensureFunc func(*provisioning.ProvisioningInfo) (bool, error)
@@ -116,6 +128,7 @@ type ensureFunc func(*provisioning.ProvisioningInfo) (bool, error)
 // +kubebuilder:rbac:groups="",resources=configmaps;secrets;services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=machine.openshift.io,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=metal3.io,resources=provisionings;provisionings/finalizers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=provisionings/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;update;patch
@@ -128,6 +141,7 @@ type ensureFunc func(*provisioning.ProvisioningInfo) (bool, error)
 // +kubebuilder:rbac:groups=metal3.io,resources=bmceventsubscriptions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=bmceventsubscriptions/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;update;patch;create;delete
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;update;patch;create;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=hardwaredata,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=hostfirmwarecomponents,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=hostfirmwarecomponents/status,verbs=get;update;patch
@@ -156,19 +170,37 @@ type InstallConfigData struct {
 	SSHKey string
 }
 
-func (r *ProvisioningReconciler) readSSHKey() string {
+// installConfigParseError is returned by readSSHKey when the install-config
+// cannot be parsed.
+type installConfigParseError struct {
+	err error
+}
+
+func (e *installConfigParseError) Error() string {
+	return fmt.Sprintf("unable to parse the install-config: %v", e.err)
+}
+
+func (e *installConfigParseError) Unwrap() error {
+	return e.err
+}
+
+// readSSHKey returns the SSH key of the install-config, empty when there is
+// no install-config.
+func (r *ProvisioningReconciler) readSSHKey(ctx context.Context) (string, error) {
 	installConfigData := InstallConfigData{}
-	clusterConfig, err := r.KubeClient.CoreV1().ConfigMaps(clusterConfigNamespace).Get(context.Background(), clusterConfigName, metav1.GetOptions{})
+	clusterConfig, err := r.KubeClient.CoreV1().ConfigMaps(ClusterConfigNamespace).Get(ctx, ClusterConfigName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("%s/%s not found, no install-config SSH key is authorized in the ramdisk", ClusterConfigNamespace, ClusterConfigName)
+		return "", nil
+	}
 	if err != nil {
-		klog.Warningf("Error: %v", err)
-		return ""
+		return "", fmt.Errorf("unable to read the install-config: %w", err)
 	}
 	err = yaml.Unmarshal([]byte(clusterConfig.Data[clusterConfigKey]), &installConfigData)
 	if err != nil {
-		klog.Warningf("Error: %v", err)
-		return ""
+		return "", &installConfigParseError{err: err}
 	}
-	return strings.TrimSpace(installConfigData.SSHKey)
+	return strings.TrimSpace(installConfigData.SSHKey), nil
 }
 
 func (r *ProvisioningReconciler) checkDaemonSet(state appsv1.DaemonSetConditionType, stateError error, name string, deleter func() error) error {
@@ -220,6 +252,18 @@ func getSuccessStatus(imageCacheState appsv1.DaemonSetConditionType, ironicProxy
 	}
 }
 
+// updateProvisioningStatus writes the status of the Provisioning CR when it
+// differs from the original one, read before this reconcile changed it.
+func (r *ProvisioningReconciler) updateProvisioningStatus(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning, original *metal3iov1alpha1.ProvisioningStatus) error {
+	if equality.Semantic.DeepEqual(&provConfig.Status, original) {
+		return nil
+	}
+	if err := r.Client.Status().Update(ctx, provConfig); err != nil {
+		return fmt.Errorf("unable to update the Provisioning status: %w", err)
+	}
+	return nil
+}
+
 // Reconcile updates the cluster settings when the Provisioning
 // resource changes
 func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -284,7 +328,16 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
-	info, err := r.provisioningInfo(ctx, baremetalConfig, &containerImages, r.readSSHKey())
+	sshKey, sshKeysStatus, debugSSHKeyExpiresIn, err := r.ramdiskSSHKeys(ctx, baremetalConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// The debug SSH key of the ramdisk is removed when it expires.
+	if debugSSHKeyExpiresIn > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > debugSSHKeyExpiresIn) {
+		result.RequeueAfter = debugSSHKeyExpiresIn
+	}
+
+	info, err := r.provisioningInfo(ctx, baremetalConfig, &containerImages, sshKey)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -336,11 +389,45 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		// Temporarily not requeuing request, as the user will have to fix the CR.
 		return ctrl.Result{}, nil
 	}
+	// The CRs created before the checksums and some of the fields were
+	// checked may not pass these checks, only warn about them.
+	warnings, _ := baremetalConfig.ValidateChangedFields(baremetalConfig, r.EnabledFeatures)
+	for _, warning := range append(warnings, baremetalConfig.MissingChecksumWarnings()...) {
+		klog.Warning(warning)
+	}
+
+	// The status is collected along the way and written once.
+	originalStatus := baremetalConfig.Status.DeepCopy()
+
+	imagesRequeueAfter, err := r.updateOSImagesCondition(ctx, baremetalConfig, info.Proxy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if imagesRequeueAfter > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > imagesRequeueAfter) {
+		result.RequeueAfter = imagesRequeueAfter
+	}
+
+	if !info.IsHyperShift {
+		info.UnusableProvisioningNodes, err = r.checkProvisioningInterfaces(ctx, baremetalConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if allNodesUnusable(baremetalConfig.Status.ProvisioningInterfaces) {
+			msg := fmt.Sprintf("no master node has a usable provisioning interface: %s", strings.Join(info.UnusableProvisioningNodes, ", "))
+			err = r.updateCOStatus(ReasonInvalidConfiguration, msg, "Unable to apply Provisioning CR: no usable provisioning interface")
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
+			}
+			// Hardware inventory may still change, check again later.
+			return ctrl.Result{RequeueAfter: time.Minute}, r.updateProvisioningStatus(ctx, baremetalConfig, originalStatus)
+		}
+	}
 
 	for _, ensureResource := range []ensureFunc{
 		provisioning.EnsureAllSecrets,
 		provisioning.EnsureMirrorConfig,
 		provisioning.EnsureMetal3Deployment,
+		provisioning.EnsureIronicURLs,
 		provisioning.EnsureBaremetalOperatorDeployment,
 		provisioning.EnsureMetal3StateService,
 		provisioning.EnsureImageCache,
@@ -353,19 +440,67 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		provisioning.EnsureIronicPrometheusRule,
 	} {
 		updated, err := ensureResource(info)
+		if errors.Is(err, provisioning.ErrNoMetal3PodIPs) {
+			// The metal3 pod is not ready yet, its IPs changing triggers
+			// another reconcile.
+			klog.Info("waiting for the metal3 pod to get IPs")
+			return ctrl.Result{RequeueAfter: metal3PodIPsRequeueAfter}, nil
+		}
 		if err != nil {
 			return ctrl.Result{}, err
 		}
 		if updated {
-			return result, r.Client.Status().Update(ctx, baremetalConfig)
+			return result, r.updateProvisioningStatus(ctx, baremetalConfig, originalStatus)
+		}
+	}
+
+	externalURLs, err := provisioning.GetExternalURLs(info)
+	if errors.Is(err, provisioning.ErrNoMetal3PodIPs) {
+		// Keep reporting the previous URLs until the metal3 pod has IPs.
+		externalURLs = baremetalConfig.Status.ExternalURLs
+		if result.RequeueAfter == 0 || result.RequeueAfter > metal3PodIPsRequeueAfter {
+			result.RequeueAfter = metal3PodIPsRequeueAfter
 		}
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to determine external URLs: %w", err)
+	}
+
+	osImage, err := provisioning.GetOSImageStatus(&baremetalConfig.Spec)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to determine OS image format: %w", err)
 	}
 
-	if specChanged {
-		baremetalConfig.Status.ObservedGeneration = baremetalConfig.Generation
-		err = r.Client.Status().Update(ctx, baremetalConfig)
+	ironicProxyMode := provisioning.GetIronicProxyMode(info)
+
+	baremetalConfig.Status.ObservedGeneration = baremetalConfig.Generation
+	baremetalConfig.Status.ExternalURLs = externalURLs
+	baremetalConfig.Status.OSImage = osImage
+	baremetalConfig.Status.IronicProxy = ironicProxyMode
+	baremetalConfig.Status.RamdiskSSHKeys = sshKeysStatus
+
+	// Determine the status of the image cache DaemonSet
+	imageCacheState, imageCacheErr := provisioning.GetImageCacheState(r.KubeClient.AppsV1(), ComponentNamespace, baremetalConfig)
+	var lowDiskSpaceNodes []string
+	if imageCacheErr == nil {
+		lowDiskSpaceNodes, err = r.updateImageCacheStatus(ctx, baremetalConfig, imageCacheState)
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("unable to update observed generation: %w", err)
+			if imageCacheState != provisioning.DaemonSetReplicaFailure {
+				return ctrl.Result{}, err
+			}
+			// The image cache pods may not serve their status yet.
+			klog.Warningf("unable to update the image cache status: %v", err)
+		}
+	}
+
+	if err := r.updateProvisioningStatus(ctx, baremetalConfig, originalStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The webhook only resolves the image of the hosts when they are
+	// written, resolve it again against the endpoints written above.
+	if imageCacheErr == nil {
+		if err := provisioning.ResolveHostImages(ctx, r.Client, baremetalConfig, ComponentNamespace); err != nil {
+			return ctrl.Result{}, err
 		}
 	}
 
@@ -401,9 +536,21 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
-	// Determine the status of the image cache DaemonSet
-	imageCacheState, err := provisioning.GetImageCacheState(r.KubeClient.AppsV1(), ComponentNamespace, baremetalConfig)
-	err = r.checkDaemonSet(imageCacheState, err, "metal3 image cache", func() error { return provisioning.DeleteImageCache(info) })
+	if imageCacheErr == nil && imageCacheState == provisioning.DaemonSetReplicaFailure {
+		// The image cache pods are only ready once they hold the OS
+		// image, report the nodes where it is not usable.
+		if nodes := imageCacheContentErrors(baremetalConfig.Status.ImageCache); len(nodes) > 0 {
+			msg := fmt.Sprintf("metal3 image cache is not ready, the OS image cannot be served on %s", strings.Join(nodes, ", "))
+			if err := r.updateCOStatus(ReasonImageCacheContentInvalid, msg, ""); err != nil {
+				return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
+			}
+			// The image cache downloads the image again, check its
+			// progress later.
+			klog.Info(msg)
+			return ctrl.Result{RequeueAfter: imageCacheDownloadStatusInterval}, nil
+		}
+	}
+	err = r.checkDaemonSet(imageCacheState, imageCacheErr, "metal3 image cache", func() error { return provisioning.DeleteImageCache(info) })
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -414,9 +561,22 @@ func (r *ProvisioningReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	if period := imageCacheStatusPeriod(baremetalConfig.Status.ImageCache); imageCacheState != provisioning.DaemonSetDisabled && (result.RequeueAfter == 0 || result.RequeueAfter > period) {
+		result.RequeueAfter = period
+	}
+
 	if deploymentState == appsv1.DeploymentAvailable && bmoState == appsv1.DeploymentAvailable {
 		msg := getSuccessStatus(imageCacheState, ironicProxyState)
-		if msg != "" {
+		if msg != "" && len(lowDiskSpaceNodes) > 0 {
+			// Everything runs, but the nodes may soon be unable to cache
+			// new images.
+			lowMsg := fmt.Sprintf("less than %d%% of the disk holding the image cache is free on %s",
+				provisioning.ImageCacheMinFreeSpacePercent(&baremetalConfig.Spec), strings.Join(lowDiskSpaceNodes, ", "))
+			err = r.updateCOStatus(ReasonImageCacheLowDiskSpace, lowMsg, msg)
+			if err != nil {
+				return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Degraded state: %w", clusterOperatorName, err)
+			}
+		} else if msg != "" {
 			err = r.updateCOStatus(ReasonComplete, msg, "")
 			if err != nil {
 				return ctrl.Result{}, fmt.Errorf("unable to put %q ClusterOperator in Progressing state: %w", clusterOperatorName, err)
@@ -533,6 +693,9 @@ func (r *ProvisioningReconciler) deleteMetal3Resources(info *provisioning.Provis
 	if err := provisioning.DeleteImageCache(info); err != nil {
 		return errors.Wrap(err, "failed to delete metal3 image cache")
 	}
+	if err := provisioning.DeleteIronicURLs(info); err != nil {
+		return errors.Wrap(err, "failed to delete Ironic URLs")
+	}
 	if err := provisioning.DeleteImageCustomizationService(info); err != nil {
 		return errors.Wrap(err, "failed to delete metal3 image customization service")
 	}
@@ -758,6 +921,8 @@ func (r *ProvisioningReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&osconfigv1.Proxy{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton)).
 		Watches(&osconfigv1.APIServer{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton)).
 		Watches(&osconfigv1.ImageDigestMirrorSet{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton)).
-		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton), builder.WithPredicates(pullSecretFilter)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton), builder.WithPredicates(predicate.Or(pullSecretFilter, r.ramdiskSSHKeySecretFilter()))).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton), builder.WithPredicates(predicate.NewPredicateFuncs(isInstallConfig))).
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(mapToProvisioningSingleton), builder.WithPredicates(metal3PodIPsChanged())).
 		Complete(r)
 }
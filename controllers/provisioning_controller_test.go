@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
@@ -316,3 +317,32 @@ func TestUpdateProvisioningMacAddresses(t *testing.T) {
 	assert.NoError(t, err, "ProvisioningReconciler.updateProvisioningMacAddresses()")
 	assert.ElementsMatch(t, baremetalCR.Spec.ProvisioningMacAddresses, want)
 }
+
+func TestUpdateProvisioningStatus(t *testing.T) {
+	sc := setUpSchemeForReconciler()
+	baremetalCR := &metal3iov1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+	}
+	r := &ProvisioningReconciler{
+		Scheme: sc,
+		Client: fakeclient.NewClientBuilder().WithScheme(sc).WithRuntimeObjects(baremetalCR).
+			WithStatusSubresource(&metal3iov1alpha1.Provisioning{}).Build(),
+	}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(baremetalCR), baremetalCR))
+
+	// An unchanged status is not written.
+	original := baremetalCR.Status.DeepCopy()
+	resourceVersion := baremetalCR.ResourceVersion
+	assert.NoError(t, r.updateProvisioningStatus(context.TODO(), baremetalCR, original))
+	assert.Equal(t, resourceVersion, baremetalCR.ResourceVersion)
+
+	// All the collected changes are written at once.
+	baremetalCR.Status.ObservedGeneration = 2
+	baremetalCR.Status.ImageCache = []metal3iov1alpha1.ImageCacheNodeStatus{{NodeName: "master-0"}}
+	assert.NoError(t, r.updateProvisioningStatus(context.TODO(), baremetalCR, original))
+	assert.NotEqual(t, resourceVersion, baremetalCR.ResourceVersion)
+
+	stored := &metal3iov1alpha1.Provisioning{}
+	assert.NoError(t, r.Client.Get(context.TODO(), client.ObjectKeyFromObject(baremetalCR), stored))
+	assert.Equal(t, baremetalCR.Status, stored.Status)
+}
@@ -2,9 +2,13 @@ package controllers
 
 import (
 	"context"
+	"net"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/utils/net"
 
 	osconfigv1 "github.com/openshift/api/config/v1"
 	osclientset "github.com/openshift/client-go/config/clientset/versioned"
@@ -47,5 +51,47 @@ func EnabledFeatures(ctx context.Context, osClient osclientset.Interface) (v1alp
 		features.ProvisioningNetwork[v1alpha1.ProvisioningNetworkDisabled] = true
 	}
 
+	// ironic-proxy runs on the control plane nodes.
+	features.IronicProxy = infra.Status.ControlPlaneTopology != osconfigv1.ExternalTopologyMode
+
+	features.IPFamilies, err = serviceNetworkIPFamilies(ctx, osClient)
+	if err != nil {
+		return features, err
+	}
+
 	return features, nil
 }
+
+// serviceNetworkIPFamilies returns the IP families of the cluster service
+// network, or nil if the network configuration is not available yet.
+func serviceNetworkIPFamilies(ctx context.Context, osClient osclientset.Interface) (map[corev1.IPFamily]bool, error) {
+	network, err := osClient.ConfigV1().Networks().Get(ctx, "cluster", metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "unable to read Network CR")
+	}
+
+	serviceNetwork := network.Status.ServiceNetwork
+	if len(serviceNetwork) == 0 {
+		serviceNetwork = network.Spec.ServiceNetwork
+	}
+	if len(serviceNetwork) == 0 {
+		return nil, nil
+	}
+
+	families := map[corev1.IPFamily]bool{}
+	for _, snet := range serviceNetwork {
+		_, cidr, err := net.ParseCIDR(snet)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not parse Service Network %s", snet)
+		}
+		if utilnet.IsIPv6CIDR(cidr) {
+			families[corev1.IPv6Protocol] = true
+		} else {
+			families[corev1.IPv4Protocol] = true
+		}
+	}
+	return families, nil
+}
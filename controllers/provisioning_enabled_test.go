@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -42,48 +43,56 @@ func TestIsEnabled(t *testing.T) {
 		infra         *configv1.Infrastructure
 		expectedError bool
 		isEnabled     bool
+		ironicProxy   bool
 	}{
 		{
 			name:          "BaremetalPlatform",
 			infra:         withPlatformType(infra, configv1.BareMetalPlatformType),
 			expectedError: false,
 			isEnabled:     true,
+			ironicProxy:   true,
 		},
 		{
 			name:          "NonePlatform",
 			infra:         &infra,
 			expectedError: false,
 			isEnabled:     true,
+			ironicProxy:   true,
 		},
 		{
 			name:          "aws",
 			infra:         withPlatformType(infra, configv1.AWSPlatformType),
 			expectedError: false,
 			isEnabled:     true,
+			ironicProxy:   true,
 		},
 		{
 			name:          "azure",
 			infra:         withPlatformType(infra, configv1.AzurePlatformType),
 			expectedError: false,
 			isEnabled:     true,
+			ironicProxy:   true,
 		},
 		{
 			name:          "gcp",
 			infra:         withPlatformType(infra, configv1.GCPPlatformType),
 			expectedError: false,
 			isEnabled:     true,
+			ironicProxy:   true,
 		},
 		{
 			name:          "kubevirt",
 			infra:         withPlatformType(infra, configv1.KubevirtPlatformType),
 			expectedError: false,
 			isEnabled:     true,
+			ironicProxy:   true,
 		},
 		{
 			name:          "NoPlatform",
 			infra:         withPlatformType(infra, ""),
 			expectedError: false,
 			isEnabled:     false,
+			ironicProxy:   true,
 		},
 		{
 			name:          "BadPlatform",
@@ -112,6 +121,45 @@ func TestIsEnabled(t *testing.T) {
 				return
 			}
 			assert.Equal(t, tc.isEnabled, IsEnabled(ef), "enabled results did not match")
+			assert.Equal(t, tc.ironicProxy, ef.IronicProxy, "ironic-proxy support did not match")
+		})
+	}
+}
+
+func TestServiceNetworkIPFamilies(t *testing.T) {
+	testCases := []struct {
+		name             string
+		network          *configv1.Network
+		expectedFamilies map[corev1.IPFamily]bool
+	}{
+		{
+			name:             "NoNetwork",
+			network:          &configv1.Network{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+			expectedFamilies: nil,
+		},
+		{
+			name: "SpecOnly",
+			network: &configv1.Network{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.NetworkSpec{ServiceNetwork: []string{"172.30.0.0/16"}},
+			},
+			expectedFamilies: map[corev1.IPFamily]bool{corev1.IPv4Protocol: true},
+		},
+		{
+			name: "DualStackStatus",
+			network: &configv1.Network{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       configv1.NetworkSpec{ServiceNetwork: []string{"172.30.0.0/16"}},
+				Status:     configv1.NetworkStatus{ServiceNetwork: []string{"172.30.0.0/16", "fd02::/112"}},
+			},
+			expectedFamilies: map[corev1.IPFamily]bool{corev1.IPv4Protocol: true, corev1.IPv6Protocol: true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			families, err := serviceNetworkIPFamilies(context.TODO(), fakeconfigclientset.NewSimpleClientset(tc.network))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedFamilies, families)
 		})
 	}
 }
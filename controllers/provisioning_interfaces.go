@@ -0,0 +1,199 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+const masterNodeRoleLabel = "node-role.kubernetes.io/master"
+
+// masterHostsByNode maps the names of master nodes to the names of the
+// BareMetalHosts backing them, going through the Machine of each node.
+func (r *ProvisioningReconciler) masterHostsByNode(ctx context.Context) (map[string]string, error) {
+	machines := machinev1beta1.MachineList{}
+	labelReq, _ := labels.NewRequirement("machine.openshift.io/cluster-api-machine-role", selection.Equals, []string{"master"})
+	err := r.Client.List(ctx, &machines, &client.ListOptions{LabelSelector: labels.NewSelector().Add(*labelReq)})
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) || meta.IsNoMatchError(err) {
+			return map[string]string{}, nil
+		}
+		return nil, errors.Wrap(err, "cannot list master machines")
+	}
+
+	bmhl := baremetalv1alpha1.BareMetalHostList{}
+	if err := r.Client.List(ctx, &bmhl, &client.ListOptions{Namespace: ComponentNamespace}); err != nil {
+		if runtime.IsNotRegisteredError(err) || meta.IsNoMatchError(err) {
+			return map[string]string{}, nil
+		}
+		return nil, errors.Wrap(err, "cannot list baremetal hosts")
+	}
+
+	hosts := map[string]string{}
+	for _, machine := range machines.Items {
+		if machine.Status.NodeRef == nil || machine.Status.NodeRef.Name == "" {
+			continue
+		}
+		bmhName := getHostByMachine(machine.ObjectMeta)
+		if bmhName == "" {
+			for _, bmh := range bmhl.Items {
+				if getMachineByHost(bmh.Name, bmh.Spec.ConsumerRef) == machine.Name {
+					bmhName = bmh.Name
+					break
+				}
+			}
+		}
+		if bmhName != "" {
+			hosts[machine.Status.NodeRef.Name] = bmhName
+		}
+	}
+	return hosts, nil
+}
+
+// getProvisioningInterfaceStatus looks for a NIC in the hardware inventory
+// of a node matching either the provisioning interface name or one of the
+// provisioning MAC addresses.
+func getProvisioningInterfaceStatus(nodeName string, hardwareData *baremetalv1alpha1.HardwareData, config *metal3iov1alpha1.ProvisioningSpec) metal3iov1alpha1.ProvisioningInterfaceStatus {
+	status := metal3iov1alpha1.ProvisioningInterfaceStatus{
+		NodeName: nodeName,
+		State:    metal3iov1alpha1.ProvisioningInterfaceUnknown,
+	}
+
+	if hardwareData == nil || hardwareData.Spec.HardwareDetails == nil {
+		status.Message = "no hardware inventory available for this node"
+		return status
+	}
+
+	// With a provisioning VLAN the interface to look for is its parent.
+	// Bonds are not part of the hardware inventory, so they can only be
+	// matched through the MAC addresses of their members.
+	interfaceName := config.ProvisioningInterface
+	if config.ProvisioningVLANID != 0 {
+		interfaceName = config.ProvisioningVLANParentInterface
+	}
+	if interfaceName == "" && len(config.ProvisioningMacAddresses) == 0 {
+		status.Message = "neither the provisioning interface nor provisioningMacAddresses is set"
+		return status
+	}
+
+	for _, nic := range hardwareData.Spec.HardwareDetails.NIC {
+		if interfaceName != "" && nic.Name == interfaceName {
+			status.State = metal3iov1alpha1.ProvisioningInterfaceFound
+			status.Interface = nic.Name
+			status.MACAddress = nic.MAC
+			return status
+		}
+		for _, mac := range config.ProvisioningMacAddresses {
+			if nic.MAC != "" && strings.EqualFold(nic.MAC, mac) {
+				status.State = metal3iov1alpha1.ProvisioningInterfaceFound
+				status.Interface = nic.Name
+				status.MACAddress = nic.MAC
+				return status
+			}
+		}
+	}
+
+	if config.ProvisioningVLANID != 0 && len(config.ProvisioningMacAddresses) == 0 {
+		status.Message = fmt.Sprintf("VLAN parent interface %q cannot be checked without provisioningMacAddresses", interfaceName)
+		return status
+	}
+
+	status.State = metal3iov1alpha1.ProvisioningInterfaceMissing
+	status.Message = fmt.Sprintf("no network interface named %q or with one of the MAC addresses [%s]",
+		interfaceName, strings.Join(config.ProvisioningMacAddresses, ", "))
+	return status
+}
+
+// checkProvisioningInterfaces verifies that the master nodes have a network
+// interface usable for the provisioning network, and records the result in
+// the status of the Provisioning CR, which the caller writes. It returns the
+// names of the nodes that are known to have no such interface.
+func (r *ProvisioningReconciler) checkProvisioningInterfaces(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning) ([]string, error) {
+	var statuses []metal3iov1alpha1.ProvisioningInterfaceStatus
+	var unusable []string
+
+	// Without a provisioning network, there is no interface to configure.
+	if provConfig.Spec.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled {
+		nodes := corev1.NodeList{}
+		if err := r.Client.List(ctx, &nodes, client.HasLabels{masterNodeRoleLabel}); err != nil {
+			return nil, errors.Wrap(err, "cannot list master nodes")
+		}
+
+		hosts, err := r.masterHostsByNode(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, node := range nodes.Items {
+			var hardwareData *baremetalv1alpha1.HardwareData
+			if bmhName, ok := hosts[node.Name]; ok {
+				hardwareData = &baremetalv1alpha1.HardwareData{}
+				err := r.Client.Get(ctx, types.NamespacedName{Name: bmhName, Namespace: ComponentNamespace}, hardwareData)
+				if err != nil {
+					if !apierrors.IsNotFound(err) && !runtime.IsNotRegisteredError(err) && !meta.IsNoMatchError(err) {
+						return nil, errors.Wrapf(err, "cannot read hardware data for host %s", bmhName)
+					}
+					hardwareData = nil
+				}
+			}
+
+			status := getProvisioningInterfaceStatus(node.Name, hardwareData, &provConfig.Spec)
+			if status.State == metal3iov1alpha1.ProvisioningInterfaceMissing {
+				klog.Warningf("node %s has no usable provisioning interface: %s", node.Name, status.Message)
+				unusable = append(unusable, node.Name)
+			}
+			statuses = append(statuses, status)
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].NodeName < statuses[j].NodeName })
+		sort.Strings(unusable)
+	}
+
+	provConfig.Status.ProvisioningInterfaces = statuses
+
+	return unusable, nil
+}
+
+// allNodesUnusable returns true when every checked node is known to have no
+// usable provisioning interface, in which case the metal3 pod cannot run.
+func allNodesUnusable(statuses []metal3iov1alpha1.ProvisioningInterfaceStatus) bool {
+	if len(statuses) == 0 {
+		return false
+	}
+	for _, status := range statuses {
+		if status.State != metal3iov1alpha1.ProvisioningInterfaceMissing {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func hardwareDataWithNICs(name string, nics ...baremetalv1alpha1.NIC) *baremetalv1alpha1.HardwareData {
+	return &baremetalv1alpha1.HardwareData{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ComponentNamespace},
+		Spec: baremetalv1alpha1.HardwareDataSpec{
+			HardwareDetails: &baremetalv1alpha1.HardwareDetails{NIC: nics},
+		},
+	}
+}
+
+func TestGetProvisioningInterfaceStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		hardwareData  *baremetalv1alpha1.HardwareData
+		spec          metal3iov1alpha1.ProvisioningSpec
+		expectedState metal3iov1alpha1.ProvisioningInterfaceState
+		expectedName  string
+	}{
+		{
+			name:          "NoHardwareData",
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningInterface: "eth1"},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceUnknown,
+		},
+		{
+			name:          "MatchByName",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:01"}, baremetalv1alpha1.NIC{Name: "eth1", MAC: "00:00:00:00:00:02"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningInterface: "eth1"},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceFound,
+			expectedName:  "eth1",
+		},
+		{
+			name:          "MatchByMACIgnoresCase",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "ens3", MAC: "00:3d:25:45:bf:e5"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningMacAddresses: []string{"00:3D:25:45:BF:E5"}},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceFound,
+			expectedName:  "ens3",
+		},
+		{
+			name:          "NoMatch",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:01"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningInterface: "eth1", ProvisioningMacAddresses: []string{"00:00:00:00:00:02"}},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceMissing,
+		},
+		{
+			name:          "VLANMatchesParent",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:01"}, baremetalv1alpha1.NIC{Name: "eth1", MAC: "00:00:00:00:00:02"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningInterface: "prov", ProvisioningVLANID: 100, ProvisioningVLANParentInterface: "eth1"},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceFound,
+			expectedName:  "eth1",
+		},
+		{
+			name:          "VLANOnBondWithoutMACs",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:01"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningVLANID: 100, ProvisioningVLANParentInterface: "bond0"},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceUnknown,
+		},
+		{
+			name:          "VLANOnBondMatchesMemberMAC",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:01"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{ProvisioningVLANID: 100, ProvisioningVLANParentInterface: "bond0", ProvisioningMacAddresses: []string{"00:00:00:00:00:01"}},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceFound,
+			expectedName:  "eth0",
+		},
+		{
+			name:          "NothingConfigured",
+			hardwareData:  hardwareDataWithNICs("host", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:01"}),
+			spec:          metal3iov1alpha1.ProvisioningSpec{},
+			expectedState: metal3iov1alpha1.ProvisioningInterfaceUnknown,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status := getProvisioningInterfaceStatus("master-0", tc.hardwareData, &tc.spec)
+			assert.Equal(t, "master-0", status.NodeName)
+			assert.Equal(t, tc.expectedState, status.State)
+			assert.Equal(t, tc.expectedName, status.Interface)
+		})
+	}
+}
+
+func TestCheckProvisioningInterfaces(t *testing.T) {
+	sc := setUpSchemeForReconciler()
+	masterNode := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{masterNodeRoleLabel: ""},
+		}}
+	}
+	masterMachine := func(name, host, node string) *machinev1beta1.Machine {
+		return &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   ComponentNamespace,
+				Labels:      map[string]string{"machine.openshift.io/cluster-api-machine-role": "master"},
+				Annotations: map[string]string{HostAnnotation: ComponentNamespace + "/" + host},
+			},
+			Status: machinev1beta1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: node},
+			},
+		}
+	}
+	host := func(name string) *baremetalv1alpha1.BareMetalHost {
+		return &baremetalv1alpha1.BareMetalHost{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ComponentNamespace}}
+	}
+
+	baremetalCR := &metal3iov1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+		Spec: metal3iov1alpha1.ProvisioningSpec{
+			ProvisioningNetwork:   metal3iov1alpha1.ProvisioningNetworkManaged,
+			ProvisioningInterface: "eth1",
+		},
+	}
+	objects := []runtime.Object{
+		baremetalCR,
+		masterNode("master-0"), masterNode("master-1"), masterNode("master-2"),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}},
+		masterMachine("machine-0", "host-0", "master-0"),
+		masterMachine("machine-1", "host-1", "master-1"),
+		masterMachine("machine-2", "host-2", "master-2"),
+		host("host-0"), host("host-1"), host("host-2"),
+		hardwareDataWithNICs("host-0", baremetalv1alpha1.NIC{Name: "eth1", MAC: "00:00:00:00:00:01"}),
+		hardwareDataWithNICs("host-1", baremetalv1alpha1.NIC{Name: "eth0", MAC: "00:00:00:00:00:02"}),
+		// host-2 has not been inspected
+	}
+	r := &ProvisioningReconciler{
+		Scheme: sc,
+		Client: fakeclient.NewClientBuilder().WithScheme(sc).WithRuntimeObjects(objects...).
+			WithStatusSubresource(&metal3iov1alpha1.Provisioning{}).Build(),
+	}
+
+	unusable, err := r.checkProvisioningInterfaces(context.TODO(), baremetalCR)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master-1"}, unusable)
+	assert.Len(t, baremetalCR.Status.ProvisioningInterfaces, 3)
+	assert.Equal(t, metal3iov1alpha1.ProvisioningInterfaceFound, baremetalCR.Status.ProvisioningInterfaces[0].State)
+	assert.Equal(t, metal3iov1alpha1.ProvisioningInterfaceMissing, baremetalCR.Status.ProvisioningInterfaces[1].State)
+	assert.Equal(t, metal3iov1alpha1.ProvisioningInterfaceUnknown, baremetalCR.Status.ProvisioningInterfaces[2].State)
+	assert.False(t, allNodesUnusable(baremetalCR.Status.ProvisioningInterfaces))
+
+	// With the provisioning network disabled there is nothing to check.
+	baremetalCR.Spec.ProvisioningNetwork = metal3iov1alpha1.ProvisioningNetworkDisabled
+	unusable, err = r.checkProvisioningInterfaces(context.TODO(), baremetalCR)
+	assert.NoError(t, err)
+	assert.Empty(t, unusable)
+	assert.Empty(t, baremetalCR.Status.ProvisioningInterfaces)
+}
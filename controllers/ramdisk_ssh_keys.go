@@ -0,0 +1,107 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	"github.com/openshift/cluster-baremetal-operator/provisioning"
+)
+
+// ramdiskSSHKeySecretKey is the key of the Secret referenced by the
+// Provisioning spec holding the SSH keys authorized in the ramdisk.
+const ramdiskSSHKeySecretKey = "authorized_keys"
+
+func ramdiskSSHKeySecretName(spec *metal3iov1alpha1.ProvisioningSpec) string {
+	if spec.IronicAgent == nil || spec.IronicAgent.SSHKey == nil {
+		return ""
+	}
+	return spec.IronicAgent.SSHKey.SecretName
+}
+
+// readRamdiskSSHKeySecret returns the SSH keys of the Secret referenced by
+// the Provisioning spec, empty when there is none.
+func (r *ProvisioningReconciler) readRamdiskSSHKeySecret(ctx context.Context, spec *metal3iov1alpha1.ProvisioningSpec) (string, error) {
+	name := ramdiskSSHKeySecretName(spec)
+	// Remember the Secret to watch.
+	r.ramdiskSSHKeySecretName.Store(&name)
+	if name == "" {
+		return "", nil
+	}
+	secret, err := r.KubeClient.CoreV1().Secrets(ComponentNamespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("ramdisk SSH key Secret %s/%s not found", ComponentNamespace, name)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read the ramdisk SSH key Secret: %w", err)
+	}
+	return string(secret.Data[ramdiskSSHKeySecretKey]), nil
+}
+
+// ramdiskSSHKeys returns the SSH keys authorized in the ramdisk, the status
+// of the keys considered and how long until the debug SSH key expires. An
+// install-config that cannot be parsed is reported in the status rather than
+// failing.
+func (r *ProvisioningReconciler) ramdiskSSHKeys(ctx context.Context, provConfig *metal3iov1alpha1.Provisioning) (string, []metal3iov1alpha1.RamdiskSSHKeyStatus, time.Duration, error) {
+	var status []metal3iov1alpha1.RamdiskSSHKeyStatus
+	installConfigKey, err := r.readSSHKey(ctx)
+	var parseErr *installConfigParseError
+	if errors.As(err, &parseErr) {
+		klog.Warningf("ignoring the install-config SSH key: %v", err)
+		status = append(status, metal3iov1alpha1.RamdiskSSHKeyStatus{
+			Source: metal3iov1alpha1.RamdiskSSHKeySourceInstallConfig,
+			Error:  err.Error(),
+		})
+	} else if err != nil {
+		return "", nil, 0, err
+	}
+	secretKey, err := r.readRamdiskSSHKeySecret(ctx, &provConfig.Spec)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	keys, keysStatus, expiresIn := provisioning.RamdiskSSHKeys(provisioning.RamdiskSSHKeySources{
+		InstallConfig: installConfigKey,
+		Secret:        secretKey,
+	}, &provConfig.Spec, time.Now())
+	return keys, append(status, keysStatus...), expiresIn, nil
+}
+
+func isInstallConfig(object client.Object) bool {
+	return object.GetNamespace() == ClusterConfigNamespace && object.GetName() == ClusterConfigName
+}
+
+// ramdiskSSHKeySecretFilter filters the events of Secrets down to the one
+// referenced by the Provisioning spec for the ramdisk SSH key, as last seen
+// when reconciling. A newly referenced Secret is read by the reconcile
+// triggered by the change of the spec.
+func (r *ProvisioningReconciler) ramdiskSSHKeySecretFilter() predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		name := r.ramdiskSSHKeySecretName.Load()
+		return name != nil && *name != "" &&
+			object.GetNamespace() == ComponentNamespace && object.GetName() == *name
+	})
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+const (
+	testInstallConfigKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIF+aT/6HJ3ID9ZiClfzw1pFMcT3hjve5/EpS8Vj4Lk3t core"
+	testAdminKey         = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBKJ7R2UcnK55M0fkx5O+ehBaH6nw6U64DOYuvt6YxukNibIcmvFz8ODXOBqs0VWS1UN759kE+HVRpVlhLP/3SZg= admin"
+)
+
+func ramdiskSSHKeyProvisioning() *metal3iov1alpha1.Provisioning {
+	return &metal3iov1alpha1.Provisioning{
+		ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+		Spec: metal3iov1alpha1.ProvisioningSpec{
+			ProvisioningNetwork: metal3iov1alpha1.ProvisioningNetworkDisabled,
+			IronicAgent: &metal3iov1alpha1.IronicAgent{
+				SSHKey: &metal3iov1alpha1.RamdiskSSHKey{SecretName: "ramdisk-ssh-key"},
+			},
+		},
+	}
+}
+
+func TestRamdiskSSHKeySources(t *testing.T) {
+	provConfig := ramdiskSSHKeyProvisioning()
+	installConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ClusterConfigName, Namespace: ClusterConfigNamespace},
+		Data:       map[string]string{clusterConfigKey: "sshKey: " + testInstallConfigKey + "\n"},
+	}
+	kubeClient := fakekube.NewSimpleClientset(installConfig)
+	r := &ProvisioningReconciler{KubeClient: kubeClient}
+	installConfigStatus := metal3iov1alpha1.RamdiskSSHKeyStatus{
+		Fingerprint: "SHA256:TjBjmt80ujX2k/6eeDhjf5CgC0zXAqocHBcyzJuGFJ0",
+		Source:      metal3iov1alpha1.RamdiskSSHKeySourceInstallConfig,
+	}
+
+	// The referenced Secret does not exist yet.
+	key, status, _, err := r.ramdiskSSHKeys(context.TODO(), provConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, testInstallConfigKey, key)
+	assert.Equal(t, []metal3iov1alpha1.RamdiskSSHKeyStatus{installConfigStatus}, status)
+
+	// Day 2, more keys are managed in the Secret.
+	_, err = kubeClient.CoreV1().Secrets(ComponentNamespace).Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ramdisk-ssh-key", Namespace: ComponentNamespace},
+		Data:       map[string][]byte{ramdiskSSHKeySecretKey: []byte(testAdminKey + "\n")},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	key, status, _, err = r.ramdiskSSHKeys(context.TODO(), provConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, testAdminKey+"\n"+testInstallConfigKey, key)
+	assert.Equal(t, []metal3iov1alpha1.RamdiskSSHKeyStatus{
+		{Fingerprint: "SHA256:ZDC/I4bYJcNsp+74LFSMgljUi6zNC1rIpET7FdAdupc", Source: metal3iov1alpha1.RamdiskSSHKeySourceSecret},
+		installConfigStatus,
+	}, status)
+
+	// An unreadable install-config is reported without failing.
+	err = kubeClient.CoreV1().Secrets(ComponentNamespace).Delete(context.TODO(), "ramdisk-ssh-key", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	installConfig.Data[clusterConfigKey] = "sshKey: [unterminated"
+	_, err = kubeClient.CoreV1().ConfigMaps(ClusterConfigNamespace).Update(context.TODO(), installConfig, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	key, status, _, err = r.ramdiskSSHKeys(context.TODO(), provConfig)
+	assert.NoError(t, err)
+	assert.Empty(t, key)
+	if assert.Len(t, status, 1) {
+		assert.Equal(t, metal3iov1alpha1.RamdiskSSHKeySourceInstallConfig, status[0].Source)
+		assert.Contains(t, status[0].Error, "unable to parse the install-config")
+	}
+}
+
+func TestRamdiskSSHKeySourcesWatched(t *testing.T) {
+	r := &ProvisioningReconciler{KubeClient: fakekube.NewSimpleClientset()}
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	configMap := func(namespace, name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	filter := r.ramdiskSSHKeySecretFilter()
+	assert.False(t, filter.Create(event.CreateEvent{Object: secret(ComponentNamespace, "ramdisk-ssh-key")}), "no Secret referenced before reconciling")
+
+	_, err := r.readRamdiskSSHKeySecret(context.TODO(), &ramdiskSSHKeyProvisioning().Spec)
+	assert.NoError(t, err)
+	assert.True(t, filter.Update(event.UpdateEvent{ObjectOld: secret(ComponentNamespace, "ramdisk-ssh-key"), ObjectNew: secret(ComponentNamespace, "ramdisk-ssh-key")}))
+	assert.False(t, filter.Update(event.UpdateEvent{ObjectOld: secret(ComponentNamespace, "other"), ObjectNew: secret(ComponentNamespace, "other")}))
+	assert.False(t, filter.Create(event.CreateEvent{Object: secret("default", "ramdisk-ssh-key")}))
+
+	_, err = r.readRamdiskSSHKeySecret(context.TODO(), &metal3iov1alpha1.ProvisioningSpec{})
+	assert.NoError(t, err)
+	assert.False(t, filter.Create(event.CreateEvent{Object: secret(ComponentNamespace, "ramdisk-ssh-key")}), "the Secret is no longer referenced")
+
+	assert.True(t, isInstallConfig(configMap(ClusterConfigNamespace, ClusterConfigName)))
+	assert.False(t, isInstallConfig(configMap(ComponentNamespace, ClusterConfigName)))
+}
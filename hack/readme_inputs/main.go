@@ -75,7 +75,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	file, err := os.OpenFile(readmePath, os.O_RDWR, 0)
+	file, err := os.OpenFile(readmePath, os.O_RDWR|os.O_TRUNC, 0)
 	if err != nil {
 		log.Fatal(err)
 	}
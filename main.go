@@ -22,7 +22,10 @@ import (
 	"os"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/dynamic"
@@ -47,6 +50,7 @@ import (
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	osclientset "github.com/openshift/client-go/config/clientset/versioned"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+	metal3iov1beta1 "github.com/openshift/cluster-baremetal-operator/api/v1beta1"
 	"github.com/openshift/cluster-baremetal-operator/controllers"
 	"github.com/openshift/cluster-baremetal-operator/provisioning"
 	utiltls "github.com/openshift/controller-runtime-common/pkg/tls"
@@ -61,6 +65,7 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(metal3iov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(metal3iov1beta1.AddToScheme(scheme))
 	utilruntime.Must(osconfigv1.AddToScheme(scheme))
 	utilruntime.Must(machinev1beta1.AddToScheme(scheme))
 	utilruntime.Must(baremetalv1alpha1.AddToScheme(scheme))
@@ -152,6 +157,20 @@ func main() {
 				provisioning.OpenshiftConfigNamespace: {},
 			},
 			ByObject: map[client.Object]cache.ByObject{
+				// The install-config is also watched, for its SSH key.
+				&corev1.ConfigMap{}: {
+					Namespaces: map[string]cache.Config{
+						controllers.ComponentNamespace:        {},
+						provisioning.OpenshiftConfigNamespace: {},
+						controllers.ClusterConfigNamespace: {
+							FieldSelector: fields.OneTermEqualSelector("metadata.name", controllers.ClusterConfigName),
+						},
+					},
+				},
+				// Only the metal3 pod is watched, for its IPs.
+				&corev1.Pod{}: {
+					Label: labels.SelectorFromSet(provisioning.Metal3PodLabels()),
+				},
 				&unstructured.Unstructured{
 					Object: map[string]interface{}{
 						"apiVersion": "monitoring.coreos.com/v1",
@@ -240,8 +259,8 @@ func main() {
 			OSClient:      osClient,
 			ResourceCache: resourceCache,
 		}
-		if err = provisioning.EnableValidatingWebhook(info, mgr, enabledFeatures); err != nil {
-			klog.ErrorS(err, "problem enabling validating webhook")
+		if err = provisioning.EnableWebhooks(info, mgr, enabledFeatures); err != nil {
+			klog.ErrorS(err, "problem enabling webhooks")
 			os.Exit(1)
 		}
 	}
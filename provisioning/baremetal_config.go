@@ -18,8 +18,11 @@ package provisioning
 import (
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/ptr"
 
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
@@ -36,6 +39,8 @@ const (
 	provisioningIP                 = "PROVISIONING_IP"
 	provisioningInterface          = "PROVISIONING_INTERFACE"
 	provisioningMacAddresses       = "PROVISIONING_MACS"
+	provisioningVLANID             = "PROVISIONING_VLAN_ID"
+	provisioningVLANParent         = "PROVISIONING_VLAN_PARENT"
 	deployKernelUrl                = "DEPLOY_KERNEL_URL"
 	ironicEndpoint                 = "IRONIC_ENDPOINT"
 	httpPort                       = "HTTP_PORT"
@@ -44,6 +49,7 @@ const (
 	dhcpRange                      = "DHCP_RANGE"
 	gatewayIP                      = "GATEWAY_IP"
 	machineImageUrl                = "RHCOS_IMAGE_URL"
+	machineImageChecksumURL        = "RHCOS_IMAGE_CHECKSUM_URL"
 	ipOptions                      = "IP_OPTIONS"
 	bootIsoSource                  = "IRONIC_BOOT_ISO_SOURCE"
 	sendSensorData                 = "SEND_SENSOR_DATA"
@@ -93,6 +99,105 @@ func getProvisioningIP(config *metal3iov1alpha1.ProvisioningSpec) *string {
 	return nil
 }
 
+// getProvisioningVLANEnvVars returns the settings provisioning_vlan.sh needs
+// to create the provisioning VLAN subinterface. When no parent interface is
+// given, the parent is the interface matching PROVISIONING_MACS.
+func getProvisioningVLANEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	if config.ProvisioningVLANID == 0 {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  provisioningVLANID,
+			Value: strconv.Itoa(int(config.ProvisioningVLANID)),
+		},
+		{
+			Name:  provisioningVLANParent,
+			Value: config.ProvisioningVLANParentInterface,
+		},
+	}
+}
+
+// getProvisioningOSChecksumEnvVars returns the location of the checksum file
+// the downloader uses to verify the OS image when the checksum is not part
+// of ProvisioningOSDownloadURL.
+func getProvisioningOSChecksumEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	if config.ProvisioningOSChecksumURL == "" {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  machineImageChecksumURL,
+			Value: config.ProvisioningOSChecksumURL,
+		},
+	}
+}
+
+// getIronicTuningEnvVars returns the Ironic options overridden by the
+// IronicTuning settings. The Ironic container reads options from the
+// OS_<SECTION>__<OPTION> variables, only the ones below can be set.
+func getIronicTuningEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	tuning := config.IronicTuning
+	if tuning == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	for _, option := range []struct {
+		name  string
+		value int
+	}{
+		{"OS_CONDUCTOR__DEPLOY_CALLBACK_TIMEOUT", tuning.DeployCallbackTimeout},
+		{"OS_CONDUCTOR__CLEAN_CALLBACK_TIMEOUT", tuning.CleanCallbackTimeout},
+		{"OS_CONDUCTOR__SYNC_POWER_STATE_INTERVAL", tuning.PowerStateSyncInterval},
+		{"OS_CONDUCTOR__POWER_STATE_CHANGE_TIMEOUT", tuning.PowerStateChangeTimeout},
+		{"OS_CONDUCTOR__MAX_CONCURRENT_DEPLOY", tuning.MaxConcurrentDeploys},
+		{"OS_CONDUCTOR__MAX_CONCURRENT_CLEAN", tuning.MaxConcurrentCleans},
+	} {
+		if option.value > 0 {
+			envVars = append(envVars, corev1.EnvVar{Name: option.name, Value: strconv.Itoa(option.value)})
+		}
+	}
+	return envVars
+}
+
+// getCleaningPolicyEnvVars returns the Ironic options implementing the
+// cleaning policy. The metadata cleaning is the Ironic default. The
+// baremetal-operator enables or disables the automated cleaning of each
+// node from its host, so it is not set here.
+func getCleaningPolicyEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	policy := config.CleaningPolicy
+	if policy == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	if policy.Mode == metal3iov1alpha1.CleaningModeFull {
+		// The full erase replaces the metadata one.
+		envVars = append(envVars,
+			corev1.EnvVar{Name: "OS_DEPLOY__ERASE_DEVICES_PRIORITY", Value: "10"},
+			corev1.EnvVar{Name: "OS_DEPLOY__ERASE_DEVICES_METADATA_PRIORITY", Value: "0"},
+		)
+		if len(policy.EraseMethods) > 0 {
+			allowed := sets.New(policy.EraseMethods...)
+			envVars = append(envVars,
+				corev1.EnvVar{Name: "OS_DEPLOY__ENABLE_ATA_SECURE_ERASE", Value: strconv.FormatBool(allowed.Has(metal3iov1alpha1.EraseMethodATASecureErase))},
+				corev1.EnvVar{Name: "OS_DEPLOY__ENABLE_NVME_SECURE_ERASE", Value: strconv.FormatBool(allowed.Has(metal3iov1alpha1.EraseMethodNVMeSecureErase))},
+			)
+			if !allowed.Has(metal3iov1alpha1.EraseMethodOverwrite) {
+				// Without overwriting, the disks that cannot be securely
+				// erased fail the cleaning.
+				envVars = append(envVars,
+					corev1.EnvVar{Name: "OS_DEPLOY__SHRED_RANDOM_OVERWRITE_ITERATIONS", Value: "0"},
+					corev1.EnvVar{Name: "OS_DEPLOY__SHRED_FINAL_OVERWRITE_WITH_ZEROS", Value: "false"},
+					corev1.EnvVar{Name: "OS_DEPLOY__CONTINUE_IF_DISK_SECURE_ERASE_FAILS", Value: "false"},
+				)
+			}
+		}
+	}
+	return envVars
+}
+
 func getDeployKernelUrl() *string {
 	deployKernelUrl := fmt.Sprintf("file://%s/%s", imageSharedDir, baremetalKernelSubPath)
 	return &deployKernelUrl
@@ -141,7 +246,7 @@ func getMetal3DeploymentConfig(name string, baremetalConfig *metal3iov1alpha1.Pr
 	case provisioningIP:
 		return getProvisioningIP(baremetalConfig)
 	case provisioningInterface:
-		return &baremetalConfig.ProvisioningInterface
+		return ptr.To(baremetalConfig.ProvisioningVLANInterface())
 	case provisioningMacAddresses:
 		return ptr.To(strings.Join(baremetalConfig.ProvisioningMacAddresses, ","))
 	case deployKernelUrl:
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
 
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
@@ -46,6 +47,24 @@ func TestGetMetal3DeploymentConfig(t *testing.T) {
 			spec:          managedProvisioning().build(),
 			expectedValue: ptr.To("eth0"),
 		},
+		{
+			name:          "Managed VLAN ProvisioningInterface",
+			configName:    provisioningInterface,
+			spec:          managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "bond0").build(),
+			expectedValue: ptr.To("bond0.100"),
+		},
+		{
+			name:          "Managed VLAN named ProvisioningInterface",
+			configName:    provisioningInterface,
+			spec:          managedProvisioning().ProvisioningVLAN(100, "bond0").build(),
+			expectedValue: ptr.To("eth0"),
+		},
+		{
+			name:          "Managed VLAN without parent ProvisioningInterface",
+			configName:    provisioningInterface,
+			spec:          managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "").build(),
+			expectedValue: ptr.To("vlan100"),
+		},
 		{
 			name:          "Unmanaged ProvisioningIPCIDR",
 			configName:    provisioningIP,
@@ -290,11 +309,190 @@ func (pb *provisioningBuilder) WatchAllNamespaces(value bool) *provisioningBuild
 	return pb
 }
 
+func (pb *provisioningBuilder) ProvisioningVLAN(id int32, parent string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningVLANID = id
+	pb.ProvisioningSpec.ProvisioningVLANParentInterface = parent
+	return pb
+}
+
 func (pb *provisioningBuilder) ExternalIPs(value []string) *provisioningBuilder {
 	pb.ProvisioningSpec.ExternalIPs = value
 	return pb
 }
 
+func (pb *provisioningBuilder) ProvisioningOSChecksumURL(value string) *provisioningBuilder {
+	pb.ProvisioningSpec.ProvisioningOSChecksumURL = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicProxy(value metal3iov1alpha1.IronicProxyMode) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicProxy = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicTuning(value *metal3iov1alpha1.IronicTuning) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicTuning = value
+	return pb
+}
+
+func (pb *provisioningBuilder) CleaningPolicy(value *metal3iov1alpha1.CleaningPolicy) *provisioningBuilder {
+	pb.ProvisioningSpec.CleaningPolicy = value
+	return pb
+}
+
+func (pb *provisioningBuilder) Inspection(value *metal3iov1alpha1.Inspection) *provisioningBuilder {
+	pb.ProvisioningSpec.Inspection = value
+	return pb
+}
+
+func (pb *provisioningBuilder) IronicAgent(value *metal3iov1alpha1.IronicAgent) *provisioningBuilder {
+	pb.ProvisioningSpec.IronicAgent = value
+	return pb
+}
+
+func (pb *provisioningBuilder) AdditionalImages(images ...metal3iov1alpha1.AdditionalImage) *provisioningBuilder {
+	pb.ProvisioningSpec.ImageCache = &metal3iov1alpha1.ImageCache{AdditionalImages: images}
+	return pb
+}
+
+func TestGetProvisioningOSChecksumEnvVars(t *testing.T) {
+	tCases := []struct {
+		name     string
+		spec     *metal3iov1alpha1.ProvisioningSpec
+		expected []corev1.EnvVar
+	}{
+		{
+			name: "Checksum in URL",
+			spec: managedProvisioning().build(),
+		},
+		{
+			name: "Checksum file",
+			spec: managedProvisioning().ProvisioningOSChecksumURL("http://172.22.0.1/images/SHA256SUMS").build(),
+			expected: []corev1.EnvVar{
+				{Name: "RHCOS_IMAGE_CHECKSUM_URL", Value: "http://172.22.0.1/images/SHA256SUMS"},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getProvisioningOSChecksumEnvVars(tc.spec))
+		})
+	}
+}
+
+func TestGetIronicTuningEnvVars(t *testing.T) {
+	tCases := []struct {
+		name     string
+		spec     *metal3iov1alpha1.ProvisioningSpec
+		expected []corev1.EnvVar
+	}{
+		{
+			name: "No tuning",
+			spec: managedProvisioning().build(),
+		},
+		{
+			name: "Some settings",
+			spec: managedProvisioning().IronicTuning(&metal3iov1alpha1.IronicTuning{
+				DeployCallbackTimeout:  3600,
+				PowerStateSyncInterval: 300,
+				MaxConcurrentDeploys:   100,
+			}).build(),
+			expected: []corev1.EnvVar{
+				{Name: "OS_CONDUCTOR__DEPLOY_CALLBACK_TIMEOUT", Value: "3600"},
+				{Name: "OS_CONDUCTOR__SYNC_POWER_STATE_INTERVAL", Value: "300"},
+				{Name: "OS_CONDUCTOR__MAX_CONCURRENT_DEPLOY", Value: "100"},
+			},
+		},
+		{
+			name: "All settings",
+			spec: managedProvisioning().IronicTuning(&metal3iov1alpha1.IronicTuning{
+				DeployCallbackTimeout:   3600,
+				CleanCallbackTimeout:    7200,
+				PowerStateSyncInterval:  300,
+				PowerStateChangeTimeout: 120,
+				MaxConcurrentDeploys:    100,
+				MaxConcurrentCleans:     50,
+			}).build(),
+			expected: []corev1.EnvVar{
+				{Name: "OS_CONDUCTOR__DEPLOY_CALLBACK_TIMEOUT", Value: "3600"},
+				{Name: "OS_CONDUCTOR__CLEAN_CALLBACK_TIMEOUT", Value: "7200"},
+				{Name: "OS_CONDUCTOR__SYNC_POWER_STATE_INTERVAL", Value: "300"},
+				{Name: "OS_CONDUCTOR__POWER_STATE_CHANGE_TIMEOUT", Value: "120"},
+				{Name: "OS_CONDUCTOR__MAX_CONCURRENT_DEPLOY", Value: "100"},
+				{Name: "OS_CONDUCTOR__MAX_CONCURRENT_CLEAN", Value: "50"},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getIronicTuningEnvVars(tc.spec))
+		})
+	}
+}
+
+func TestGetCleaningPolicyEnvVars(t *testing.T) {
+	tCases := []struct {
+		name     string
+		spec     *metal3iov1alpha1.ProvisioningSpec
+		expected []corev1.EnvVar
+	}{
+		{
+			name: "No cleaning policy",
+			spec: managedProvisioning().build(),
+		},
+		{
+			name: "Metadata cleaning",
+			spec: managedProvisioning().CleaningPolicy(&metal3iov1alpha1.CleaningPolicy{
+				Mode: metal3iov1alpha1.CleaningModeMetadata,
+			}).build(),
+		},
+		{
+			name: "Full cleaning with any method",
+			spec: managedProvisioning().CleaningPolicy(&metal3iov1alpha1.CleaningPolicy{
+				Mode: metal3iov1alpha1.CleaningModeFull,
+			}).build(),
+			expected: []corev1.EnvVar{
+				{Name: "OS_DEPLOY__ERASE_DEVICES_PRIORITY", Value: "10"},
+				{Name: "OS_DEPLOY__ERASE_DEVICES_METADATA_PRIORITY", Value: "0"},
+			},
+		},
+		{
+			name: "Full cleaning with secure erase only",
+			spec: managedProvisioning().CleaningPolicy(&metal3iov1alpha1.CleaningPolicy{
+				Mode:         metal3iov1alpha1.CleaningModeFull,
+				EraseMethods: []metal3iov1alpha1.EraseMethod{metal3iov1alpha1.EraseMethodNVMeSecureErase},
+			}).build(),
+			expected: []corev1.EnvVar{
+				{Name: "OS_DEPLOY__ERASE_DEVICES_PRIORITY", Value: "10"},
+				{Name: "OS_DEPLOY__ERASE_DEVICES_METADATA_PRIORITY", Value: "0"},
+				{Name: "OS_DEPLOY__ENABLE_ATA_SECURE_ERASE", Value: "false"},
+				{Name: "OS_DEPLOY__ENABLE_NVME_SECURE_ERASE", Value: "true"},
+				{Name: "OS_DEPLOY__SHRED_RANDOM_OVERWRITE_ITERATIONS", Value: "0"},
+				{Name: "OS_DEPLOY__SHRED_FINAL_OVERWRITE_WITH_ZEROS", Value: "false"},
+				{Name: "OS_DEPLOY__CONTINUE_IF_DISK_SECURE_ERASE_FAILS", Value: "false"},
+			},
+		},
+		{
+			name: "Full cleaning with overwrite only",
+			spec: managedProvisioning().CleaningPolicy(&metal3iov1alpha1.CleaningPolicy{
+				Mode:         metal3iov1alpha1.CleaningModeFull,
+				EraseMethods: []metal3iov1alpha1.EraseMethod{metal3iov1alpha1.EraseMethodOverwrite},
+			}).build(),
+			expected: []corev1.EnvVar{
+				{Name: "OS_DEPLOY__ERASE_DEVICES_PRIORITY", Value: "10"},
+				{Name: "OS_DEPLOY__ERASE_DEVICES_METADATA_PRIORITY", Value: "0"},
+				{Name: "OS_DEPLOY__ENABLE_ATA_SECURE_ERASE", Value: "false"},
+				{Name: "OS_DEPLOY__ENABLE_NVME_SECURE_ERASE", Value: "false"},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getCleaningPolicyEnvVars(tc.spec))
+		})
+	}
+}
+
 func TestWatchAllNamespaces(t *testing.T) {
 	tCases := []struct {
 		name          string
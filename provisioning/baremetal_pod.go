@@ -17,7 +17,9 @@ package provisioning
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -168,6 +170,7 @@ var metal3Volumes = []corev1.Volume{
 	ironicAgentPullSecretVolume(),
 	caTrustDirVolume(),
 	mirrorConfigVolume(),
+	imageCacheImagesVolume(),
 	{
 		Name: ironicCredentialsVolume,
 		VolumeSource: corev1.VolumeSource{
@@ -245,15 +248,67 @@ func buildEnvVar(name string, baremetalProvisioningConfig *metal3iov1alpha1.Prov
 
 func getKernelParams(config *metal3iov1alpha1.ProvisioningSpec, networkStack NetworkStackType) string {
 	// OCPBUGS-872: workaround for https://bugzilla.redhat.com/show_bug.cgi?id=2111675
-	return fmt.Sprintf("rd.net.timeout.carrier=30 %s",
-		IpOptionForProvisioning(config, networkStack))
+	params := []string{
+		"rd.net.timeout.carrier=30",
+		IpOptionForProvisioning(config, networkStack),
+	}
+	if inspection := config.Inspection; inspection != nil {
+		if len(inspection.Collectors) > 0 {
+			collectors := []string{"default"}
+			for _, collector := range inspection.Collectors {
+				collectors = append(collectors, string(collector))
+			}
+			params = append(params, "ipa-inspection-collectors="+strings.Join(collectors, ","))
+		}
+		if inspection.CollectLLDP {
+			params = append(params, "ipa-collect-lldp=1")
+		}
+	}
+	if agentParams := getIronicAgentKernelParams(config); agentParams != "" {
+		params = append(params, agentParams)
+	}
+	return strings.Join(params, " ")
+}
+
+// getInspectionEnvVars returns the Ironic options of the inspection: the
+// hooks processing the inspection data, the LLDP data needing its own, and
+// the kernel parameters only added when the ramdisk boots for inspection.
+func getInspectionEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	inspection := config.Inspection
+	if inspection == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	if len(inspection.Hooks) > 0 || inspection.CollectLLDP {
+		hooks := []string{"$default_hooks"}
+		for _, hook := range inspection.Hooks {
+			hooks = append(hooks, string(hook))
+		}
+		if inspection.CollectLLDP {
+			hooks = append(hooks, "parse-lldp", "local-link-connection")
+		}
+		envVars = append(envVars, corev1.EnvVar{Name: "OS_INSPECTOR__HOOKS", Value: strings.Join(hooks, ",")})
+	}
+	if params := strings.Fields(inspection.ExtraKernelParams); len(params) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "OS_INSPECTOR__EXTRA_KERNEL_PARAMS", Value: strings.Join(params, " ")})
+	}
+	return envVars
 }
 
+// setIronicExternalIp passes the IPv4 external IP to Ironic, the IPv6 one
+// being handled by setIronicExternalIPv6. On IPv6-only clusters, the IPv6
+// external IP is used instead.
 func setIronicExternalIp(name string, config *metal3iov1alpha1.ProvisioningSpec) corev1.EnvVar {
-	if len(config.ExternalIPs) > 0 {
+	if ip := getExternalIP(config.ExternalIPs, false); ip != "" {
 		return corev1.EnvVar{
 			Name:  name,
-			Value: config.ExternalIPs[0],
+			Value: ip,
+		}
+	} else if ip := getExternalIP(config.ExternalIPs, true); ip != "" {
+		return corev1.EnvVar{
+			Name:  name,
+			Value: ip,
 		}
 	} else if config.ProvisioningNetwork != metal3iov1alpha1.ProvisioningNetworkDisabled && config.VirtualMediaViaExternalNetwork {
 		return corev1.EnvVar{
@@ -288,25 +343,29 @@ func setIronicExternalIPv6(info *ProvisioningInfo) (corev1.EnvVar, error) {
 		}, err
 	}
 
-	// protocol, host, port
-	urlTemplate := "%s://[%s]:%s"
+	return corev1.EnvVar{
+		Name:  externalUrlEnvVar,
+		Value: getVirtualMediaURL(ironicIPv6, &info.ProvConfig.Spec),
+	}, nil
+}
 
-	if info.ProvConfig.Spec.DisableVirtualMediaTLS {
-		return corev1.EnvVar{
-			Name:  externalUrlEnvVar,
-			Value: fmt.Sprintf(urlTemplate, "http", ironicIPv6, baremetalHttpPort),
-		}, nil
-	} else {
-		return corev1.EnvVar{
-			Name:  externalUrlEnvVar,
-			Value: fmt.Sprintf(urlTemplate, "https", ironicIPv6, baremetalVmediaHttpsPort),
-		}, nil
+// getVirtualMediaURL returns the URL under which the httpd container serves
+// virtual media images on the given IP.
+func getVirtualMediaURL(ip string, config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.DisableVirtualMediaTLS {
+		return fmt.Sprintf("http://%s", net.JoinHostPort(ip, baremetalHttpPort))
 	}
+	return fmt.Sprintf("https://%s", net.JoinHostPort(ip, baremetalVmediaHttpsPort))
 }
 
 func newMetal3InitContainers(info *ProvisioningInfo) []corev1.Container {
 	initContainers := []corev1.Container{}
 
+	// The provisioning VLAN has to exist before its static IP is set.
+	if info.ProvConfig.Spec.ProvisioningVLANID != 0 {
+		initContainers = append(initContainers, createInitContainerProvisioningVLAN(info.Images, &info.ProvConfig.Spec))
+	}
+
 	// If the provisioning network is disabled, and the user hasn't requested a
 	// particular provisioning IP on the machine CIDR, we have nothing for this container
 	// to manage.
@@ -319,7 +378,9 @@ func newMetal3InitContainers(info *ProvisioningInfo) []corev1.Container {
 
 	// If the ProvisioningOSDownloadURL is set, we download the URL specified in it
 	if info.ProvConfig.Spec.ProvisioningOSDownloadURL != "" {
-		initContainers = append(initContainers, createInitContainerMachineOsDownloader(info, info.ProvConfig.Spec.ProvisioningOSDownloadURL, false, true))
+		downloader := createInitContainerMachineOsDownloader(info, info.ProvConfig.Spec.ProvisioningOSDownloadURL, false, true)
+		downloader.Env = append(downloader.Env, getProvisioningOSChecksumEnvVars(&info.ProvConfig.Spec)...)
+		initContainers = append(initContainers, downloader)
 	}
 
 	return injectProxyAndCA(initContainers, info.Proxy)
@@ -374,6 +435,39 @@ func createInitContainerMachineOsDownloader(info *ProvisioningInfo, imageURLs st
 	return initContainer
 }
 
+// provisioningVLANScript creates the provisioning VLAN subinterface on the
+// node.
+//
+//go:embed provisioning_vlan.sh
+var provisioningVLANScript string
+
+func createInitContainerProvisioningVLAN(images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
+	return corev1.Container{
+		Name:            "metal3-provisioning-vlan",
+		Image:           images.StaticIpManager,
+		Command:         []string{"/bin/bash", "-c", provisioningVLANScript},
+		ImagePullPolicy: "IfNotPresent",
+		SecurityContext: &corev1.SecurityContext{
+			ReadOnlyRootFilesystem: ptr.To(true),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+				Add:  []corev1.Capability{"NET_ADMIN"},
+			},
+		},
+		Env: append([]corev1.EnvVar{
+			buildEnvVar(provisioningInterface, config),
+			buildEnvVar(provisioningMacAddresses, config),
+		}, getProvisioningVLANEnvVars(config)...),
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
+
 func createInitContainerStaticIpSet(images *Images, config *metal3iov1alpha1.ProvisioningSpec) corev1.Container {
 	initContainer := corev1.Container{
 		Name:            "metal3-static-ip-set",
@@ -427,6 +521,10 @@ func newMetal3Containers(info *ProvisioningInfo) []corev1.Container {
 		containers = append(containers, createContainerIronicPrometheusExporter(info.Images))
 	}
 
+	if hasAdditionalImages(&info.ProvConfig.Spec) {
+		containers = append(containers, createContainerMetal3ImagePrefetcher(info.Images))
+	}
+
 	return injectProxyAndCA(containers, info.Proxy)
 }
 
@@ -513,13 +611,10 @@ func createContainerMetal3Httpd(images *Images, info *ProvisioningInfo) corev1.C
 	port, _ := strconv.Atoi(baremetalHttpPort)             // #nosec
 	httpsPort, _ := strconv.Atoi(baremetalVmediaHttpsPort) // #nosec
 
-	ironicPort := baremetalIronicPort
 	// In the proxy mode, the ironic API is served on the private port,
 	// while ironic-proxy, running as a DeamonSet on all nodes, serves on
 	// 6385 and proxies the traffic.
-	if UseIronicProxy(info) {
-		ironicPort = ironicPrivatePort
-	}
+	ironicPort := getControlPlanePort(info)
 	config := &info.ProvConfig.Spec
 
 	volumeMounts := []corev1.VolumeMount{
@@ -679,6 +774,10 @@ func createContainerMetal3Ironic(images *Images, info *ProvisioningInfo, config
 		)
 	}
 
+	container.Env = append(container.Env, getIronicTuningEnvVars(config)...)
+	container.Env = append(container.Env, getCleaningPolicyEnvVars(config)...)
+	container.Env = append(container.Env, getInspectionEnvVars(config)...)
+
 	if info.TLSProfileSpec != nil {
 		container.Env = append(container.Env, tlsProfileToApacheEnvVars(*info.TLSProfileSpec)...)
 	}
@@ -812,6 +911,29 @@ func newMetal3PodTemplateSpec(info *ProvisioningInfo, labels *map[string]string)
 		nodeSelector = map[string]string{"node-role.kubernetes.io/master": ""}
 	}
 
+	var affinity *corev1.Affinity
+	if len(info.UnusableProvisioningNodes) > 0 {
+		// Keep the pod away from masters without a usable provisioning
+		// interface, the static IP manager and dnsmasq would fail there.
+		affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchFields: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "metadata.name",
+									Operator: corev1.NodeSelectorOpNotIn,
+									Values:   info.UnusableProvisioningNodes,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
 	annotations := make(map[string]string, len(podTemplateAnnotations)+1)
 	for k, v := range podTemplateAnnotations {
 		annotations[k] = v
@@ -833,6 +955,7 @@ func newMetal3PodTemplateSpec(info *ProvisioningInfo, labels *map[string]string)
 			DNSPolicy:         corev1.DNSClusterFirstWithHostNet,
 			PriorityClassName: "system-node-critical",
 			NodeSelector:      nodeSelector,
+			Affinity:          affinity,
 			SecurityContext: &corev1.PodSecurityContext{
 				RunAsNonRoot: ptr.To(false),
 			},
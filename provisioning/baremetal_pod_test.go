@@ -152,6 +152,28 @@ func TestNewMetal3InitContainers(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "provisioning VLAN",
+			config: managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "bond0").build(),
+			expectedContainers: []corev1.Container{
+				{
+					Name:  "metal3-provisioning-vlan",
+					Image: images.StaticIpManager,
+				},
+				{
+					Name:  "metal3-static-ip-set",
+					Image: images.StaticIpManager,
+				},
+				{
+					Name:  "machine-os-images",
+					Image: images.MachineOSImages,
+				},
+				{
+					Name:  "metal3-machine-os-downloader",
+					Image: images.MachineOsDownloader,
+				},
+			},
+		},
 		{
 			name:   "valid config with pre provisioning os download urls set",
 			config: configWithPreProvisioningOSDownloadURLs().build(),
@@ -177,6 +199,9 @@ func TestNewMetal3InitContainers(t *testing.T) {
 			info := &ProvisioningInfo{Images: &images, ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.config}}
 			actualContainers := newMetal3InitContainers(info)
 			assert.Equal(t, len(tc.expectedContainers), len(actualContainers), fmt.Sprintf("%s : Expected number of Init Containers : %d Actual number of Init Containers : %d", tc.name, len(tc.expectedContainers), len(actualContainers)))
+			for i := range min(len(tc.expectedContainers), len(actualContainers)) {
+				assert.Equal(t, tc.expectedContainers[i].Name, actualContainers[i].Name)
+			}
 		})
 	}
 }
@@ -422,6 +447,38 @@ func TestNewMetal3Containers(t *testing.T) {
 			},
 			sshkey: "sshkey",
 		},
+		{
+			name:   "ManagedSpec with additional images",
+			config: managedProvisioning().AdditionalImages(metal3iov1alpha1.AdditionalImage{URL: "http://example.com/rhcos-414.qcow2.gz"}).build(),
+			expectedContainers: []corev1.Container{
+				withTLSEnv(containers["metal3-httpd"], sshkey),
+				withTLSEnv(containers["metal3-ironic"], sshkey),
+				containers["metal3-ramdisk-logs"],
+				containers["metal3-static-ip-manager"],
+				containers["metal3-dnsmasq"],
+				{
+					Name: "metal3-image-prefetcher",
+					Env: []corev1.EnvVar{
+						envWithValue("IMAGE_CACHE_FETCH_ONLY", "true"),
+						envWithValue("IMAGE_CACHE_ADDITIONAL_IMAGES", "/etc/metal3-image-cache/images"),
+						envWithValue("IMAGE_CACHE_INTERVAL", "60"),
+					},
+				},
+			},
+			sshkey: "sshkey",
+		},
+		{
+			name:   "ManagedSpecWithVLAN",
+			config: managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "bond0").build(),
+			expectedContainers: []corev1.Container{
+				withTLSEnv(containers["metal3-httpd"], envWithValue("PROVISIONING_INTERFACE", "bond0.100")),
+				withTLSEnv(containers["metal3-ironic"], envWithValue("PROVISIONING_INTERFACE", "bond0.100")),
+				containers["metal3-ramdisk-logs"],
+				withEnv(containers["metal3-static-ip-manager"], envWithValue("PROVISIONING_INTERFACE", "bond0.100")),
+				withEnv(containers["metal3-dnsmasq"], envWithValue("PROVISIONING_INTERFACE", "bond0.100")),
+			},
+			sshkey: "",
+		},
 		{
 			name:   "UnmanagedSpec",
 			config: unmanagedProvisioning().build(),
@@ -695,6 +752,71 @@ func TestProxyAndCAInjection(t *testing.T) {
 	}
 }
 
+func TestGetKernelParams(t *testing.T) {
+	tCases := []struct {
+		name        string
+		spec        *metal3iov1alpha1.ProvisioningSpec
+		expected    string
+		expectedEnv []corev1.EnvVar
+	}{
+		{
+			name:     "No inspection settings",
+			spec:     managedProvisioning().build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp",
+		},
+		{
+			name: "Collectors and extra parameters",
+			spec: managedProvisioning().Inspection(&metal3iov1alpha1.Inspection{
+				Collectors:        []metal3iov1alpha1.InspectionCollector{"extra-hardware", "numa-topology"},
+				ExtraKernelParams: " ipa-inspection-dhcp-all-interfaces=1  console=ttyS0 ",
+			}).build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp ipa-inspection-collectors=default,extra-hardware,numa-topology",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "OS_INSPECTOR__EXTRA_KERNEL_PARAMS", Value: "ipa-inspection-dhcp-all-interfaces=1 console=ttyS0"},
+			},
+		},
+		{
+			name: "LLDP and hooks",
+			spec: managedProvisioning().Inspection(&metal3iov1alpha1.Inspection{
+				CollectLLDP: true,
+				Hooks:       []metal3iov1alpha1.InspectionHook{"extra-hardware"},
+			}).build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp ipa-collect-lldp=1",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "OS_INSPECTOR__HOOKS", Value: "$default_hooks,extra-hardware,parse-lldp,local-link-connection"},
+			},
+		},
+		{
+			name: "Inspection and ramdisk parameters",
+			spec: managedProvisioning().Inspection(&metal3iov1alpha1.Inspection{
+				ExtraKernelParams: "ipa-inspection-dhcp-all-interfaces=1",
+			}).IronicAgent(&metal3iov1alpha1.IronicAgent{
+				ExtraKernelParams: "console=ttyS0  ipa-debug=1",
+			}).build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp console=ttyS0 ipa-debug=1",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "OS_INSPECTOR__EXTRA_KERNEL_PARAMS", Value: "ipa-inspection-dhcp-all-interfaces=1"},
+			},
+		},
+		{
+			name: "Hooks only",
+			spec: managedProvisioning().Inspection(&metal3iov1alpha1.Inspection{
+				Hooks: []metal3iov1alpha1.InspectionHook{"pci-devices", "accelerators"},
+			}).build(),
+			expected: "rd.net.timeout.carrier=30 ip=dhcp",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "OS_INSPECTOR__HOOKS", Value: "$default_hooks,pci-devices,accelerators"},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getKernelParams(tc.spec, NetworkStackV4))
+			assert.Equal(t, tc.expectedEnv, getInspectionEnvVars(tc.spec))
+		})
+	}
+}
+
 func TestSetIronicExternalIp(t *testing.T) {
 	tCases := []struct {
 		name           string
@@ -720,6 +842,15 @@ func TestSetIronicExternalIp(t *testing.T) {
 				Value: "fd2e:6f44:5dd8:b856::100",
 			},
 		},
+		{
+			name:       "Dual-stack ExternalIPs use the IPv4 address",
+			envVarName: externalIpEnvVar,
+			spec:       managedProvisioning().ExternalIPs([]string{"fd2e:6f44:5dd8:b856::100", "192.168.1.100"}).build(),
+			expectedEnvVar: corev1.EnvVar{
+				Name:  externalIpEnvVar,
+				Value: "192.168.1.100",
+			},
+		},
 		{
 			name:       "ExternalIP takes precedence over VirtualMediaViaExternalNetwork",
 			envVarName: externalIpEnvVar,
@@ -933,6 +1064,20 @@ func TestSetIronicExternalIPv6(t *testing.T) {
 	}
 }
 
+func TestCreateInitContainerProvisioningVLAN(t *testing.T) {
+	config := managedProvisioning().ProvisioningInterface("").ProvisioningVLAN(100, "").build()
+	config.ProvisioningMacAddresses = []string{"34:b3:2d:81:f8:fb", "34:b3:2d:81:f8:fc"}
+	container := createInitContainerProvisioningVLAN(&Images{StaticIpManager: expectedIronicStaticIpManager}, config)
+
+	assert.Equal(t, []string{"/bin/bash", "-c", provisioningVLANScript}, container.Command)
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "PROVISIONING_INTERFACE", Value: "vlan100"},
+		{Name: "PROVISIONING_MACS", Value: "34:b3:2d:81:f8:fb,34:b3:2d:81:f8:fc"},
+		{Name: "PROVISIONING_VLAN_ID", Value: "100"},
+		{Name: "PROVISIONING_VLAN_PARENT", Value: ""},
+	}, container.Env)
+}
+
 func TestCreateInitContainerMachineOsDownloader(t *testing.T) {
 	images := Images{
 		BaremetalOperator:   expectedBaremetalOperator,
@@ -1175,3 +1320,48 @@ func TestOperandImagesMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMetal3PodTemplateSpecAffinity(t *testing.T) {
+	tCases := []struct {
+		name          string
+		unusableNodes []string
+		expected      *corev1.Affinity
+	}{
+		{
+			name:     "all masters usable",
+			expected: nil,
+		},
+		{
+			name:          "some masters without provisioning interface",
+			unusableNodes: []string{"master-1", "master-2"},
+			expected: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchFields: []corev1.NodeSelectorRequirement{
+									{
+										Key:      "metadata.name",
+										Operator: corev1.NodeSelectorOpNotIn,
+										Values:   []string{"master-1", "master-2"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:                    &Images{},
+				ProvConfig:                &metal3iov1alpha1.Provisioning{Spec: *managedProvisioning().build()},
+				UnusableProvisioningNodes: tc.unusableNodes,
+			}
+			template := newMetal3PodTemplateSpec(info, &map[string]string{})
+			assert.Equal(t, tc.expected, template.Spec.Affinity)
+		})
+	}
+}
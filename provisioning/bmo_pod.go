@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	operatorv1 "github.com/openshift/api/operator/v1"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 	"github.com/openshift/library-go/pkg/operator/resource/resourcemerge"
@@ -31,6 +32,50 @@ const (
 	baremetalWebhookServiceLabel  = "metal3-validating-webhook"
 )
 
+// bmoLogLevels maps the log levels of the baremetal-operator to the values
+// of its --zap-log-level argument.
+var bmoLogLevels = map[operatorv1.LogLevel]string{
+	operatorv1.Normal:   "info",
+	operatorv1.Debug:    "debug",
+	operatorv1.Trace:    "2",
+	operatorv1.TraceAll: "5",
+}
+
+// bmoConfigArgs returns the arguments of the baremetal-operator from its
+// configuration.
+func bmoConfigArgs(config *metal3iov1alpha1.BaremetalOperator) []string {
+	if config == nil || config.LogLevel == "" {
+		return nil
+	}
+	return []string{"--zap-log-level", bmoLogLevels[config.LogLevel]}
+}
+
+// getLiveISOPersistentBootDevice returns whether the hosts booted from a live
+// ISO keep it as their persistent boot device, Never unless configured.
+func getLiveISOPersistentBootDevice(config *metal3iov1alpha1.BaremetalOperator) string {
+	if config != nil && config.LiveISOPersistentBootDevice != "" {
+		return string(config.LiveISOPersistentBootDevice)
+	}
+	return string(metal3iov1alpha1.PersistentBootDeviceNever)
+}
+
+// bmoConfigEnvVars returns the environment of the baremetal-operator from
+// its configuration, beyond the settings it always gets.
+func bmoConfigEnvVars(config *metal3iov1alpha1.BaremetalOperator) []corev1.EnvVar {
+	if config == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	if config.MaxConcurrentReconciles > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "BMO_CONCURRENCY", Value: strconv.Itoa(config.MaxConcurrentReconciles)})
+	}
+	if config.ProvisioningLimit > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "PROVISIONING_LIMIT", Value: strconv.Itoa(config.ProvisioningLimit)})
+	}
+	return envVars
+}
+
 var baremetalWebhookCertMount = corev1.VolumeMount{
 	Name:      baremetalWebhookCertVolume,
 	ReadOnly:  true,
@@ -39,6 +84,7 @@ var baremetalWebhookCertMount = corev1.VolumeMount{
 
 var bmoVolumes = []corev1.Volume{
 	trustedCAVolume(),
+	ironicURLsVolume(),
 	{
 		Name: baremetalWebhookCertVolume,
 		VolumeSource: corev1.VolumeSource{
@@ -71,11 +117,6 @@ var bmoVolumes = []corev1.Volume{
 
 func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container, error) {
 	webhookPort, _ := strconv.ParseInt(baremetalWebhookPort, 10, 32) // #nosec
-	externalIPv6Var, err := setIronicExternalIPv6(info)
-	if err != nil {
-		return corev1.Container{}, err
-	}
-
 	ironicURL := getControlPlaneEndpoint(info)
 
 	container := corev1.Container{
@@ -151,14 +192,15 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 			},
 			{
 				Name:  "LIVE_ISO_FORCE_PERSISTENT_BOOT_DEVICE",
-				Value: "Never",
+				Value: getLiveISOPersistentBootDevice(info.ProvConfig.Spec.BaremetalOperator),
 			},
 			{
 				Name:  "METAL3_AUTH_ROOT_DIR",
 				Value: metal3AuthRootDir,
 			},
 			setIronicExternalIp(externalIpEnvVar, &info.ProvConfig.Spec),
-			externalIPv6Var,
+			// May depend on the metal3 pod IPs
+			ironicURLsEnvVar(externalUrlEnvVar),
 			{
 				Name:  "PROVISIONING_NETWORK_DISABLED",
 				Value: strconv.FormatBool(info.ProvConfig.Spec.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled),
@@ -173,6 +215,9 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 	}
 
+	container.Args = append(container.Args, bmoConfigArgs(info.ProvConfig.Spec.BaremetalOperator)...)
+	container.Env = append(container.Env, bmoConfigEnvVars(info.ProvConfig.Spec.BaremetalOperator)...)
+
 	if info.TLSProfileSpec != nil {
 		container.Args = append(container.Args, tlsProfileToBMOArgs(*info.TLSProfileSpec)...)
 	}
@@ -185,7 +230,7 @@ func createContainerBaremetalOperator(info *ProvisioningInfo) (corev1.Container,
 		container.Args = append(container.Args, "--webhook-port", baremetalWebhookPort)
 	}
 
-	return container, nil
+	return withIronicURLsLivenessProbe(container), nil
 }
 
 func newBMOPodTemplateSpec(info *ProvisioningInfo, labels *map[string]string) (*corev1.PodTemplateSpec, error) {
@@ -10,6 +10,7 @@ import (
 	fakekube "k8s.io/client-go/kubernetes/fake"
 
 	osconfigv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
 	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
@@ -45,7 +46,7 @@ func TestNewBMOContainers(t *testing.T) {
 				{Name: "LIVE_ISO_FORCE_PERSISTENT_BOOT_DEVICE", Value: "Never"},
 				{Name: "METAL3_AUTH_ROOT_DIR", Value: "/auth"},
 				{Name: "IRONIC_EXTERNAL_IP", Value: ""},
-				{Name: "IRONIC_EXTERNAL_URL_V6", Value: ""},
+				ironicURLsEnvVar("IRONIC_EXTERNAL_URL_V6"),
 				{Name: "PROVISIONING_NETWORK_DISABLED", Value: "false"},
 			},
 		},
@@ -97,7 +98,6 @@ func TestNewBMOContainers(t *testing.T) {
 			expectedContainers: []corev1.Container{
 				withEnv(
 					containers["metal3-baremetal-operator"],
-					envWithValue("IRONIC_EXTERNAL_URL_V6", fmt.Sprintf("https://[%s]:6183", testProvisioningIPv6)),
 				),
 			},
 			sshkey: "sshkey",
@@ -109,7 +109,6 @@ func TestNewBMOContainers(t *testing.T) {
 				withEnv(
 					containers["metal3-baremetal-operator"],
 					envWithFieldValue("IRONIC_EXTERNAL_IP", "status.hostIP"),
-					envWithValue("IRONIC_EXTERNAL_URL_V6", "https://[fd2e:6f44:5dd8:c956::16]:6183"),
 					// Private ports because of the proxy
 					envWithValue("IRONIC_ENDPOINT", "https://metal3-state.openshift-machine-api.svc.cluster.local:6388/v1/"),
 				),
@@ -123,7 +122,6 @@ func TestNewBMOContainers(t *testing.T) {
 				withEnv(
 					containers["metal3-baremetal-operator"],
 					envWithValue("IRONIC_EXTERNAL_IP", ""),
-					envWithValue("IRONIC_EXTERNAL_URL_V6", "https://[fd2e:6f44:5dd8:c956::16]:6183"),
 					// Private ports because of the proxy
 					envWithValue("IRONIC_ENDPOINT", "https://metal3-state.openshift-machine-api.svc.cluster.local:6388/v1/"),
 					envWithValue("PROVISIONING_NETWORK_DISABLED", "true"),
@@ -205,6 +203,78 @@ func TestNewBMOContainers(t *testing.T) {
 	}
 }
 
+func TestBMOContainerConfig(t *testing.T) {
+	tCases := []struct {
+		name         string
+		config       *metal3iov1alpha1.BaremetalOperator
+		expectedArgs []string
+		expectedEnv  []corev1.EnvVar
+		absentEnv    []string
+	}{
+		{
+			name: "No configuration",
+			expectedEnv: []corev1.EnvVar{
+				{Name: "LIVE_ISO_FORCE_PERSISTENT_BOOT_DEVICE", Value: "Never"},
+			},
+			absentEnv: []string{"BMO_CONCURRENCY", "PROVISIONING_LIMIT"},
+		},
+		{
+			name: "Full configuration",
+			config: &metal3iov1alpha1.BaremetalOperator{
+				MaxConcurrentReconciles:     8,
+				ProvisioningLimit:           40,
+				LiveISOPersistentBootDevice: metal3iov1alpha1.PersistentBootDeviceAlways,
+				LogLevel:                    operatorv1.Debug,
+			},
+			expectedArgs: []string{"--zap-log-level", "debug"},
+			expectedEnv: []corev1.EnvVar{
+				{Name: "LIVE_ISO_FORCE_PERSISTENT_BOOT_DEVICE", Value: "Always"},
+				{Name: "BMO_CONCURRENCY", Value: "8"},
+				{Name: "PROVISIONING_LIMIT", Value: "40"},
+			},
+		},
+		{
+			name:         "Trace log level",
+			config:       &metal3iov1alpha1.BaremetalOperator{LogLevel: operatorv1.Trace},
+			expectedArgs: []string{"--zap-log-level", "2"},
+			expectedEnv: []corev1.EnvVar{
+				{Name: "LIVE_ISO_FORCE_PERSISTENT_BOOT_DEVICE", Value: "Never"},
+			},
+			absentEnv: []string{"BMO_CONCURRENCY", "PROVISIONING_LIMIT"},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := managedProvisioning().build()
+			spec.BaremetalOperator = tc.config
+			info := &ProvisioningInfo{
+				Namespace:  "openshift-machine-api",
+				Images:     &Images{BaremetalOperator: expectedBaremetalOperator},
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *spec},
+			}
+
+			container, err := createContainerBaremetalOperator(info)
+			assert.NoError(t, err)
+
+			if tc.expectedArgs == nil {
+				assert.NotContains(t, container.Args, "--zap-log-level")
+			}
+			for i, arg := range container.Args {
+				if arg == "--zap-log-level" {
+					assert.Equal(t, tc.expectedArgs, container.Args[i:i+2])
+				}
+			}
+			for _, envVar := range tc.expectedEnv {
+				assert.Contains(t, container.Env, envVar)
+			}
+			for _, envVar := range container.Env {
+				assert.NotContains(t, tc.absentEnv, envVar.Name)
+			}
+		})
+	}
+}
+
 func TestBMOContainerTLSProfileArgs(t *testing.T) {
 	images := Images{
 		BaremetalOperator: expectedBaremetalOperator,
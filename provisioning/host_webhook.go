@@ -0,0 +1,84 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+const hostWebhookPath = "/mutate-metal3-io-v1alpha1-baremetalhost"
+
+// defaultDetachedDeleteAction sets the delete action of the detached
+// annotation of a host, read by the baremetal-operator when the host is
+// deleted, when the annotation has none. Annotations that cannot be parsed
+// are left for the baremetal-operator to report.
+func defaultDetachedDeleteAction(host *baremetalv1alpha1.BareMetalHost, action metal3iov1alpha1.DetachedDeleteAction) error {
+	value, detached := host.Annotations[baremetalv1alpha1.DetachedAnnotation]
+	if !detached || action == "" {
+		return nil
+	}
+	args := baremetalv1alpha1.DetachedAnnotationArguments{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &args); err != nil {
+			return nil
+		}
+	}
+	if args.DeleteAction != "" {
+		return nil
+	}
+	args.DeleteAction = baremetalv1alpha1.DetachedDeleteAction(action)
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	host.Annotations[baremetalv1alpha1.DetachedAnnotation] = string(data)
+	return nil
+}
+
+// hostDefaulter sets what the Provisioning CR configures on the
+// BareMetalHosts each time they are written: it points their image to the
+// image cache of their zone when ImageCache.TopologyAwareURLs is set, which
+// ResolveHostImages also does when the endpoints change, and sets the delete
+// action of the detached hosts from BaremetalOperator.DetachedDeleteAction.
+type hostDefaulter struct {
+	client client.Reader
+}
+
+var _ admission.Defaulter[*baremetalv1alpha1.BareMetalHost] = &hostDefaulter{}
+
+func (w *hostDefaulter) Default(ctx context.Context, host *baremetalv1alpha1.BareMetalHost) error {
+	// Without the Provisioning CR, the user image of the hosts is restored.
+	prov := &metal3iov1alpha1.Provisioning{}
+	err := w.client.Get(ctx, types.NamespacedName{Name: metal3iov1alpha1.ProvisioningSingletonName}, prov)
+	if err != nil && !apierrors.IsNotFound(err) {
+		// The webhook ignores failures, the host is left unchanged.
+		return err
+	}
+
+	if err := resolveHostImage(host, imageCacheZoneURLs(prov, host.Labels[corev1.LabelTopologyZone])); err != nil {
+		return err
+	}
+	if bmo := prov.Spec.BaremetalOperator; bmo != nil {
+		return defaultDetachedDeleteAction(host, bmo.DetachedDeleteAction)
+	}
+	return nil
+}
+
+func setupHostWebhook(mgr ctrl.Manager) error {
+	// The Provisioning CR is read from the API server: the hosts resolved
+	// by ResolveHostImages right after its status was written must not be
+	// resolved again against the endpoints of a stale cache.
+	return ctrl.NewWebhookManagedBy(mgr, &baremetalv1alpha1.BareMetalHost{}).
+		WithDefaulter(&hostDefaulter{client: mgr.GetAPIReader()}).
+		WithDefaulterCustomPath(hostWebhookPath).
+		Complete()
+}
@@ -0,0 +1,80 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func TestHostDefaulterDetachedDeleteAction(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, metal3iov1alpha1.AddToScheme(scheme))
+
+	testCases := []struct {
+		name        string
+		action      metal3iov1alpha1.DetachedDeleteAction
+		annotations map[string]string
+		expected    map[string]string
+	}{
+		{
+			name:        "detached without arguments",
+			action:      metal3iov1alpha1.DetachedDeleteActionDelete,
+			annotations: map[string]string{baremetalv1alpha1.DetachedAnnotation: ""},
+			expected:    map[string]string{baremetalv1alpha1.DetachedAnnotation: `{"deleteAction":"delete"}`},
+		},
+		{
+			name:        "detached without delete action",
+			action:      metal3iov1alpha1.DetachedDeleteActionDelay,
+			annotations: map[string]string{baremetalv1alpha1.DetachedAnnotation: `{}`},
+			expected:    map[string]string{baremetalv1alpha1.DetachedAnnotation: `{"deleteAction":"delay"}`},
+		},
+		{
+			name:        "delete action of the host kept",
+			action:      metal3iov1alpha1.DetachedDeleteActionDelete,
+			annotations: map[string]string{baremetalv1alpha1.DetachedAnnotation: `{"deleteAction":"delay"}`},
+			expected:    map[string]string{baremetalv1alpha1.DetachedAnnotation: `{"deleteAction":"delay"}`},
+		},
+		{
+			name:        "invalid annotation left to the baremetal-operator",
+			action:      metal3iov1alpha1.DetachedDeleteActionDelete,
+			annotations: map[string]string{baremetalv1alpha1.DetachedAnnotation: `delete`},
+			expected:    map[string]string{baremetalv1alpha1.DetachedAnnotation: `delete`},
+		},
+		{
+			name:        "not set",
+			annotations: map[string]string{baremetalv1alpha1.DetachedAnnotation: ""},
+			expected:    map[string]string{baremetalv1alpha1.DetachedAnnotation: ""},
+		},
+		{
+			name:   "not detached",
+			action: metal3iov1alpha1.DetachedDeleteActionDelete,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prov := &metal3iov1alpha1.Provisioning{
+				ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+				Spec: metal3iov1alpha1.ProvisioningSpec{
+					BaremetalOperator: &metal3iov1alpha1.BaremetalOperator{DetachedDeleteAction: tc.action},
+				},
+			}
+			host := &baremetalv1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-5", Namespace: testNamespace, Annotations: tc.annotations},
+			}
+			defaulter := &hostDefaulter{
+				client: fakeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(prov).Build(),
+			}
+
+			assert.NoError(t, defaulter.Default(context.TODO(), host))
+			assert.Equal(t, tc.expected, host.Annotations)
+		})
+	}
+}
@@ -2,12 +2,17 @@ package provisioning
 
 import (
 	"context"
+	_ "embed"
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,6 +21,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	appsclientv1 "k8s.io/client-go/kubernetes/typed/apps/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -28,16 +34,25 @@ import (
 )
 
 const (
-	imageCacheSharedVolume                                = "metal3-shared-image-cache"
-	imageCacheService                                     = "metal3-image-cache"
-	imageCachePort                                        = 6181
-	imageCachePortName                                    = "http"
-	DaemonSetProgressing    appsv1.DaemonSetConditionType = "Progressing"
-	DaemonSetReplicaFailure appsv1.DaemonSetConditionType = "ReplicaFailure"
-	DaemonSetAvailable      appsv1.DaemonSetConditionType = "Available"
-	DaemonSetDisabled       appsv1.DaemonSetConditionType = "Disabled"
+	imageCacheSharedVolume                                  = "metal3-shared-image-cache"
+	imageCacheService                                       = "metal3-image-cache"
+	imageCachePort                                          = 6181
+	imageCachePortName                                      = "http"
+	imageCacheStatusFile                                    = "cache-status.json"
+	imageCacheManagerInterval                               = 5 * time.Minute
+	imageCacheFetchInterval                                 = time.Minute
+	DaemonSetProgressing      appsv1.DaemonSetConditionType = "Progressing"
+	DaemonSetReplicaFailure   appsv1.DaemonSetConditionType = "ReplicaFailure"
+	DaemonSetAvailable        appsv1.DaemonSetConditionType = "Available"
+	DaemonSetDisabled         appsv1.DaemonSetConditionType = "Disabled"
 )
 
+// imageCacheManagerScript runs in the image cache pod to remove old images
+// and publish the disk usage of the cache.
+//
+//go:embed image_cache_manager.sh
+var imageCacheManagerScript string
+
 var (
 	daemonSetRolloutStartTime = time.Now()
 	daemonSetRolloutTimeout   = 5 * time.Minute
@@ -51,6 +66,7 @@ var (
 func getImageVolumes() []corev1.Volume {
 	volumes := []corev1.Volume{
 		imageVolume(),
+		imageCacheImagesVolume(),
 		trustedCAVolume(),
 		{
 			Name: ironicConfigVolume,
@@ -102,14 +118,108 @@ func imageVolume() corev1.Volume {
 	}
 }
 
+// cachedImageName returns the name under which the metal3 pod caches the
+// image downloaded from URL, which is served uncompressed.
+func cachedImageName(URL string) (string, error) {
+	downloadURL, err := url.Parse(URL)
+	if err != nil {
+		return "", err
+	}
+	return path.Base(fileCompressionSuffix.ReplaceAllString(downloadURL.Path, "")), nil
+}
+
+// cachedImageChecksum returns the checksum the image cache verifies the
+// image from ProvisioningOSDownloadURL against: the sha256 or sha512
+// parameter of the URL, which is the checksum of the uncompressed content of
+// gzip-compressed images. Images compressed otherwise, or whose checksum is
+// only in ProvisioningOSChecksumURL, are not verified.
+func cachedImageChecksum(config *metal3iov1alpha1.ProvisioningSpec) (string, error) {
+	downloadURL, err := url.Parse(config.ProvisioningOSDownloadURL)
+	if err != nil {
+		return "", err
+	}
+	_, compression, _ := metal3iov1alpha1.OSImageFormatFromPath(path.Base(downloadURL.Path))
+	if compression != metal3iov1alpha1.OSImageCompressionNone && compression != metal3iov1alpha1.OSImageCompressionGzip {
+		return "", nil
+	}
+	for _, param := range []string{"sha256", "sha512"} {
+		if sum := downloadURL.Query().Get(param); sum != "" {
+			return strings.ToLower(sum), nil
+		}
+	}
+	return "", nil
+}
+
+// imageCacheReadinessScript succeeds when the image cache manager verified
+// the image, given with its directory and expected checksum as arguments,
+// since it last changed.
+const imageCacheReadinessScript = `dir="$1/$2"; name="$2"; checksum="$3"
+read -r size mtime sum state < "${dir}/.${name}.state" &&
+[[ "${size} ${mtime}" == "$(stat -c '%s %Y' "${dir}/${name}")" && "${sum}" == "${checksum}" ]] &&
+[[ "${state}" == Verified || "${state}" == Ready ]]`
+
+// imageCacheReadinessProbe makes the image cache ready only once the image
+// from ProvisioningOSDownloadURL is cached and matches its checksum.
+func imageCacheReadinessProbe(cachedName, checksum string) *corev1.Probe {
+	if checksum == "" {
+		checksum = "-"
+	}
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/bash", "-c", imageCacheReadinessScript, "readiness", imageSharedDir, cachedName, checksum},
+			},
+		},
+		PeriodSeconds:    10,
+		TimeoutSeconds:   5,
+		FailureThreshold: 3,
+	}
+}
+
+// GetOSImageStatus describes the image downloaded from
+// ProvisioningOSDownloadURL, or returns nil when it is not set.
+func GetOSImageStatus(config *metal3iov1alpha1.ProvisioningSpec) (*metal3iov1alpha1.OSImageStatus, error) {
+	if config.ProvisioningOSDownloadURL == "" {
+		return nil, nil
+	}
+	downloadURL, err := url.Parse(config.ProvisioningOSDownloadURL)
+	if err != nil {
+		return nil, err
+	}
+	format, compression, ok := metal3iov1alpha1.OSImageFormatFromPath(path.Base(downloadURL.Path))
+	if !ok {
+		return nil, fmt.Errorf("unsupported OS image format in %s", downloadURL.Redacted())
+	}
+	cachedName, err := cachedImageName(config.ProvisioningOSDownloadURL)
+	if err != nil {
+		return nil, err
+	}
+	return &metal3iov1alpha1.OSImageStatus{
+		Format:      format,
+		Compression: compression,
+		CachedName:  cachedName,
+	}, nil
+}
+
+// firstLevelCacheURL returns the base URL of the images served by the
+// metal3 pod.
+// NOTE: Uses HTTPS and ironicPrivatePort (6388) because TLS is always enabled in OpenShift.
+// Plain HTTP access to /images on port 6180 is blocked when TLS is enabled.
+func firstLevelCacheURL(targetNamespace string) url.URL {
+	return url.URL{
+		Scheme: "https",
+		Host: net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", stateService, targetNamespace),
+			fmt.Sprint(ironicPrivatePort)),
+	}
+}
+
 // Helper to transform the first level (metal3 pod) cache URLs to second level
 // (control-plane daemonset) cache
 func transformURL(targetNamespace, URL string) (string, error) {
-	downloadURL, err := url.Parse(URL)
+	imageName, err := cachedImageName(URL)
 	if err != nil {
 		return "", err
 	}
-	imageName := path.Base(fileCompressionSuffix.ReplaceAllString(downloadURL.Path, ""))
 
 	// The first-level cache downloads and caches the file from the URL specified in ProvisioningOSDownloadURL
 	// and makes it available to this second-level cache.
@@ -119,14 +229,8 @@ func transformURL(targetNamespace, URL string) (string, error) {
 	// Finally, the second-level cache will make it available at:
 	// http://cluster.local:6181/images/rhcos-42.80.20190725.1-openstack.qcow2/cached-rhcos-42.80.20190725.1-openstack.qcow2
 	// See https://github.com/openshift/ironic-rhcos-downloader for more details
-	// NOTE: Uses HTTPS and ironicPrivatePort (6388) because TLS is always enabled in OpenShift.
-	// Plain HTTP access to /images on port 6180 is blocked when TLS is enabled.
-	cacheURL := url.URL{
-		Scheme: "https",
-		Host: net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", stateService, targetNamespace),
-			fmt.Sprint(ironicPrivatePort)),
-		Path: fmt.Sprintf("/images/%s/%s", imageName, imageName),
-	}
+	cacheURL := firstLevelCacheURL(targetNamespace)
+	cacheURL.Path = fmt.Sprintf("/images/%s/%s", imageName, imageName)
 	return cacheURL.String(), nil
 }
 
@@ -187,6 +291,110 @@ func createContainerImageCache(images *Images) corev1.Container {
 	return container
 }
 
+func getImageCacheManagerEnvVars(config *metal3iov1alpha1.ProvisioningSpec) ([]corev1.EnvVar, error) {
+	cachedName, err := cachedImageName(config.ProvisioningOSDownloadURL)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := cachedImageChecksum(config)
+	if err != nil {
+		return nil, err
+	}
+
+	keepVersions := metal3iov1alpha1.DefaultImageCacheKeepVersions
+	var maxSize int64
+	if config.ImageCache != nil {
+		if config.ImageCache.KeepVersions > 0 {
+			keepVersions = config.ImageCache.KeepVersions
+		}
+		if config.ImageCache.MaxSize != nil {
+			maxSize = config.ImageCache.MaxSize.Value()
+		}
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "CACHED_IMAGE_NAME",
+			Value: cachedName,
+		},
+		{
+			Name:  "CACHED_IMAGE_CHECKSUM",
+			Value: checksum,
+		},
+		{
+			Name:  "IMAGE_CACHE_KEEP_VERSIONS",
+			Value: strconv.Itoa(keepVersions),
+		},
+		{
+			Name:  "IMAGE_CACHE_MAX_SIZE",
+			Value: strconv.FormatInt(maxSize, 10),
+		},
+		{
+			Name:  "IMAGE_CACHE_INTERVAL",
+			Value: strconv.Itoa(int(imageCacheManagerInterval.Seconds())),
+		},
+		{
+			Name:  "IMAGE_CACHE_STATUS_FILE",
+			Value: imageCacheStatusFile,
+		},
+		{
+			Name:  "IMAGE_CACHE_ADDITIONAL_IMAGES",
+			Value: imageCacheImagesPath(),
+		},
+	}, nil
+}
+
+// createContainerImageCacheManager returns the container enforcing the
+// image cache policy of the Provisioning CR on the node, and pre-fetching
+// the additional images from the metal3 pod.
+func createContainerImageCacheManager(images *Images, targetNamespace string, config *metal3iov1alpha1.ProvisioningSpec) (corev1.Container, error) {
+	env, err := getImageCacheManagerEnvVars(config)
+	if err != nil {
+		return corev1.Container{}, err
+	}
+	sourceURL := firstLevelCacheURL(targetNamespace)
+	env = append(env,
+		corev1.EnvVar{
+			Name:  "IMAGE_CACHE_SOURCE_URL",
+			Value: sourceURL.String(),
+		},
+		// The metal3 pod serves the images over TLS
+		corev1.EnvVar{
+			Name:  "CURL_CA_BUNDLE",
+			Value: metal3TlsRootDir + "/ironic/ca.crt",
+		},
+	)
+
+	container := corev1.Container{
+		Name:            "metal3-image-cache-manager",
+		Image:           images.Ironic,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		SecurityContext: &corev1.SecurityContext{
+			ReadOnlyRootFilesystem: ptr.To(true),
+			// Needed for hostPath image volume mount
+			Privileged: ptr.To(true),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+		Command: []string{"/bin/bash", "-c", imageCacheManagerScript},
+		VolumeMounts: []corev1.VolumeMount{
+			imageVolumeMount,
+			imageCacheImagesVolumeMount,
+			ironicTlsMount,
+		},
+		Env: env,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	return container, nil
+}
+
 func newImageCacheInitContainers(info *ProvisioningInfo) ([]corev1.Container, error) {
 	newURL, err := transformURL(info.Namespace, info.ProvConfig.Spec.ProvisioningOSDownloadURL)
 	if err != nil {
@@ -207,12 +415,28 @@ func newImageCacheInitContainers(info *ProvisioningInfo) ([]corev1.Container, er
 	return []corev1.Container{initContainer}, nil
 }
 
-func newImageCacheContainers(images *Images, proxy *osconfigv1.Proxy) []corev1.Container {
+func newImageCacheContainers(images *Images, targetNamespace string, config *metal3iov1alpha1.ProvisioningSpec, proxy *osconfigv1.Proxy) ([]corev1.Container, error) {
+	manager, err := createContainerImageCacheManager(images, targetNamespace, config)
+	if err != nil {
+		return nil, err
+	}
+	cachedName, err := cachedImageName(config.ProvisioningOSDownloadURL)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := cachedImageChecksum(config)
+	if err != nil {
+		return nil, err
+	}
+	httpd := createContainerImageCache(images)
+	httpd.ReadinessProbe = imageCacheReadinessProbe(cachedName, checksum)
+
 	containers := []corev1.Container{
-		createContainerImageCache(images),
+		httpd,
+		manager,
 	}
 
-	return injectProxyAndCA(containers, proxy)
+	return injectProxyAndCA(containers, proxy), nil
 }
 
 func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSpec, error) {
@@ -220,7 +444,10 @@ func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSp
 	if err != nil {
 		return nil, err
 	}
-	containers := newImageCacheContainers(info.Images, info.Proxy)
+	containers, err := newImageCacheContainers(info.Images, info.Namespace, &info.ProvConfig.Spec, info.Proxy)
+	if err != nil {
+		return nil, err
+	}
 
 	tolerations := []corev1.Toleration{
 		{
@@ -245,6 +472,16 @@ func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSp
 		},
 	}
 
+	nodeSelector := map[string]string{
+		"node-role.kubernetes.io/master": "",
+	}
+	if imageCache := info.ProvConfig.Spec.ImageCache; imageCache != nil {
+		if len(imageCache.NodeSelector) > 0 {
+			nodeSelector = imageCache.NodeSelector
+		}
+		tolerations = append(tolerations, imageCache.Tolerations...)
+	}
+
 	return &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: podTemplateAnnotations,
@@ -254,9 +491,7 @@ func newImageCachePodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSp
 			},
 		},
 		Spec: corev1.PodSpec{
-			NodeSelector: map[string]string{
-				"node-role.kubernetes.io/master": "",
-			},
+			NodeSelector:      nodeSelector,
 			Volumes:           getImageVolumes(),
 			InitContainers:    injectProxyAndCA(initContainers, info.Proxy),
 			Containers:        containers,
@@ -314,6 +549,10 @@ func EnsureImageCache(info *ProvisioningInfo) (updated bool, err error) {
 		return
 	}
 
+	if updated, err = ensureImageCacheImages(info); err != nil {
+		return
+	}
+
 	imageCacheDaemonSet, err := newImageCacheDaemonSet(info)
 	if err != nil {
 		return
@@ -326,7 +565,7 @@ func EnsureImageCache(info *ProvisioningInfo) (updated bool, err error) {
 		return
 	}
 	daemonSetRolloutStartTime = time.Now()
-	daemonSet, updated, err := resourceapply.ApplyDaemonSet(
+	daemonSet, daemonSetUpdated, err := resourceapply.ApplyDaemonSet(
 		context.Background(),
 		info.Client.AppsV1(),
 		info.EventRecorder,
@@ -337,6 +576,7 @@ func EnsureImageCache(info *ProvisioningInfo) (updated bool, err error) {
 	}
 
 	resourcemerge.SetDaemonSetGeneration(&info.ProvConfig.Status.Generations, daemonSet)
+	updated = updated || daemonSetUpdated
 	return
 }
 
@@ -360,6 +600,108 @@ func GetImageCacheState(client appsclientv1.DaemonSetsGetter, targetNamespace st
 	return DaemonSetProgressing, nil
 }
 
+// imageCacheUsage is the content of the status file published by the image
+// cache pod on each node.
+type imageCacheUsage struct {
+	CurrentImage   string                              `json:"currentImage"`
+	Content        *metal3iov1alpha1.CachedImageStatus `json:"content"`
+	CachedImages   int                                 `json:"cachedImages"`
+	UsedBytes      int64                               `json:"usedBytes"`
+	CapacityBytes  int64                               `json:"capacityBytes"`
+	AvailableBytes int64                               `json:"availableBytes"`
+
+	Images []metal3iov1alpha1.CachedImageStatus `json:"images"`
+}
+
+func readImageCacheUsage(ctx context.Context, client *http.Client, statusURL string) (*imageCacheUsage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	usage := &imageCacheUsage{}
+	if err := json.NewDecoder(resp.Body).Decode(usage); err != nil {
+		return nil, fmt.Errorf("invalid image cache status: %w", err)
+	}
+	return usage, nil
+}
+
+// ImageCacheMinFreeSpacePercent returns the free space, in percent of the
+// disk holding the image cache, below which the operator is Degraded.
+func ImageCacheMinFreeSpacePercent(config *metal3iov1alpha1.ProvisioningSpec) int {
+	if config.ImageCache != nil && config.ImageCache.MinFreeSpacePercent > 0 {
+		return config.ImageCache.MinFreeSpacePercent
+	}
+	return metal3iov1alpha1.DefaultImageCacheMinFreeSpacePercent
+}
+
+func imageCacheNodeStatus(nodeName string, usage *imageCacheUsage, minFreeSpacePercent int) metal3iov1alpha1.ImageCacheNodeStatus {
+	return metal3iov1alpha1.ImageCacheNodeStatus{
+		NodeName:       nodeName,
+		CachedImages:   usage.CachedImages,
+		UsedBytes:      usage.UsedBytes,
+		CapacityBytes:  usage.CapacityBytes,
+		AvailableBytes: usage.AvailableBytes,
+		LowDiskSpace:   usage.CapacityBytes > 0 && usage.AvailableBytes*100 < usage.CapacityBytes*int64(minFreeSpacePercent),
+		Content:        usage.Content,
+		Images:         usage.Images,
+	}
+}
+
+// GetImageCacheNodeStatuses reads the disk usage published by the image
+// cache pod running on each node.
+func GetImageCacheNodeStatuses(ctx context.Context, pods coreclientv1.PodsGetter, client *http.Client, targetNamespace string, config *metal3iov1alpha1.ProvisioningSpec) ([]metal3iov1alpha1.ImageCacheNodeStatus, error) {
+	podList, err := pods.Pods(targetNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", cboLabelName, imageCacheService),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	minFreeSpacePercent := ImageCacheMinFreeSpacePercent(config)
+	var statuses []metal3iov1alpha1.ImageCacheNodeStatus
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.HostIP == "" {
+			statuses = append(statuses, metal3iov1alpha1.ImageCacheNodeStatus{
+				NodeName: pod.Spec.NodeName,
+				Message:  "image cache pod is not running",
+			})
+			continue
+		}
+
+		statusURL := url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(pod.Status.HostIP, strconv.Itoa(imageCachePort)),
+			Path:   path.Join("/images", imageCacheStatusFile),
+		}
+		usage, err := readImageCacheUsage(ctx, client, statusURL.String())
+		if err != nil {
+			statuses = append(statuses, metal3iov1alpha1.ImageCacheNodeStatus{
+				NodeName: pod.Spec.NodeName,
+				Message:  fmt.Sprintf("unable to read the disk usage: %v", err),
+			})
+			continue
+		}
+		statuses = append(statuses, imageCacheNodeStatus(pod.Spec.NodeName, usage, minFreeSpacePercent))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].NodeName < statuses[j].NodeName })
+	return statuses, nil
+}
+
 func DeleteImageCache(info *ProvisioningInfo) error {
+	if err := deleteImageCacheImages(info); err != nil {
+		return err
+	}
 	return client.IgnoreNotFound(info.Client.AppsV1().DaemonSets(info.Namespace).Delete(context.Background(), imageCacheService, metav1.DeleteOptions{}))
 }
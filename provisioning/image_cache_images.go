@@ -0,0 +1,165 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+// The additional images are listed in a ConfigMap rather than in the
+// environment of the containers downloading them, so that changing the list
+// does not restart the metal3 pod.
+const (
+	imageCacheImagesName = "metal3-image-cache-images"
+	imageCacheImagesKey  = "images"
+)
+
+var imageCacheImagesVolumeMount = corev1.VolumeMount{
+	Name:      imageCacheImagesName,
+	MountPath: "/etc/metal3-image-cache",
+	ReadOnly:  true,
+}
+
+func imageCacheImagesVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: imageCacheImagesName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: imageCacheImagesName},
+				Items:                []corev1.KeyToPath{{Key: imageCacheImagesKey, Path: imageCacheImagesKey}},
+				Optional:             ptr.To(true),
+			},
+		},
+	}
+}
+
+func imageCacheImagesPath() string {
+	return imageCacheImagesVolumeMount.MountPath + "/" + imageCacheImagesKey
+}
+
+func hasAdditionalImages(config *metal3iov1alpha1.ProvisioningSpec) bool {
+	return config.ImageCache != nil && len(config.ImageCache.AdditionalImages) > 0
+}
+
+// additionalImagesList renders ImageCache.AdditionalImages in the format read
+// by the image cache manager: one "<name> <url> [<checksum>]" line per image.
+func additionalImagesList(config *metal3iov1alpha1.ProvisioningSpec) (string, error) {
+	if !hasAdditionalImages(config) {
+		return "", nil
+	}
+
+	var list strings.Builder
+	for _, image := range config.ImageCache.AdditionalImages {
+		name, err := cachedImageName(image.URL)
+		if err != nil {
+			return "", err
+		}
+		fields := []string{name, image.URL}
+		if image.Checksum != "" {
+			fields = append(fields, image.Checksum)
+		}
+		list.WriteString(strings.Join(fields, " ") + "\n")
+	}
+	return list.String(), nil
+}
+
+func newImageCacheImagesConfigMap(info *ProvisioningInfo) (*corev1.ConfigMap, error) {
+	list, err := additionalImagesList(&info.ProvConfig.Spec)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      imageCacheImagesName,
+			Namespace: info.Namespace,
+			Labels: map[string]string{
+				"k8s-app":    metal3AppName,
+				cboLabelName: imageCacheService,
+			},
+		},
+		Data: map[string]string{
+			imageCacheImagesKey: list,
+		},
+	}, nil
+}
+
+func ensureImageCacheImages(info *ProvisioningInfo) (bool, error) {
+	if !hasAdditionalImages(&info.ProvConfig.Spec) {
+		return false, deleteImageCacheImages(info)
+	}
+
+	configMap, err := newImageCacheImagesConfigMap(info)
+	if err != nil {
+		return false, err
+	}
+	if err := controllerutil.SetControllerReference(info.ProvConfig, configMap, info.Scheme); err != nil {
+		return false, fmt.Errorf("unable to set controllerReference on image cache images ConfigMap: %w", err)
+	}
+
+	_, updated, err := resourceapply.ApplyConfigMap(context.Background(), info.Client.CoreV1(), info.EventRecorder, configMap)
+	if err != nil {
+		return false, fmt.Errorf("unable to apply image cache images ConfigMap: %w", err)
+	}
+	return updated, nil
+}
+
+func deleteImageCacheImages(info *ProvisioningInfo) error {
+	return client.IgnoreNotFound(info.Client.CoreV1().ConfigMaps(info.Namespace).Delete(context.Background(), imageCacheImagesName, metav1.DeleteOptions{}))
+}
+
+// createContainerMetal3ImagePrefetcher returns the container of the metal3
+// pod downloading the additional images into the first-level cache, where
+// the image cache pods fetch them from.
+func createContainerMetal3ImagePrefetcher(images *Images) corev1.Container {
+	return corev1.Container{
+		Name:            "metal3-image-prefetcher",
+		Image:           images.Ironic,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		SecurityContext: &corev1.SecurityContext{
+			ReadOnlyRootFilesystem: ptr.To(true),
+			// Needed for hostPath image volume mount
+			Privileged: ptr.To(true),
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+			},
+		},
+		Command: []string{"/bin/bash", "-c", imageCacheManagerScript},
+		VolumeMounts: []corev1.VolumeMount{
+			imageVolumeMount,
+			imageCacheImagesVolumeMount,
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "IMAGE_CACHE_FETCH_ONLY",
+				Value: "true",
+			},
+			{
+				Name:  "IMAGE_CACHE_ADDITIONAL_IMAGES",
+				Value: imageCacheImagesPath(),
+			},
+			{
+				Name:  "IMAGE_CACHE_INTERVAL",
+				Value: strconv.Itoa(int(imageCacheFetchInterval.Seconds())),
+			},
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("5m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
@@ -0,0 +1,65 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func TestAdditionalImagesList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cache    *metal3iov1alpha1.ImageCache
+		expected string
+	}{
+		{
+			name: "no image cache",
+		},
+		{
+			name: "images",
+			cache: &metal3iov1alpha1.ImageCache{AdditionalImages: []metal3iov1alpha1.AdditionalImage{
+				{URL: "http://example.com/rhcos-414.qcow2.gz?foo=bar", Checksum: "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234"},
+				{URL: "https://example.com/custom/custom.raw"},
+			}},
+			expected: "rhcos-414.qcow2 http://example.com/rhcos-414.qcow2.gz?foo=bar e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234\n" +
+				"custom.raw https://example.com/custom/custom.raw\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := additionalImagesList(&metal3iov1alpha1.ProvisioningSpec{ImageCache: tc.cache})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, list)
+		})
+	}
+}
+
+func TestEnsureImageCacheImages(t *testing.T) {
+	info := newTestProvisioningInfo()
+	info.ProvConfig.Spec.ImageCache = &metal3iov1alpha1.ImageCache{AdditionalImages: []metal3iov1alpha1.AdditionalImage{
+		{URL: "http://example.com/custom.raw"},
+	}}
+
+	updated, err := ensureImageCacheImages(info)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	cm, err := info.Client.CoreV1().ConfigMaps(info.Namespace).Get(context.TODO(), imageCacheImagesName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "custom.raw http://example.com/custom.raw\n", cm.Data[imageCacheImagesKey])
+
+	info.ProvConfig.Spec.ImageCache = nil
+	updated, err = ensureImageCacheImages(info)
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	_, err = info.Client.CoreV1().ConfigMaps(info.Namespace).Get(context.TODO(), imageCacheImagesName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
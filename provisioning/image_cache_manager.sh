@@ -0,0 +1,331 @@
+#!/bin/bash
+#
+# Manages the OS images cached by the metal3-image-cache pod on its node:
+# verifies the current image against CACHED_IMAGE_CHECKSUM, downloads it
+# again when it is missing, downloads the additional images listed in
+# IMAGE_CACHE_ADDITIONAL_IMAGES, removes the images exceeding
+# IMAGE_CACHE_KEEP_VERSIONS or IMAGE_CACHE_MAX_SIZE, oldest first, and
+# publishes the disk usage of the cache and the state of the images in a
+# status file served next to the images.
+#
+# The state of each verified image is recorded in .<name>.state next to it,
+# with the size and modification time of the file it applies to, so that
+# images are only verified again when they change. The readiness probe of
+# the image cache reads it. Images not matching their checksum are removed
+# and downloaded again, waiting longer after each failed download, up to
+# IMAGE_CACHE_MAX_RETRY_DELAY seconds.
+#
+# With IMAGE_CACHE_FETCH_ONLY=true, as in the metal3 pod, it only downloads
+# the additional images from their own URL.
+
+set -u
+
+IMAGES_DIR=${IMAGES_DIR:-/shared/html/images}
+STATUS_FILE="${IMAGES_DIR}/${IMAGE_CACHE_STATUS_FILE:-cache-status.json}"
+KEEP_VERSIONS=${IMAGE_CACHE_KEEP_VERSIONS:-2}
+MAX_SIZE=${IMAGE_CACHE_MAX_SIZE:-0}
+INTERVAL=${IMAGE_CACHE_INTERVAL:-300}
+MAX_RETRY_DELAY=${IMAGE_CACHE_MAX_RETRY_DELAY:-3600}
+CURRENT_IMAGE=${CACHED_IMAGE_NAME:-}
+CURRENT_CHECKSUM=${CACHED_IMAGE_CHECKSUM:-}
+# Images changed more recently than this (in minutes) may still be
+# downloaded by the metal3 pod sharing the directory, and are never removed.
+MIN_AGE=${IMAGE_CACHE_MIN_AGE:-10}
+# Lines of "<name> <url> [<checksum>]".
+ADDITIONAL_IMAGES=${IMAGE_CACHE_ADDITIONAL_IMAGES:-}
+# Base URL of the metal3 pod the additional images are downloaded from. They
+# are downloaded from their own URL when empty.
+SOURCE_URL=${IMAGE_CACHE_SOURCE_URL:-}
+FETCH_ONLY=${IMAGE_CACHE_FETCH_ONLY:-false}
+
+cached_images=0
+used_bytes=0
+declare -A image_state image_downloaded image_total
+# Failed downloads of each image, and when to download it again.
+declare -A image_failures image_retry_at
+
+# Prints the names of the additional images.
+additional_images() {
+    [[ -r "${ADDITIONAL_IMAGES}" ]] || return 0
+    cut -d' ' -f1 "${ADDITIONAL_IMAGES}"
+}
+
+# Prints the names of the cached images other than the current and the
+# additional ones, most recently changed first.
+other_images() {
+    find "${IMAGES_DIR}" -mindepth 1 -maxdepth 1 -type d \
+        \( -name '*.qcow2' -o -name '*.raw' \) ! -name "${CURRENT_IMAGE}" \
+        -printf '%T@ %f\n' | sort -rn | cut -d' ' -f2- |
+        grep -vxF -f <(additional_images; echo "${CURRENT_IMAGE}")
+}
+
+dir_size() {
+    du -sb "$1" | cut -f1
+}
+
+collect() {
+    cached_images=0
+    used_bytes=0
+
+    if [[ -n "${CURRENT_IMAGE}" && -d "${IMAGES_DIR}/${CURRENT_IMAGE}" ]]; then
+        cached_images=1
+        used_bytes=$(dir_size "${IMAGES_DIR}/${CURRENT_IMAGE}")
+    fi
+
+    # Additional images are never removed and count for no version.
+    local name size recent
+    while read -r name; do
+        [[ -n "${name}" && -f "${IMAGES_DIR}/${name}/${name}" ]] || continue
+        cached_images=$(( cached_images + 1 ))
+        used_bytes=$(( used_bytes + $(dir_size "${IMAGES_DIR}/${name}") ))
+    done < <(additional_images)
+
+    # The current image counts as a kept version even while it is being
+    # downloaded.
+    local kept=1
+    while read -r name; do
+        [[ -n "${name}" ]] || continue
+        size=$(dir_size "${IMAGES_DIR}/${name}")
+        recent=$(find "${IMAGES_DIR}/${name}" -maxdepth 0 -mmin "-${MIN_AGE}")
+        if [[ -z "${recent}" ]] && { (( kept >= KEEP_VERSIONS )) ||
+            (( MAX_SIZE > 0 && used_bytes + size > MAX_SIZE )); }; then
+            echo "removing cached image ${name} (${size} bytes)"
+            rm -rf "${IMAGES_DIR:?}/${name}"
+            continue
+        fi
+        kept=$(( kept + 1 ))
+        cached_images=$(( cached_images + 1 ))
+        used_bytes=$(( used_bytes + size ))
+    done < <(other_images)
+}
+
+# Prints the size of the file at the given URL, or 0 when unknown. Fails
+# when the file cannot be found.
+remote_size() {
+    curl -sfIL "$1" | tr -d '\r' |
+        awk 'tolower($1) == "content-length:" { size = $2 } END { print size + 0 }'
+    return "${PIPESTATUS[0]}"
+}
+
+# Prints the size and modification time of a file.
+file_stamp() {
+    stat -c '%s %Y' "$1"
+}
+
+# Records the state of a cached image in its state file.
+mark() {
+    local name=$1 checksum=$2 state=$3
+    local file="${IMAGES_DIR}/${name}/${name}" marker="${IMAGES_DIR}/${name}/.${name}.state"
+    echo "$(file_stamp "${file}") ${checksum:--} ${state}" > "${marker}.tmp"
+    mv -f "${marker}.tmp" "${marker}"
+}
+
+verify_checksum() {
+    local file=$1 checksum=$2 actual
+    case ${#checksum} in
+        64) actual=$(sha256sum "${file}" | cut -d' ' -f1) ;;
+        128) actual=$(sha512sum "${file}" | cut -d' ' -f1) ;;
+        *) return 1 ;;
+    esac
+    [[ "${actual,,}" == "${checksum,,}" ]]
+}
+
+# Records the state of a cached image, verifying it against its checksum
+# unless it did not change since it was last verified.
+check_image() {
+    local name=$1 checksum=$2
+    local file="${IMAGES_DIR}/${name}/${name}" marker="${IMAGES_DIR}/${name}/.${name}.state"
+    local size mtime sum state
+    image_downloaded[${name}]=$(stat -c %s "${file}")
+    image_total[${name}]=${image_downloaded[${name}]}
+
+    if [[ -r "${marker}" ]] && read -r size mtime sum state < "${marker}" &&
+        [[ "${size} ${mtime}" == "$(file_stamp "${file}")" && "${sum}" == "${checksum:--}" ]]; then
+        image_state[${name}]=${state}
+        return
+    fi
+
+    if [[ -z "${checksum}" ]]; then
+        state=Ready
+    elif verify_checksum "${file}" "${checksum}"; then
+        state=Verified
+    else
+        echo "cached image ${name} does not match its checksum, removing it"
+        rm -f "${file}" "${marker}"
+        image_state[${name}]=ChecksumMismatch
+        image_downloaded[${name}]=0
+        return
+    fi
+    mark "${name}" "${checksum}" "${state}"
+    image_state[${name}]=${state}
+}
+
+# Delays the next download of an image after it failed, doubling the delay
+# after each failure.
+retry_later() {
+    local name=$1
+    local failures=$(( ${image_failures[${name}]:-0} + 1 ))
+    local delay=${INTERVAL} i
+    for (( i = 1; i < failures && delay < MAX_RETRY_DELAY; i++ )); do
+        delay=$(( delay * 2 ))
+    done
+    (( delay > MAX_RETRY_DELAY )) && delay=${MAX_RETRY_DELAY}
+    image_failures[${name}]=${failures}
+    image_retry_at[${name}]=$(( $(date +%s) + delay ))
+    echo "downloading image ${name} again in ${delay}s"
+}
+
+# Downloads an image, unless already cached, and records its state.
+fetch_image() {
+    local name=$1 url=$2 checksum=$3
+    local dir="${IMAGES_DIR}/${name}"
+    local done_state=Ready
+    [[ -n "${checksum}" ]] && done_state=Verified
+
+    if [[ -f "${dir}/${name}" ]]; then
+        check_image "${name}" "${checksum}"
+        [[ "${image_state[${name}]}" == ChecksumMismatch ]] || return
+    fi
+
+    # Keep the state of the last failure until it is time to retry.
+    if (( $(date +%s) < ${image_retry_at[${name}]:-0} )); then
+        return
+    fi
+
+    local src=${url} compressed=false
+    if [[ -n "${SOURCE_URL}" ]]; then
+        src="${SOURCE_URL}/images/${name}/${name}"
+    elif [[ "${url%%\?*}" == *.gz ]]; then
+        compressed=true
+    fi
+
+    local total
+    if ! total=$(remote_size "${src}"); then
+        if [[ -n "${SOURCE_URL}" ]]; then
+            # The metal3 pod has not downloaded the image yet.
+            image_state[${name}]=Pending
+            return
+        fi
+        # Not all servers answer HEAD requests, the size stays unknown.
+        total=0
+    fi
+
+    mkdir -p "${dir}"
+    local tmp="${dir}/.${name}.download"
+    image_state[${name}]=Downloading
+    image_downloaded[${name}]=0
+    image_total[${name}]=${total}
+    curl -sfL -o "${tmp}" "${src}" &
+    local pid=$!
+    while kill -0 "${pid}" 2>/dev/null; do
+        image_downloaded[${name}]=$(stat -c %s "${tmp}" 2>/dev/null || echo 0)
+        write_status
+        sleep 2 &
+        wait $!
+    done
+    if ! wait "${pid}"; then
+        echo "unable to download image ${name}"
+        rm -f "${tmp}"
+        image_state[${name}]=Failed
+        retry_later "${name}"
+        return
+    fi
+
+    if [[ "${compressed}" == true ]]; then
+        if ! gzip -dc "${tmp}" > "${tmp}.raw"; then
+            echo "unable to decompress image ${name}"
+            rm -f "${tmp}" "${tmp}.raw"
+            image_state[${name}]=Failed
+            retry_later "${name}"
+            return
+        fi
+        mv -f "${tmp}.raw" "${tmp}"
+    fi
+
+    if [[ -n "${checksum}" ]] && ! verify_checksum "${tmp}" "${checksum}"; then
+        echo "image ${name} does not match its checksum"
+        rm -f "${tmp}"
+        image_state[${name}]=ChecksumMismatch
+        retry_later "${name}"
+        return
+    fi
+
+    unset "image_failures[${name}]" "image_retry_at[${name}]"
+    mv -f "${tmp}" "${dir}/${name}"
+    mark "${name}" "${checksum}" "${done_state}"
+    image_state[${name}]=${done_state}
+    image_downloaded[${name}]=$(stat -c %s "${dir}/${name}")
+    image_total[${name}]=${image_downloaded[${name}]}
+    echo "cached image ${name}"
+}
+
+# Checks the current image, downloading it again from the metal3 pod when
+# it went missing.
+check_current() {
+    [[ -n "${CURRENT_IMAGE}" ]] || return 0
+    fetch_image "${CURRENT_IMAGE}" "" "${CURRENT_CHECKSUM}"
+    if [[ "${image_state[${CURRENT_IMAGE}]}" == Pending ]]; then
+        image_state[${CURRENT_IMAGE}]=Missing
+    fi
+}
+
+fetch_images() {
+    [[ -r "${ADDITIONAL_IMAGES}" ]] || return 0
+    local name url checksum
+    while read -r name url checksum <&3; do
+        [[ -n "${name}" ]] || continue
+        fetch_image "${name}" "${url}" "${checksum:-}"
+    done 3< "${ADDITIONAL_IMAGES}"
+}
+
+image_status() {
+    local name=$1 default_state=$2
+    printf '{"name": "%s", "state": "%s", "downloadedBytes": %d, "totalBytes": %d}' \
+        "${name}" "${image_state[${name}]:-${default_state}}" \
+        "${image_downloaded[${name}]:-0}" "${image_total[${name}]:-0}"
+}
+
+images_status() {
+    local name sep=""
+    while read -r name; do
+        [[ -n "${name}" ]] || continue
+        printf '%s' "${sep}"
+        image_status "${name}" Pending
+        sep=", "
+    done < <(additional_images)
+}
+
+content_status() {
+    if [[ -n "${CURRENT_IMAGE}" ]]; then
+        image_status "${CURRENT_IMAGE}" Missing
+    else
+        echo null
+    fi
+}
+
+write_status() {
+    [[ "${FETCH_ONLY}" == true ]] && return
+
+    local capacity available
+    read -r capacity available < <(df -B1 --output=size,avail "${IMAGES_DIR}" | tail -n 1)
+
+    cat > "${STATUS_FILE}.tmp" <<EOF
+{"currentImage": "${CURRENT_IMAGE}", "content": $(content_status), "cachedImages": ${cached_images}, "usedBytes": ${used_bytes}, "capacityBytes": ${capacity}, "availableBytes": ${available}, "images": [$(images_status)]}
+EOF
+    mv -f "${STATUS_FILE}.tmp" "${STATUS_FILE}"
+}
+
+trap 'exit 0' TERM INT
+
+while true; do
+    if [[ "${FETCH_ONLY}" != true ]]; then
+        check_current
+    fi
+    fetch_images
+    if [[ "${FETCH_ONLY}" != true ]]; then
+        collect
+        write_status
+    fi
+    sleep "${INTERVAL}" &
+    wait $!
+done
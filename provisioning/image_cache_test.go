@@ -16,10 +16,25 @@ limitations under the License.
 package provisioning
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
 
 func TestGetImageVolumes(t *testing.T) {
@@ -83,6 +98,117 @@ func TestCreateContainerImageCache(t *testing.T) {
 		"ReadOnlyRootFilesystem should be true")
 }
 
+func TestCachedImageChecksum(t *testing.T) {
+	sha256sum := "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234"
+	testCases := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name:     "gzip",
+			url:      "http://example.com/rhcos.qcow2.gz?sha256=" + sha256sum,
+			expected: sha256sum,
+		},
+		{
+			name:     "uncompressed upper case",
+			url:      "http://example.com/rhcos.raw?sha256=" + strings.ToUpper(sha256sum),
+			expected: sha256sum,
+		},
+		{
+			name: "xz",
+			url:  "http://example.com/rhcos.qcow2.xz?sha256=" + sha256sum,
+		},
+		{
+			name: "no checksum",
+			url:  "http://example.com/rhcos.qcow2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checksum, err := cachedImageChecksum(&metal3iov1alpha1.ProvisioningSpec{ProvisioningOSDownloadURL: tc.url})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, checksum)
+		})
+	}
+}
+
+func TestImageCacheReadinessScript(t *testing.T) {
+	dir := t.TempDir()
+	name := "rhcos.qcow2"
+	file := filepath.Join(dir, name, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte("image"), 0o644))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	stamp := fmt.Sprintf("%d %d", info.Size(), info.ModTime().Unix())
+
+	testCases := []struct {
+		name     string
+		state    string
+		checksum string
+		ready    bool
+	}{
+		{
+			name: "no state",
+		},
+		{
+			name:     "verified",
+			state:    stamp + " abc Verified",
+			checksum: "abc",
+			ready:    true,
+		},
+		{
+			name:     "no checksum",
+			state:    stamp + " - Ready",
+			checksum: "-",
+			ready:    true,
+		},
+		{
+			name:     "mismatch",
+			state:    stamp + " abc ChecksumMismatch",
+			checksum: "abc",
+		},
+		{
+			name:     "other checksum",
+			state:    stamp + " abc Verified",
+			checksum: "def",
+		},
+		{
+			name:     "file changed",
+			state:    "1 1 abc Verified",
+			checksum: "abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			marker := filepath.Join(dir, name, "."+name+".state")
+			os.Remove(marker)
+			if tc.state != "" {
+				require.NoError(t, os.WriteFile(marker, []byte(tc.state+"\n"), 0o644))
+			}
+			err := exec.Command("/bin/bash", "-c", imageCacheReadinessScript, "readiness", dir, name, tc.checksum).Run()
+			assert.Equal(t, tc.ready, err == nil, "%v", err)
+		})
+	}
+}
+
+func TestNewImageCacheContainersReadinessProbe(t *testing.T) {
+	config := &metal3iov1alpha1.ProvisioningSpec{
+		ProvisioningOSDownloadURL: "http://example.com/rhcos.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+	}
+	containers, err := newImageCacheContainers(&Images{Ironic: "test-ironic-image:latest"}, testNamespace, config, nil)
+	require.NoError(t, err)
+
+	probe := containers[0].ReadinessProbe
+	require.NotNil(t, probe)
+	assert.Equal(t, "metal3-httpd", containers[0].Name)
+	assert.Equal(t, []string{"/bin/bash", "-c", imageCacheReadinessScript, "readiness", imageSharedDir, "rhcos.qcow2",
+		"e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234"}, probe.Exec.Command)
+}
+
 func TestTransformURL(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -103,6 +229,12 @@ func TestTransformURL(t *testing.T) {
 			inputURL:    "https://example.com/rhcos-9.8.20260403-0-openstack.x86_64.qcow2",
 			expectedURL: "https://metal3-state.test-namespace.svc.cluster.local:6388/images/rhcos-9.8.20260403-0-openstack.x86_64.qcow2/rhcos-9.8.20260403-0-openstack.x86_64.qcow2",
 		},
+		{
+			name:        "Xz compressed raw image URL",
+			namespace:   "openshift-machine-api",
+			inputURL:    "https://example.com/rhcos-9.8.20260403-0-metal.x86_64.raw.xz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+			expectedURL: "https://metal3-state.openshift-machine-api.svc.cluster.local:6388/images/rhcos-9.8.20260403-0-metal.x86_64.raw/rhcos-9.8.20260403-0-metal.x86_64.raw",
+		},
 		{
 			name:          "Invalid URL",
 			namespace:     "openshift-machine-api",
@@ -125,3 +257,170 @@ func TestTransformURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOSImageStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		expected      *metal3iov1alpha1.OSImageStatus
+		expectedError bool
+	}{
+		{
+			name: "No OS download URL",
+		},
+		{
+			name: "Compressed qcow2",
+			url:  "https://example.com/rhcos-openstack.x86_64.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+			expected: &metal3iov1alpha1.OSImageStatus{
+				Format:      metal3iov1alpha1.OSImageFormatQCOW2,
+				Compression: metal3iov1alpha1.OSImageCompressionGzip,
+				CachedName:  "rhcos-openstack.x86_64.qcow2",
+			},
+		},
+		{
+			name: "Uncompressed raw",
+			url:  "https://example.com/rhcos-metal.x86_64.raw",
+			expected: &metal3iov1alpha1.OSImageStatus{
+				Format:      metal3iov1alpha1.OSImageFormatRaw,
+				Compression: metal3iov1alpha1.OSImageCompressionNone,
+				CachedName:  "rhcos-metal.x86_64.raw",
+			},
+		},
+		{
+			name:          "Unsupported format",
+			url:           "https://example.com/rhcos-live.x86_64.iso",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := GetOSImageStatus(&metal3iov1alpha1.ProvisioningSpec{ProvisioningOSDownloadURL: tc.url})
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+}
+
+func TestGetImageCacheManagerEnvVars(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cache    *metal3iov1alpha1.ImageCache
+		expected map[string]string
+	}{
+		{
+			name: "defaults",
+			expected: map[string]string{
+				"CACHED_IMAGE_NAME":         "rhcos.qcow2",
+				"CACHED_IMAGE_CHECKSUM":     "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+				"IMAGE_CACHE_KEEP_VERSIONS": "2",
+				"IMAGE_CACHE_MAX_SIZE":      "0",
+			},
+		},
+		{
+			name:  "policy",
+			cache: &metal3iov1alpha1.ImageCache{MaxSize: ptr.To(resource.MustParse("20Gi")), KeepVersions: 3},
+			expected: map[string]string{
+				"CACHED_IMAGE_NAME":             "rhcos.qcow2",
+				"IMAGE_CACHE_KEEP_VERSIONS":     "3",
+				"IMAGE_CACHE_MAX_SIZE":          "21474836480",
+				"IMAGE_CACHE_ADDITIONAL_IMAGES": "/etc/metal3-image-cache/images",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &metal3iov1alpha1.ProvisioningSpec{
+				ProvisioningOSDownloadURL: "http://example.com/rhcos.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+				ImageCache:                tc.cache,
+			}
+			env, err := getImageCacheManagerEnvVars(config)
+			assert.NoError(t, err)
+
+			actual := map[string]string{}
+			for _, e := range env {
+				actual[e.Name] = e.Value
+			}
+			for name, value := range tc.expected {
+				assert.Equal(t, value, actual[name], name)
+			}
+		})
+	}
+}
+
+func TestReadImageCacheUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/images/cache-status.json":
+			fmt.Fprint(w, `{"currentImage": "rhcos.qcow2", "content": {"name": "rhcos.qcow2", "state": "Verified", "downloadedBytes": 1024, "totalBytes": 1024}, "cachedImages": 2, "usedBytes": 2048, "capacityBytes": 100000, "availableBytes": 5000, "images": [{"name": "custom.raw", "state": "Downloading", "downloadedBytes": 512, "totalBytes": 1024}]}`)
+		case "/images/invalid.json":
+			fmt.Fprint(w, `not json`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	usage, err := readImageCacheUsage(context.TODO(), server.Client(), server.URL+"/images/cache-status.json")
+	assert.NoError(t, err)
+	assert.Equal(t, &imageCacheUsage{
+		CurrentImage: "rhcos.qcow2", CachedImages: 2, UsedBytes: 2048, CapacityBytes: 100000, AvailableBytes: 5000,
+		Content: &metal3iov1alpha1.CachedImageStatus{Name: "rhcos.qcow2", State: metal3iov1alpha1.CachedImageVerified, DownloadedBytes: 1024, TotalBytes: 1024},
+		Images: []metal3iov1alpha1.CachedImageStatus{
+			{Name: "custom.raw", State: metal3iov1alpha1.CachedImageDownloading, DownloadedBytes: 512, TotalBytes: 1024},
+		},
+	}, usage)
+
+	_, err = readImageCacheUsage(context.TODO(), server.Client(), server.URL+"/images/invalid.json")
+	assert.ErrorContains(t, err, "invalid image cache status")
+
+	_, err = readImageCacheUsage(context.TODO(), server.Client(), server.URL+"/images/missing.json")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestImageCacheNodeStatus(t *testing.T) {
+	usage := &imageCacheUsage{
+		CachedImages: 1, UsedBytes: 1000, CapacityBytes: 100000, AvailableBytes: 9000,
+		Images: []metal3iov1alpha1.CachedImageStatus{{Name: "custom.raw", State: metal3iov1alpha1.CachedImageVerified}},
+	}
+
+	status := imageCacheNodeStatus("master-0", usage, 10)
+	assert.Equal(t, "master-0", status.NodeName)
+	assert.Equal(t, int64(9000), status.AvailableBytes)
+	assert.True(t, status.LowDiskSpace)
+	assert.Equal(t, usage.Images, status.Images)
+
+	status = imageCacheNodeStatus("master-0", usage, 5)
+	assert.False(t, status.LowDiskSpace)
+}
+
+func TestGetImageCacheNodeStatuses(t *testing.T) {
+	pod := func(name, node string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testNamespace,
+				Labels:    map[string]string{cboLabelName: imageCacheService},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	kubeClient := fakekube.NewSimpleClientset(
+		pod("metal3-image-cache-b", "master-1", corev1.PodPending),
+		pod("metal3-image-cache-a", "master-0", corev1.PodRunning),
+		pod("metal3-image-cache-c", "", corev1.PodPending),
+	)
+
+	statuses, err := GetImageCacheNodeStatuses(context.TODO(), kubeClient.CoreV1(), http.DefaultClient, testNamespace, &metal3iov1alpha1.ProvisioningSpec{})
+	assert.NoError(t, err)
+	assert.Equal(t, []metal3iov1alpha1.ImageCacheNodeStatus{
+		{NodeName: "master-0", Message: "image cache pod is not running"},
+		{NodeName: "master-1", Message: "image cache pod is not running"},
+	}, statuses)
+}
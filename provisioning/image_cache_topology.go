@@ -0,0 +1,222 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+const (
+	// hostImageSourceAnnotation keeps the image set by the user on a host
+	// whose URLs point to the image cache of its zone.
+	hostImageSourceAnnotation = "baremetal.openshift.io/image-cache-source"
+)
+
+// GetImageCacheEndpoints groups the image cache pods ready to serve images
+// by the zone of their node. The nodes are read from the given cache, as
+// there is one lookup per pod.
+func GetImageCacheEndpoints(ctx context.Context, coreClient coreclientv1.CoreV1Interface, nodes client.Reader, targetNamespace string) ([]metal3iov1alpha1.ImageCacheEndpoint, error) {
+	podList, err := coreClient.Pods(targetNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", cboLabelName, imageCacheService),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urlsByZone := map[string][]string{}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || pod.Status.HostIP == "" || !isPodReady(&pod) {
+			continue
+		}
+		node := &corev1.Node{}
+		if err := nodes.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		zone := node.Labels[corev1.LabelTopologyZone]
+		cacheURL := url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(pod.Status.HostIP, strconv.Itoa(imageCachePort)),
+		}
+		urlsByZone[zone] = append(urlsByZone[zone], cacheURL.String())
+	}
+
+	var endpoints []metal3iov1alpha1.ImageCacheEndpoint
+	for zone, urls := range urlsByZone {
+		sort.Strings(urls)
+		endpoints = append(endpoints, metal3iov1alpha1.ImageCacheEndpoint{Zone: zone, URLs: urls})
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Zone < endpoints[j].Zone })
+	return endpoints, nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// rewriteImageCacheURL points an image cache URL to one of the given
+// endpoints, chosen by key so that the hosts of a zone are spread over its
+// caches. URLs that are not served by the image cache, or that already
+// point to one of the endpoints, are returned unchanged.
+func rewriteImageCacheURL(rawURL string, endpoints []string, key string) string {
+	imageURL, err := url.Parse(rawURL)
+	if err != nil || len(endpoints) == 0 {
+		return rawURL
+	}
+	if imageURL.Port() != strconv.Itoa(imageCachePort) || !strings.HasPrefix(imageURL.Path, "/images/") {
+		return rawURL
+	}
+	for _, endpoint := range endpoints {
+		if endpointURL, err := url.Parse(endpoint); err == nil && endpointURL.Host == imageURL.Host {
+			return rawURL
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	endpointURL, err := url.Parse(endpoints[h.Sum32()%uint32(len(endpoints))])
+	if err != nil {
+		return rawURL
+	}
+	imageURL.Scheme = endpointURL.Scheme
+	imageURL.Host = endpointURL.Host
+	return imageURL.String()
+}
+
+// hostNotProvisioned returns true when the image of the host can still be
+// changed without affecting a provisioning in progress or done.
+func hostNotProvisioned(host *baremetalv1alpha1.BareMetalHost) bool {
+	switch host.Status.Provisioning.State {
+	case baremetalv1alpha1.StateNone, baremetalv1alpha1.StateUnmanaged, baremetalv1alpha1.StateRegistering,
+		baremetalv1alpha1.StateInspecting, baremetalv1alpha1.StateMatchProfile, baremetalv1alpha1.StatePreparing,
+		baremetalv1alpha1.StateReady, baremetalv1alpha1.StateAvailable:
+		return true
+	}
+	return false
+}
+
+// hostImageSource is the image set by the user on a host, along with the
+// URL it was pointed to.
+type hostImageSource struct {
+	URL         string `json:"url"`
+	Checksum    string `json:"checksum,omitempty"`
+	ResolvedURL string `json:"resolvedURL"`
+}
+
+// userImage returns the image URL and checksum set by the user on a host,
+// undoing a previous rewrite unless the user changed the URL since.
+func userImage(host *baremetalv1alpha1.BareMetalHost) (string, string) {
+	if value, ok := host.Annotations[hostImageSourceAnnotation]; ok {
+		source := hostImageSource{}
+		if err := json.Unmarshal([]byte(value), &source); err == nil && source.ResolvedURL == host.Spec.Image.URL {
+			return source.URL, source.Checksum
+		}
+	}
+	return host.Spec.Image.URL, host.Spec.Image.Checksum
+}
+
+// resolveHostImage points the image of a host that is not provisioned yet to
+// one of the image cache endpoints of its zone. The image set by the user is
+// kept in an annotation: it is the one resolved each time, and it is restored
+// when there is no endpoint to use anymore.
+func resolveHostImage(host *baremetalv1alpha1.BareMetalHost, endpoints []string) error {
+	if host.Spec.Image == nil {
+		delete(host.Annotations, hostImageSourceAnnotation)
+		return nil
+	}
+	if !hostNotProvisioned(host) {
+		return nil
+	}
+
+	imageURL, checksum := userImage(host)
+	host.Spec.Image.URL = rewriteImageCacheURL(imageURL, endpoints, host.Name)
+	host.Spec.Image.Checksum = rewriteImageCacheURL(checksum, endpoints, host.Name)
+	if host.Spec.Image.URL == imageURL && host.Spec.Image.Checksum == checksum {
+		delete(host.Annotations, hostImageSourceAnnotation)
+		return nil
+	}
+
+	source, err := json.Marshal(hostImageSource{URL: imageURL, Checksum: checksum, ResolvedURL: host.Spec.Image.URL})
+	if err != nil {
+		return err
+	}
+	if host.Annotations[hostImageSourceAnnotation] != string(source) {
+		klog.Infof("serving the image of host %s/%s from the image cache of zone %s", host.Namespace, host.Name, host.Labels[corev1.LabelTopologyZone])
+	}
+	if host.Annotations == nil {
+		host.Annotations = map[string]string{}
+	}
+	host.Annotations[hostImageSourceAnnotation] = string(source)
+	return nil
+}
+
+// imageCacheZoneURLs returns the image cache endpoints of a zone when the
+// image URLs are topology aware.
+func imageCacheZoneURLs(prov *metal3iov1alpha1.Provisioning, zone string) []string {
+	if zone == "" || prov.Spec.ImageCache == nil || !prov.Spec.ImageCache.TopologyAwareURLs {
+		return nil
+	}
+	for _, endpoint := range prov.Status.ImageCacheEndpoints {
+		if endpoint.Zone == zone {
+			return endpoint.URLs
+		}
+	}
+	return nil
+}
+
+// ResolveHostImages resolves the image of the hosts of the namespace that are
+// not provisioned yet against the image cache endpoints in the status of the
+// Provisioning CR, which must already be written. The hosts are otherwise
+// only resolved when they are written, and would keep pointing to a cache
+// that went away.
+func ResolveHostImages(ctx context.Context, c client.Client, prov *metal3iov1alpha1.Provisioning, namespace string) error {
+	hosts := &baremetalv1alpha1.BareMetalHostList{}
+	if err := c.List(ctx, hosts, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("unable to list the BareMetalHosts: %w", err)
+	}
+	for i := range hosts.Items {
+		host := &hosts.Items[i]
+		if host.Spec.Image == nil {
+			continue
+		}
+		resolved := host.DeepCopy()
+		if err := resolveHostImage(resolved, imageCacheZoneURLs(prov, host.Labels[corev1.LabelTopologyZone])); err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(host, resolved) {
+			continue
+		}
+		if err := c.Update(ctx, resolved); err != nil {
+			return fmt.Errorf("unable to update the image of host %s/%s: %w", host.Namespace, host.Name, err)
+		}
+	}
+	return nil
+}
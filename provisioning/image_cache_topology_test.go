@@ -0,0 +1,331 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	baremetalv1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func TestImageCacheNodeSelector(t *testing.T) {
+	infraToleration := corev1.Toleration{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists}
+	testCases := []struct {
+		name                 string
+		imageCache           *metal3iov1alpha1.ImageCache
+		expectedNodeSelector map[string]string
+		expectToleration     bool
+	}{
+		{
+			name:                 "control plane by default",
+			expectedNodeSelector: map[string]string{"node-role.kubernetes.io/master": ""},
+		},
+		{
+			name: "infra nodes",
+			imageCache: &metal3iov1alpha1.ImageCache{
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations:  []corev1.Toleration{infraToleration},
+			},
+			expectedNodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+			expectToleration:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:    &Images{Ironic: "ironic", MachineOsDownloader: "downloader"},
+				Namespace: testNamespace,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: metal3iov1alpha1.ProvisioningSpec{
+					ProvisioningOSDownloadURL: "http://example.com/rhcos.qcow2",
+					ImageCache:                tc.imageCache,
+				}},
+			}
+			template, err := newImageCachePodTemplateSpec(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedNodeSelector, template.Spec.NodeSelector)
+			if tc.expectToleration {
+				assert.Contains(t, template.Spec.Tolerations, infraToleration)
+			} else {
+				assert.NotContains(t, template.Spec.Tolerations, infraToleration)
+			}
+		})
+	}
+}
+
+func TestGetImageCacheEndpoints(t *testing.T) {
+	node := func(name, zone string) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if zone != "" {
+			n.Labels = map[string]string{corev1.LabelTopologyZone: zone}
+		}
+		return n
+	}
+	pod := func(name, node, hostIP string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: testNamespace,
+				Labels:    map[string]string{cboLabelName: imageCacheService},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				HostIP:     hostIP,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	nodes := fakeclient.NewClientBuilder().WithObjects(
+		node("worker-0", "rack-1"), node("worker-1", "rack-1"), node("worker-2", "rack-2"), node("worker-3", ""),
+	).Build()
+	kubeClient := fakekube.NewSimpleClientset(
+		pod("cache-0", "worker-0", "192.168.1.20", corev1.ConditionTrue),
+		pod("cache-1", "worker-1", "192.168.1.21", corev1.ConditionTrue),
+		pod("cache-2", "worker-2", "fd00::22", corev1.ConditionTrue),
+		pod("cache-3", "worker-3", "192.168.1.23", corev1.ConditionTrue),
+		pod("cache-4", "worker-4", "192.168.1.24", corev1.ConditionFalse),
+	)
+
+	endpoints, err := GetImageCacheEndpoints(context.TODO(), kubeClient.CoreV1(), nodes, testNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, []metal3iov1alpha1.ImageCacheEndpoint{
+		{URLs: []string{"http://192.168.1.23:6181"}},
+		{Zone: "rack-1", URLs: []string{"http://192.168.1.20:6181", "http://192.168.1.21:6181"}},
+		{Zone: "rack-2", URLs: []string{"http://[fd00::22]:6181"}},
+	}, endpoints)
+}
+
+func TestRewriteImageCacheURL(t *testing.T) {
+	endpoints := []string{"http://192.168.1.20:6181", "http://192.168.1.21:6181"}
+	testCases := []struct {
+		name     string
+		url      string
+		expected []string
+	}{
+		{
+			name:     "image cache URL",
+			url:      "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+			expected: []string{"http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2", "http://192.168.1.21:6181/images/rhcos.qcow2/rhcos.qcow2"},
+		},
+		{
+			name:     "already in the zone",
+			url:      "http://192.168.1.21:6181/images/rhcos.qcow2/rhcos.qcow2",
+			expected: []string{"http://192.168.1.21:6181/images/rhcos.qcow2/rhcos.qcow2"},
+		},
+		{
+			name:     "other server",
+			url:      "http://example.com/images/rhcos.qcow2",
+			expected: []string{"http://example.com/images/rhcos.qcow2"},
+		},
+		{
+			name:     "checksum",
+			url:      "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+			expected: []string{"e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := rewriteImageCacheURL(tc.url, endpoints, "worker-5")
+			assert.Contains(t, tc.expected, actual)
+			assert.Equal(t, actual, rewriteImageCacheURL(tc.url, endpoints, "worker-5"))
+		})
+	}
+}
+
+func TestHostImageURLRewriter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, metal3iov1alpha1.AddToScheme(scheme))
+
+	prov := func(topologyAware bool) *metal3iov1alpha1.Provisioning {
+		return &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+			Spec: metal3iov1alpha1.ProvisioningSpec{
+				ImageCache: &metal3iov1alpha1.ImageCache{TopologyAwareURLs: topologyAware},
+			},
+			Status: metal3iov1alpha1.ProvisioningStatus{
+				ImageCacheEndpoints: []metal3iov1alpha1.ImageCacheEndpoint{
+					{Zone: "rack-1", URLs: []string{"http://192.168.1.20:6181"}},
+				},
+			},
+		}
+	}
+	host := func(zone string, state baremetalv1alpha1.ProvisioningState) *baremetalv1alpha1.BareMetalHost {
+		return &baremetalv1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker-5",
+				Namespace: testNamespace,
+				Labels:    map[string]string{corev1.LabelTopologyZone: zone},
+			},
+			Spec: baremetalv1alpha1.BareMetalHostSpec{
+				Image: &baremetalv1alpha1.Image{
+					URL:      "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+					Checksum: "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2.sha256sum",
+				},
+			},
+			Status: baremetalv1alpha1.BareMetalHostStatus{
+				Provisioning: baremetalv1alpha1.ProvisionStatus{State: state},
+			},
+		}
+	}
+
+	rewritten := func(zone string, state baremetalv1alpha1.ProvisioningState, imageURL string) *baremetalv1alpha1.BareMetalHost {
+		h := host(zone, state)
+		h.Annotations = map[string]string{hostImageSourceAnnotation: `{"url":"` + h.Spec.Image.URL + `","checksum":"` + h.Spec.Image.Checksum + `","resolvedURL":"` + imageURL + `"}`}
+		h.Spec.Image.URL = imageURL
+		return h
+	}
+	changed := rewritten("rack-1", baremetalv1alpha1.StateAvailable, "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2")
+	changed.Spec.Image.URL = "http://example.com/rhcos.qcow2"
+	changed.Spec.Image.Checksum = "http://example.com/rhcos.qcow2.sha256sum"
+	expectedSource := `{"url":"http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",` +
+		`"checksum":"http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2.sha256sum",` +
+		`"resolvedURL":"http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2"}`
+
+	testCases := []struct {
+		name           string
+		prov           *metal3iov1alpha1.Provisioning
+		host           *baremetalv1alpha1.BareMetalHost
+		expectedURL    string
+		expectedSource string
+	}{
+		{
+			name:           "rewritten",
+			prov:           prov(true),
+			host:           host("rack-1", baremetalv1alpha1.StateAvailable),
+			expectedURL:    "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2",
+			expectedSource: expectedSource,
+		},
+		{
+			name:           "rewritten again",
+			prov:           prov(true),
+			host:           rewritten("rack-1", baremetalv1alpha1.StateAvailable, "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2"),
+			expectedURL:    "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2",
+			expectedSource: expectedSource,
+		},
+		{
+			name:        "restored in a zone without cache",
+			prov:        prov(true),
+			host:        rewritten("rack-2", baremetalv1alpha1.StateAvailable, "http://192.168.1.30:6181/images/rhcos.qcow2/rhcos.qcow2"),
+			expectedURL: "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+		},
+		{
+			name:        "restored when disabled",
+			prov:        prov(false),
+			host:        rewritten("rack-1", baremetalv1alpha1.StateAvailable, "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2"),
+			expectedURL: "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+		},
+		{
+			name:        "changed by the user",
+			prov:        prov(true),
+			host:        changed,
+			expectedURL: "http://example.com/rhcos.qcow2",
+		},
+		{
+			name:           "provisioned",
+			prov:           prov(true),
+			host:           rewritten("rack-1", baremetalv1alpha1.StateProvisioned, "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2"),
+			expectedURL:    "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2",
+			expectedSource: expectedSource,
+		},
+		{
+			name:        "disabled",
+			prov:        prov(false),
+			host:        host("rack-1", baremetalv1alpha1.StateAvailable),
+			expectedURL: "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+		},
+		{
+			name:        "zone without cache",
+			prov:        prov(true),
+			host:        host("rack-2", baremetalv1alpha1.StateAvailable),
+			expectedURL: "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+		},
+		{
+			name:        "not rewritten once provisioned",
+			prov:        prov(true),
+			host:        host("rack-1", baremetalv1alpha1.StateProvisioned),
+			expectedURL: "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rewriter := &hostDefaulter{
+				client: fakeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(tc.prov).Build(),
+			}
+			assert.NoError(t, rewriter.Default(context.TODO(), tc.host))
+			assert.Equal(t, tc.expectedURL, tc.host.Spec.Image.URL)
+			assert.Equal(t, tc.expectedSource, tc.host.Annotations[hostImageSourceAnnotation])
+		})
+	}
+}
+
+func TestResolveHostImages(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, baremetalv1alpha1.AddToScheme(scheme))
+
+	userURL := "http://192.168.111.5:6181/images/rhcos.qcow2/rhcos.qcow2"
+	host := func(name, zone, resolvedURL string, state baremetalv1alpha1.ProvisioningState) *baremetalv1alpha1.BareMetalHost {
+		return &baremetalv1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   testNamespace,
+				Labels:      map[string]string{corev1.LabelTopologyZone: zone},
+				Annotations: map[string]string{hostImageSourceAnnotation: `{"url":"` + userURL + `","resolvedURL":"` + resolvedURL + `"}`},
+			},
+			Spec: baremetalv1alpha1.BareMetalHostSpec{
+				Image: &baremetalv1alpha1.Image{URL: resolvedURL},
+			},
+			Status: baremetalv1alpha1.BareMetalHostStatus{
+				Provisioning: baremetalv1alpha1.ProvisionStatus{State: state},
+			},
+		}
+	}
+	deadCacheURL := "http://192.168.1.20:6181/images/rhcos.qcow2/rhcos.qcow2"
+	otherCacheURL := "http://192.168.1.21:6181/images/rhcos.qcow2/rhcos.qcow2"
+
+	// The cache of rack-1 on 192.168.1.20 went away, rack-2 has no cache
+	// anymore.
+	prov := &metal3iov1alpha1.Provisioning{
+		Spec: metal3iov1alpha1.ProvisioningSpec{
+			ImageCache: &metal3iov1alpha1.ImageCache{TopologyAwareURLs: true},
+		},
+		Status: metal3iov1alpha1.ProvisioningStatus{
+			ImageCacheEndpoints: []metal3iov1alpha1.ImageCacheEndpoint{
+				{Zone: "rack-1", URLs: []string{"http://192.168.1.21:6181"}},
+			},
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		host("worker-1", "rack-1", deadCacheURL, baremetalv1alpha1.StateAvailable),
+		host("worker-2", "rack-2", "http://192.168.1.30:6181/images/rhcos.qcow2/rhcos.qcow2", baremetalv1alpha1.StateAvailable),
+		host("worker-3", "rack-1", deadCacheURL, baremetalv1alpha1.StateProvisioned),
+	).Build()
+
+	assert.NoError(t, ResolveHostImages(context.TODO(), c, prov, testNamespace))
+
+	expected := map[string]struct {
+		url    string
+		source bool
+	}{
+		"worker-1": {url: otherCacheURL, source: true},
+		"worker-2": {url: userURL},
+		"worker-3": {url: deadCacheURL, source: true},
+	}
+	for name, want := range expected {
+		resolved := &baremetalv1alpha1.BareMetalHost{}
+		assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: testNamespace, Name: name}, resolved))
+		assert.Equal(t, want.url, resolved.Spec.Image.URL, name)
+		_, hasSource := resolved.Annotations[hostImageSourceAnnotation]
+		assert.Equal(t, want.source, hasSource, name)
+	}
+}
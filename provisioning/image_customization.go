@@ -142,14 +142,14 @@ func getRootfsURL(ironicIPs []string) string {
 	return ""
 }
 
-func createImageCustomizationContainer(images *Images, info *ProvisioningInfo, ironicIPs []string) corev1.Container {
-	noProxy := ironicIPs
-	envVars := envWithProxy(info.Proxy, []corev1.EnvVar{}, noProxy)
-
-	agentImage := images.IronicAgent
-	if info.ProvConfig.Spec.UnsupportedConfigOverrides != nil && info.ProvConfig.Spec.UnsupportedConfigOverrides.IronicAgentImage != "" {
-		agentImage = info.ProvConfig.Spec.UnsupportedConfigOverrides.IronicAgentImage
+func createImageCustomizationContainer(images *Images, info *ProvisioningInfo) corev1.Container {
+	// The Ironic URLs may depend on the metal3 pod IPs, they are read from
+	// the Ironic URLs ConfigMap.
+	envVars := []corev1.EnvVar{}
+	if info.Proxy != nil {
+		envVars = append(envVars, ironicURLsEnvVar(ironicIPsKey))
 	}
+	envVars = envWithProxy(info.Proxy, envVars, []string{fmt.Sprintf("$(%s)", ironicIPsKey)})
 
 	container := corev1.Container{
 		Name:  "machine-image-customization-controller",
@@ -193,13 +193,10 @@ func createImageCustomizationContainer(images *Images, info *ProvisioningInfo, i
 				Name:  imageSharedDirEnvVar,
 				Value: imageSharedDir,
 			},
-			corev1.EnvVar{
-				Name:  ironicBaseUrl,
-				Value: getUrlFromIP(ironicIPs, baremetalIronicPort),
-			},
+			ironicURLsEnvVar(ironicBaseUrl),
 			corev1.EnvVar{
 				Name:  ironicAgentImage,
-				Value: agentImage,
+				Value: getIronicAgentImage(info),
 			},
 			corev1.EnvVar{
 				Name:  containerRegistriesEnvVar,
@@ -217,10 +214,7 @@ func createImageCustomizationContainer(images *Images, info *ProvisioningInfo, i
 				Name:  containerUserCaBundleEnvVar,
 				Value: containerUserCaBundlePath,
 			},
-			corev1.EnvVar{
-				Name:  ironicRootfsEnvVar,
-				Value: getRootfsURL(ironicIPs),
-			},
+			ironicURLsEnvVar(ironicRootfsEnvVar),
 			buildSSHKeyEnvVar(info.SSHKey)),
 		Ports: []corev1.ContainerPort{
 			{
@@ -236,12 +230,13 @@ func createImageCustomizationContainer(images *Images, info *ProvisioningInfo, i
 		},
 		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 	}
-	return container
+	container.Env = append(container.Env, ironicAgentEnvVars(&info.ProvConfig.Spec)...)
+	return withIronicURLsLivenessProbe(container)
 }
 
-func newImageCustomizationPodTemplateSpec(info *ProvisioningInfo, labels *map[string]string, ironicIPs []string) *corev1.PodTemplateSpec {
+func newImageCustomizationPodTemplateSpec(info *ProvisioningInfo, labels *map[string]string) *corev1.PodTemplateSpec {
 	containers := []corev1.Container{
-		createImageCustomizationContainer(info.Images, info, ironicIPs),
+		createImageCustomizationContainer(info.Images, info),
 	}
 
 	// Extract the pre-provisioning images from a container in the payload
@@ -307,12 +302,13 @@ func newImageCustomizationPodTemplateSpec(info *ProvisioningInfo, labels *map[st
 				ironicAgentPullSecretVolume(),
 				caTrustDirVolume(),
 				mirrorConfigVolume(),
+				ironicURLsVolume(),
 			},
 		},
 	}
 }
 
-func newImageCustomizationDeployment(info *ProvisioningInfo, ironicIPs []string) *appsv1.Deployment {
+func newImageCustomizationDeployment(info *ProvisioningInfo) *appsv1.Deployment {
 	selector := &metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			"k8s-app":    metal3AppName,
@@ -323,7 +319,7 @@ func newImageCustomizationDeployment(info *ProvisioningInfo, ironicIPs []string)
 		"k8s-app":    metal3AppName,
 		cboLabelName: imageCustomizationService,
 	}
-	template := newImageCustomizationPodTemplateSpec(info, &podSpecLabels, ironicIPs)
+	template := newImageCustomizationPodTemplateSpec(info, &podSpecLabels)
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        imageCustomizationDeploymentName,
@@ -342,7 +338,19 @@ func newImageCustomizationDeployment(info *ProvisioningInfo, ironicIPs []string)
 	}
 }
 
-func newImageCustomizationConfig(info *ProvisioningInfo, ironicIPs []string) *corev1.Secret {
+// newImageCustomizationConfig returns the configuration of the ramdisk built
+// by the image customization controller. The URL of Ironic is not part of
+// it: it may depend on the metal3 pod IPs, and is read from the Ironic URLs
+// ConfigMap instead.
+func newImageCustomizationConfig(info *ProvisioningInfo) *corev1.Secret {
+	config := map[string]string{
+		ironicAgentImage: getIronicAgentImage(info),
+		sshKeyEnvVar:     info.SSHKey,
+	}
+	for _, envVar := range ironicAgentEnvVars(&info.ProvConfig.Spec) {
+		config[envVar.Name] = envVar.Value
+	}
+
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        imageCustomizationConfigName,
@@ -353,22 +361,13 @@ func newImageCustomizationConfig(info *ProvisioningInfo, ironicIPs []string) *co
 				cboLabelName: imageCustomizationService,
 			},
 		},
-		Type: corev1.SecretTypeOpaque,
-		StringData: map[string]string{
-			ironicAgentImage: info.Images.IronicAgent,
-			ironicBaseUrl:    getUrlFromIP(ironicIPs, baremetalIronicPort),
-			sshKeyEnvVar:     info.SSHKey,
-		},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: config,
 	}
 }
 
 func EnsureImageCustomizationDeployment(info *ProvisioningInfo) (updated bool, err error) {
-	ironicIPs, err := GetIronicIPs(info)
-	if err != nil {
-		return false, fmt.Errorf("unable to determine Ironic's IP to pass to the machine-image-customization-controller: %w", err)
-	}
-
-	configSecret := newImageCustomizationConfig(info, ironicIPs)
+	configSecret := newImageCustomizationConfig(info)
 	err = controllerutil.SetControllerReference(info.ProvConfig, configSecret, info.Scheme)
 	if err != nil {
 		err = fmt.Errorf("unable to set controllerReference on machine-image-customization config: %w", err)
@@ -380,7 +379,7 @@ func EnsureImageCustomizationDeployment(info *ProvisioningInfo) (updated bool, e
 		return false, fmt.Errorf("failed to create the image customization config: %w", err)
 	}
 
-	imageCustomizationDeployment := newImageCustomizationDeployment(info, ironicIPs)
+	imageCustomizationDeployment := newImageCustomizationDeployment(info)
 	expectedGeneration := resourcemerge.ExpectedDeploymentGeneration(imageCustomizationDeployment, info.ProvConfig.Status.Generations)
 	err = controllerutil.SetControllerReference(info.ProvConfig, imageCustomizationDeployment, info.Scheme)
 	if err != nil {
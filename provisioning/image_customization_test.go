@@ -45,14 +45,13 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 		MachineOsDownloader: expectedMachineOsDownloader,
 		StaticIpManager:     expectedIronicStaticIpManager,
 	}
-	ironicIP := "192.168.0.2"
-	ironicIP6 := "2001:db8::2"
 
 	expectedVolumeMounts := []corev1.VolumeMount{
 		imageRegistriesVolumeMount,
 		imageVolumeMount,
 		ironicAgentPullSecretMount,
 		caTrustDirVolumeMount,
+		{Name: ironicURLsConfigMapName, MountPath: ironicURLsMountPath, ReadOnly: true},
 	}
 
 	ntpServers := []string{"192.168.1.252", "192.168.1.253"}
@@ -60,26 +59,26 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 	container1 := corev1.Container{
 		Name: "image-customization-controller",
 		Env: []corev1.EnvVar{
+			ironicURLsEnvVar(ironicIPsKey),
 			{Name: "HTTP_PROXY", Value: "https://172.2.0.1:3128"},
 			{Name: "HTTPS_PROXY", Value: "https://172.2.0.1:3128"},
-			{Name: "NO_PROXY", Value: ".example.com,192.168.0.2"},
+			{Name: "NO_PROXY", Value: ".example.com,$(IRONIC_IPS)"},
 			{Name: "DEPLOY_ISO", Value: "/shared/html/images/ironic-python-agent.iso"},
 			{Name: "DEPLOY_INITRD", Value: "/shared/html/images/ironic-python-agent.initramfs"},
 			{Name: "DEPLOY_KERNEL", Value: "/shared/html/images/ironic-python-agent.kernel"},
 			{Name: "IMAGE_SHARED_DIR", Value: "/shared/html/images"},
-			{Name: "IRONIC_BASE_URL", Value: "https://192.168.0.2:6385"},
+			ironicURLsEnvVar(ironicBaseUrl),
 			{Name: "IRONIC_AGENT_IMAGE", Value: "registry.ci.openshift.org/openshift:ironic-agent"},
 			{Name: "REGISTRIES_CONF_PATH", Value: "/etc/containers/registries.conf"},
 			{Name: "IP_OPTIONS", Value: "ip=dhcp"},
 			{Name: "ADDITIONAL_NTP_SERVERS", Value: ""},
 			{Name: "CA_BUNDLE", Value: "/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt"},
-			{Name: "IRONIC_ROOTFS_URL", Value: "http://192.168.0.2:6180/images/ironic-python-agent.rootfs"},
+			ironicURLsEnvVar(ironicRootfsEnvVar),
 			{Name: "IRONIC_RAMDISK_SSH_KEY", Value: "sshkey"},
 		},
 		VolumeMounts: expectedVolumeMounts,
 	}
 	secret1 := map[string]string{
-		"IRONIC_BASE_URL":        "https://192.168.0.2:6385",
 		"IRONIC_AGENT_IMAGE":     "registry.ci.openshift.org/openshift:ironic-agent",
 		"IRONIC_RAMDISK_SSH_KEY": "sshkey",
 	}
@@ -91,19 +90,18 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 			{Name: "DEPLOY_INITRD", Value: "/shared/html/images/ironic-python-agent.initramfs"},
 			{Name: "DEPLOY_KERNEL", Value: "/shared/html/images/ironic-python-agent.kernel"},
 			{Name: "IMAGE_SHARED_DIR", Value: "/shared/html/images"},
-			{Name: "IRONIC_BASE_URL", Value: "https://192.168.0.2:6385"},
+			ironicURLsEnvVar(ironicBaseUrl),
 			{Name: "IRONIC_AGENT_IMAGE", Value: "registry.ci.openshift.org/openshift:ironic-agent"},
 			{Name: "REGISTRIES_CONF_PATH", Value: "/etc/containers/registries.conf"},
 			{Name: "IP_OPTIONS", Value: "ip=dhcp"},
 			{Name: "ADDITIONAL_NTP_SERVERS", Value: ""},
 			{Name: "CA_BUNDLE", Value: "/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt"},
-			{Name: "IRONIC_ROOTFS_URL", Value: "http://192.168.0.2:6180/images/ironic-python-agent.rootfs"},
+			ironicURLsEnvVar(ironicRootfsEnvVar),
 			{Name: "IRONIC_RAMDISK_SSH_KEY", Value: "sshkey"},
 		},
 		VolumeMounts: expectedVolumeMounts,
 	}
 	secret2 := map[string]string{
-		"IRONIC_BASE_URL":        "https://192.168.0.2:6385",
 		"IRONIC_AGENT_IMAGE":     "registry.ci.openshift.org/openshift:ironic-agent",
 		"IRONIC_RAMDISK_SSH_KEY": "sshkey",
 	}
@@ -111,26 +109,26 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 	container3 := corev1.Container{
 		Name: "image-customization-controller",
 		Env: []corev1.EnvVar{
+			ironicURLsEnvVar(ironicIPsKey),
 			{Name: "HTTP_PROXY", Value: "https://172.2.0.1:3128"},
 			{Name: "HTTPS_PROXY", Value: "https://172.2.0.1:3128"},
-			{Name: "NO_PROXY", Value: ".example.com,192.168.0.2,2001:db8::2"},
+			{Name: "NO_PROXY", Value: ".example.com,$(IRONIC_IPS)"},
 			{Name: "DEPLOY_ISO", Value: "/shared/html/images/ironic-python-agent.iso"},
 			{Name: "DEPLOY_INITRD", Value: "/shared/html/images/ironic-python-agent.initramfs"},
 			{Name: "DEPLOY_KERNEL", Value: "/shared/html/images/ironic-python-agent.kernel"},
 			{Name: "IMAGE_SHARED_DIR", Value: "/shared/html/images"},
-			{Name: "IRONIC_BASE_URL", Value: "https://192.168.0.2:6385,https://[2001:db8::2]:6385"},
+			ironicURLsEnvVar(ironicBaseUrl),
 			{Name: "IRONIC_AGENT_IMAGE", Value: "registry.ci.openshift.org/openshift:ironic-agent"},
 			{Name: "REGISTRIES_CONF_PATH", Value: "/etc/containers/registries.conf"},
 			{Name: "IP_OPTIONS", Value: "ip=dhcp"},
 			{Name: "ADDITIONAL_NTP_SERVERS", Value: ""},
 			{Name: "CA_BUNDLE", Value: "/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt"},
-			{Name: "IRONIC_ROOTFS_URL", Value: "http://192.168.0.2:6180/images/ironic-python-agent.rootfs"},
+			ironicURLsEnvVar(ironicRootfsEnvVar),
 			{Name: "IRONIC_RAMDISK_SSH_KEY", Value: "sshkey"},
 		},
 		VolumeMounts: expectedVolumeMounts,
 	}
 	secret3 := map[string]string{
-		"IRONIC_BASE_URL":        "https://192.168.0.2:6385,https://[2001:db8::2]:6385",
 		"IRONIC_AGENT_IMAGE":     "registry.ci.openshift.org/openshift:ironic-agent",
 		"IRONIC_RAMDISK_SSH_KEY": "sshkey",
 	}
@@ -142,19 +140,18 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 			{Name: "DEPLOY_INITRD", Value: "/shared/html/images/ironic-python-agent.initramfs"},
 			{Name: "DEPLOY_KERNEL", Value: "/shared/html/images/ironic-python-agent.kernel"},
 			{Name: "IMAGE_SHARED_DIR", Value: "/shared/html/images"},
-			{Name: "IRONIC_BASE_URL", Value: "https://192.168.0.2:6385"},
+			ironicURLsEnvVar(ironicBaseUrl),
 			{Name: "IRONIC_AGENT_IMAGE", Value: "registry.ci.openshift.org/openshift:ironic-agent"},
 			{Name: "REGISTRIES_CONF_PATH", Value: "/etc/containers/registries.conf"},
 			{Name: "IP_OPTIONS", Value: "ip=dhcp"},
 			{Name: "ADDITIONAL_NTP_SERVERS", Value: "192.168.1.252,192.168.1.253"},
 			{Name: "CA_BUNDLE", Value: "/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt"},
-			{Name: "IRONIC_ROOTFS_URL", Value: "http://192.168.0.2:6180/images/ironic-python-agent.rootfs"},
+			ironicURLsEnvVar(ironicRootfsEnvVar),
 			{Name: "IRONIC_RAMDISK_SSH_KEY", Value: "sshkey"},
 		},
 		VolumeMounts: expectedVolumeMounts,
 	}
 	secret4 := map[string]string{
-		"IRONIC_BASE_URL":        "https://192.168.0.2:6385",
 		"IRONIC_AGENT_IMAGE":     "registry.ci.openshift.org/openshift:ironic-agent",
 		"IRONIC_RAMDISK_SSH_KEY": "sshkey",
 	}
@@ -167,31 +164,55 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 			{Name: "DEPLOY_INITRD", Value: "/shared/html/images/ironic-python-agent.initramfs"},
 			{Name: "DEPLOY_KERNEL", Value: "/shared/html/images/ironic-python-agent.kernel"},
 			{Name: "IMAGE_SHARED_DIR", Value: "/shared/html/images"},
-			{Name: "IRONIC_BASE_URL", Value: "https://192.168.0.2:6385"},
+			ironicURLsEnvVar(ironicBaseUrl),
 			{Name: "IRONIC_AGENT_IMAGE", Value: imageOverride},
 			{Name: "REGISTRIES_CONF_PATH", Value: "/etc/containers/registries.conf"},
 			{Name: "IP_OPTIONS", Value: "ip=dhcp"},
 			{Name: "ADDITIONAL_NTP_SERVERS", Value: ""},
 			{Name: "CA_BUNDLE", Value: "/etc/pki/ca-trust/source/anchors/openshift-config-user-ca-bundle.crt"},
-			{Name: "IRONIC_ROOTFS_URL", Value: "http://192.168.0.2:6180/images/ironic-python-agent.rootfs"},
+			ironicURLsEnvVar(ironicRootfsEnvVar),
 			{Name: "IRONIC_RAMDISK_SSH_KEY", Value: "sshkey"},
 		},
 		VolumeMounts: expectedVolumeMounts,
 	}
-	secret5 := secret2
+	secret5 := map[string]string{
+		"IRONIC_AGENT_IMAGE":     imageOverride,
+		"IRONIC_RAMDISK_SSH_KEY": "sshkey",
+	}
+
+	ironicAgent := &v1alpha1.IronicAgent{
+		ExtraKernelParams: "console=ttyS0  ipa-debug=1",
+		HardwareManagers: []v1alpha1.HardwareManager{
+			{Name: "vendor", Image: "quay.io/example/vendor-hardware-manager:v1"},
+			{Name: "firmware", Image: "quay.io/example/firmware-hardware-manager:v2"},
+		},
+	}
+	container6 := corev1.Container{
+		Name: "image-customization-controller",
+		Env: append(container2.Env,
+			corev1.EnvVar{Name: "IRONIC_AGENT_KERNEL_PARAMS", Value: "console=ttyS0 ipa-debug=1"},
+			corev1.EnvVar{Name: "IRONIC_AGENT_HARDWARE_MANAGERS", Value: "vendor=quay.io/example/vendor-hardware-manager:v1,firmware=quay.io/example/firmware-hardware-manager:v2"},
+		),
+		VolumeMounts: expectedVolumeMounts,
+	}
+	secret6 := map[string]string{
+		"IRONIC_AGENT_IMAGE":             "registry.ci.openshift.org/openshift:ironic-agent",
+		"IRONIC_RAMDISK_SSH_KEY":         "sshkey",
+		"IRONIC_AGENT_KERNEL_PARAMS":     "console=ttyS0 ipa-debug=1",
+		"IRONIC_AGENT_HARDWARE_MANAGERS": "vendor=quay.io/example/vendor-hardware-manager:v1,firmware=quay.io/example/firmware-hardware-manager:v2",
+	}
 
 	tCases := []struct {
 		name              string
-		ironicIPs         []string
 		proxy             *v1.Proxy
 		expectedContainer corev1.Container
 		expectedSecret    map[string]string
 		ntpServers        []string
 		ironicAgentImage  string
+		ironicAgent       *v1alpha1.IronicAgent
 	}{
 		{
 			name:              "image customization container with proxy",
-			ironicIPs:         []string{ironicIP},
 			proxy:             testProxy,
 			expectedContainer: container1,
 			expectedSecret:    secret1,
@@ -199,7 +220,6 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 		},
 		{
 			name:              "image customization container without proxy",
-			ironicIPs:         []string{ironicIP},
 			proxy:             nil,
 			expectedContainer: container2,
 			expectedSecret:    secret2,
@@ -207,7 +227,6 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 		},
 		{
 			name:              "image customization container with proxy",
-			ironicIPs:         []string{ironicIP, ironicIP6},
 			proxy:             testProxy,
 			expectedContainer: container3,
 			expectedSecret:    secret3,
@@ -215,7 +234,6 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 		},
 		{
 			name:              "image customization container with additional NTP servers",
-			ironicIPs:         []string{ironicIP},
 			proxy:             nil,
 			expectedContainer: container4,
 			expectedSecret:    secret4,
@@ -223,11 +241,16 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 		},
 		{
 			name:              "image customization container with agent override",
-			ironicIPs:         []string{ironicIP},
 			expectedContainer: container5,
 			expectedSecret:    secret5,
 			ironicAgentImage:  imageOverride,
 		},
+		{
+			name:              "image customization container with ramdisk customizations",
+			expectedContainer: container6,
+			expectedSecret:    secret6,
+			ironicAgent:       ironicAgent,
+		},
 	}
 	for _, tc := range tCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -247,14 +270,16 @@ func TestNewImageCustomizationContainer(t *testing.T) {
 					Spec: v1alpha1.ProvisioningSpec{
 						AdditionalNTPServers:       tc.ntpServers,
 						UnsupportedConfigOverrides: overrides,
+						IronicAgent:                tc.ironicAgent,
 					},
 				},
 			}
-			actualContainer := createImageCustomizationContainer(&images, info, tc.ironicIPs)
+			actualContainer := createImageCustomizationContainer(&images, info)
+			assert.Len(t, actualContainer.Env, len(tc.expectedContainer.Env))
 			for e := range actualContainer.Env {
 				assert.EqualValues(t, tc.expectedContainer.Env[e], actualContainer.Env[e])
 			}
-			actualSecret := newImageCustomizationConfig(info, tc.ironicIPs)
+			actualSecret := newImageCustomizationConfig(info)
 			assert.Equal(t, tc.expectedSecret, actualSecret.StringData)
 			assert.Equal(t, tc.expectedContainer.VolumeMounts, actualContainer.VolumeMounts)
 		})
@@ -0,0 +1,320 @@
+package provisioning
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coreclientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+const imageResponseHeaderTimeout = 30 * time.Second
+
+// ImageURLResult is the outcome of checking that an OS image can be
+// downloaded.
+type ImageURLResult struct {
+	URL string
+	// Size is the size of the image as reported by the server, or -1 when
+	// it is unknown.
+	Size int64
+	// ChecksumVerified is set when the downloaded content matched the
+	// checksum of the URL.
+	ChecksumVerified bool
+	Err              error
+}
+
+// OSImageURLs returns the OS image URLs, and the URLs of the files used to
+// verify them, that are downloaded by the image cache and the
+// machine-os-images init containers.
+func OSImageURLs(config *metal3iov1alpha1.ProvisioningSpec) []string {
+	var urls []string
+	for _, u := range []string{
+		config.ProvisioningOSDownloadURL,
+		config.ProvisioningOSChecksumURL,
+		config.PreProvisioningOSDownloadURLs.IsoURL,
+		config.PreProvisioningOSDownloadURLs.KernelURL,
+		config.PreProvisioningOSDownloadURLs.InitramfsURL,
+		config.PreProvisioningOSDownloadURLs.RootfsURL,
+	} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if config.ImageCache != nil {
+		for _, image := range config.ImageCache.AdditionalImages {
+			urls = append(urls, image.URL)
+		}
+	}
+	return urls
+}
+
+// NewImageHTTPClient returns an HTTP client that goes through the cluster
+// proxy and trusts the CA bundle injected for the cluster, like the
+// containers downloading the images do.
+func NewImageHTTPClient(proxy *osconfigv1.Proxy, caBundle []byte) (*http.Client, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if len(caBundle) > 0 && !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, fmt.Errorf("no valid certificate found in the trusted CA bundle")
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 proxyFunc(proxy),
+			TLSClientConfig:       &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
+			ResponseHeaderTimeout: imageResponseHeaderTimeout,
+		},
+	}, nil
+}
+
+// ReadTrustedCABundle returns the CA bundle injected for the cluster in the
+// trusted CA ConfigMap, or nil when it does not exist.
+func ReadTrustedCABundle(ctx context.Context, client coreclientv1.ConfigMapsGetter, namespace string) ([]byte, error) {
+	cm, err := client.ConfigMaps(namespace).Get(ctx, externalTrustBundleConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []byte(cm.Data["ca-bundle.crt"]), nil
+}
+
+func proxyFunc(proxy *osconfigv1.Proxy) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if proxy == nil {
+			return nil, nil
+		}
+		proxyURL := proxy.Status.HTTPProxy
+		if req.URL.Scheme == "https" {
+			proxyURL = proxy.Status.HTTPSProxy
+		}
+		if proxyURL == "" || bypassProxy(req.URL.Hostname(), proxy.Status.NoProxy) {
+			return nil, nil
+		}
+		return url.Parse(proxyURL)
+	}
+}
+
+// bypassProxy returns true when the host matches one of the comma separated
+// domains, IP addresses or CIDRs of noProxy.
+func bypassProxy(host, noProxy string) bool {
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// newChecksumHash returns the hash matching a hex encoded checksum, based on
+// its length.
+func newChecksumHash(sum string) (hash.Hash, error) {
+	switch len(sum) {
+	case hex.EncodedLen(sha256.Size):
+		return sha256.New(), nil
+	case hex.EncodedLen(sha512.Size):
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported checksum %q", sum)
+}
+
+// expectedChecksum returns the hex encoded checksum of the image, either
+// from the sha256 or sha512 parameter of its URL or from the checksum file
+// at checksumURL. It returns an empty string when the image has no
+// checksum. The checksum of a URL parameter is the one of the uncompressed
+// image, while checksum files list the checksum of the files as published,
+// which is reported by fromFile.
+func expectedChecksum(ctx context.Context, client *http.Client, imageURL *url.URL, checksumURL string) (sum string, fromFile bool, err error) {
+	for _, param := range []string{"sha256", "sha512"} {
+		if sum := imageURL.Query().Get(param); sum != "" {
+			return strings.ToLower(sum), false, nil
+		}
+	}
+	if checksumURL == "" {
+		return "", false, nil
+	}
+
+	resp, err := doImageRequest(ctx, client, http.MethodGet, checksumURL)
+	if err != nil {
+		return "", true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", true, fmt.Errorf("checksum file: server returned %s", resp.Status)
+	}
+	sum, err = findChecksum(resp.Body, path.Base(imageURL.Path))
+	return sum, true, err
+}
+
+// findChecksum looks up the checksum of the named file in a checksum file,
+// in either the GNU coreutils ("<sum>  <name>") or the BSD
+// ("SHA256 (<name>) = <sum>") format.
+func findChecksum(checksums io.Reader, name string) (string, error) {
+	scanner := bufio.NewScanner(checksums)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if fields := strings.Fields(line); len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+		if _, rest, ok := strings.Cut(line, " ("); ok {
+			if file, sum, ok := strings.Cut(rest, ") = "); ok && file == name {
+				return strings.ToLower(sum), nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("unable to read checksum file: %w", err)
+	}
+	return "", fmt.Errorf("no checksum for %s in the checksum file", name)
+}
+
+// VerifyImageURL checks that the image at rawURL can be downloaded and is
+// not empty. When verifyChecksum is set and the image has a checksum, in
+// its URL or in the checksum file at checksumURL, the image is downloaded
+// and its checksum compared. As with the downloader, the checksum in the
+// URL of a gzip-compressed image is the one of its uncompressed content,
+// while a checksum file applies to the image as downloaded. Images
+// compressed with other formats are only verified against checksum files.
+func VerifyImageURL(ctx context.Context, client *http.Client, rawURL, checksumURL string, verifyChecksum bool) ImageURLResult {
+	result := ImageURLResult{URL: rawURL, Size: -1}
+
+	imageURL, err := url.Parse(rawURL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	resp, err := doImageRequest(ctx, client, http.MethodHead, rawURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		// Some servers do not implement HEAD, fall back to reading the
+		// headers of a GET.
+		resp, err = doImageRequest(ctx, client, http.MethodGet, rawURL)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Err = fmt.Errorf("server returned %s", resp.Status)
+		return result
+	}
+	result.Size = resp.ContentLength
+	if result.Size == 0 {
+		result.Err = fmt.Errorf("server returned an empty image")
+		return result
+	}
+
+	if !verifyChecksum {
+		return result
+	}
+	_, compression, _ := metal3iov1alpha1.OSImageFormatFromPath(path.Base(imageURL.Path))
+	sum, fromFile, err := expectedChecksum(ctx, client, imageURL, checksumURL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if sum == "" {
+		return result
+	}
+	if !fromFile && compression == metal3iov1alpha1.OSImageCompressionXZ {
+		return result
+	}
+	h, err := newChecksumHash(sum)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	resp, err = doImageRequest(ctx, client, http.MethodGet, rawURL)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Err = fmt.Errorf("server returned %s", resp.Status)
+		return result
+	}
+
+	var content io.Reader = resp.Body
+	if !fromFile && compression == metal3iov1alpha1.OSImageCompressionGzip {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			result.Err = fmt.Errorf("unable to decompress image: %w", err)
+			return result
+		}
+		defer gz.Close()
+		content = gz
+	}
+	if _, err := io.Copy(h, content); err != nil {
+		result.Err = fmt.Errorf("unable to download image: %w", err)
+		return result
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != sum {
+		result.Err = fmt.Errorf("checksum mismatch: expected %s, got %s", sum, actual)
+		return result
+	}
+	result.ChecksumVerified = true
+	return result
+}
+
+func doImageRequest(ctx context.Context, client *http.Client, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodHead {
+		resp.Body.Close()
+	}
+	return resp, nil
+}
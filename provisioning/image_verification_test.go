@@ -0,0 +1,188 @@
+package provisioning
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBypassProxy(t *testing.T) {
+	noProxy := ".cluster.local, example.com,10.0.0.0/16,fd00::1"
+	tCases := []struct {
+		host     string
+		expected bool
+	}{
+		{host: "metal3-state.openshift-machine-api.svc.cluster.local", expected: true},
+		{host: "example.com", expected: true},
+		{host: "mirror.example.com", expected: true},
+		{host: "badexample.com", expected: false},
+		{host: "10.0.4.2", expected: true},
+		{host: "10.1.4.2", expected: false},
+		{host: "fd00::1", expected: true},
+		{host: "fd00::2", expected: false},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.host, func(t *testing.T) {
+			assert.Equal(t, tc.expected, bypassProxy(tc.host, noProxy))
+		})
+	}
+	assert.True(t, bypassProxy("anything", "*"))
+	assert.False(t, bypassProxy("anything", ""))
+}
+
+func TestVerifyImageURL(t *testing.T) {
+	image := []byte("not really a qcow2 image")
+	sum := sha256.Sum256(image)
+	checksum := hex.EncodeToString(sum[:])
+	sum512 := sha512.Sum512(image)
+	checksum512 := hex.EncodeToString(sum512[:])
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, _ = gz.Write(image)
+	gz.Close()
+	// Checksum files list the checksum of the published, compressed, files.
+	compressedSum := sha256.Sum256(compressed.Bytes())
+	compressedChecksum := hex.EncodeToString(compressedSum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.qcow2.gz":
+			_, _ = w.Write(compressed.Bytes())
+		case "/no-head.qcow2.gz":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_, _ = w.Write(compressed.Bytes())
+		case "/SHA256SUMS":
+			_, _ = w.Write([]byte(checksum + "  other.qcow2\n" + compressedChecksum + "  image.qcow2.gz\n"))
+		case "/SHA256SUMS.uncompressed":
+			_, _ = w.Write([]byte(checksum + "  image.qcow2.gz\n"))
+		case "/empty.qcow2.gz":
+			w.Header().Set("Content-Length", "0")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tCases := []struct {
+		name             string
+		path             string
+		checksumPath     string
+		verifyChecksum   bool
+		expectedErr      string
+		expectedVerified bool
+	}{
+		{
+			name: "reachable",
+			path: "/image.qcow2.gz?sha256=" + checksum,
+		},
+		{
+			name: "HEAD not allowed",
+			path: "/no-head.qcow2.gz?sha256=" + checksum,
+		},
+		{
+			name:        "not found",
+			path:        "/missing.qcow2.gz?sha256=" + checksum,
+			expectedErr: "server returned 404 Not Found",
+		},
+		{
+			name:        "empty",
+			path:        "/empty.qcow2.gz?sha256=" + checksum,
+			expectedErr: "server returned an empty image",
+		},
+		{
+			name:             "checksum verified",
+			path:             "/image.qcow2.gz?sha256=" + checksum,
+			verifyChecksum:   true,
+			expectedVerified: true,
+		},
+		{
+			name:             "sha512 checksum verified",
+			path:             "/image.qcow2.gz?sha512=" + checksum512,
+			verifyChecksum:   true,
+			expectedVerified: true,
+		},
+		{
+			name:             "checksum file",
+			path:             "/image.qcow2.gz",
+			checksumPath:     "/SHA256SUMS",
+			verifyChecksum:   true,
+			expectedVerified: true,
+		},
+		{
+			name:           "checksum file listing the uncompressed image",
+			path:           "/image.qcow2.gz",
+			checksumPath:   "/SHA256SUMS.uncompressed",
+			verifyChecksum: true,
+			expectedErr:    "checksum mismatch",
+		},
+		{
+			name:           "checksum file not found",
+			path:           "/image.qcow2.gz",
+			checksumPath:   "/SHA512SUMS",
+			verifyChecksum: true,
+			expectedErr:    "checksum file: server returned 404 Not Found",
+		},
+		{
+			name:           "checksum mismatch",
+			path:           "/image.qcow2.gz?sha256=e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234",
+			verifyChecksum: true,
+			expectedErr:    "checksum mismatch",
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checksumURL := ""
+			if tc.checksumPath != "" {
+				checksumURL = server.URL + tc.checksumPath
+			}
+			result := VerifyImageURL(context.TODO(), server.Client(), server.URL+tc.path, checksumURL, tc.verifyChecksum)
+			if tc.expectedErr == "" {
+				assert.NoError(t, result.Err)
+				assert.Equal(t, int64(compressed.Len()), result.Size)
+			} else {
+				assert.ErrorContains(t, result.Err, tc.expectedErr)
+			}
+			assert.Equal(t, tc.expectedVerified, result.ChecksumVerified)
+		})
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := `e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234  rhcos-openstack.x86_64.qcow2.gz
+E98F83A2B9D4043719664A2BE75FE8134DC6CA1FDBDE807996622F8CC7ECD235 *rhcos-live.x86_64.iso
+SHA256 (rhcos-metal.x86_64.raw.gz) = e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd236
+`
+	tCases := []struct {
+		name        string
+		expected    string
+		expectedErr string
+	}{
+		{name: "rhcos-openstack.x86_64.qcow2.gz", expected: "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd234"},
+		{name: "rhcos-live.x86_64.iso", expected: "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd235"},
+		{name: "rhcos-metal.x86_64.raw.gz", expected: "e98f83a2b9d4043719664a2be75fe8134dc6ca1fdbde807996622f8cc7ecd236"},
+		{name: "rhcos-missing.qcow2.gz", expectedErr: "no checksum for rhcos-missing.qcow2.gz"},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sum, err := findChecksum(strings.NewReader(checksums), tc.name)
+			if tc.expectedErr != "" {
+				assert.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, sum)
+		})
+	}
+}
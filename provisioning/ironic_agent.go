@@ -0,0 +1,126 @@
+package provisioning
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+const (
+	ironicAgentKernelParams     = "IRONIC_AGENT_KERNEL_PARAMS"
+	ironicAgentHardwareManagers = "IRONIC_AGENT_HARDWARE_MANAGERS"
+)
+
+// getIronicAgentImage returns the ironic-python-agent image the ramdisk is
+// built from.
+func getIronicAgentImage(info *ProvisioningInfo) string {
+	if overrides := info.ProvConfig.Spec.UnsupportedConfigOverrides; overrides != nil && overrides.IronicAgentImage != "" {
+		return overrides.IronicAgentImage
+	}
+	return info.Images.IronicAgent
+}
+
+// getIronicAgentKernelParams returns the additional kernel parameters of
+// the ramdisk.
+func getIronicAgentKernelParams(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.IronicAgent == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(config.IronicAgent.ExtraKernelParams), " ")
+}
+
+// getIronicAgentHardwareManagers returns the hardware manager images
+// layered on the ramdisk, as comma-separated <name>=<image> pairs.
+func getIronicAgentHardwareManagers(config *metal3iov1alpha1.ProvisioningSpec) string {
+	if config.IronicAgent == nil {
+		return ""
+	}
+	managers := make([]string, 0, len(config.IronicAgent.HardwareManagers))
+	for _, manager := range config.IronicAgent.HardwareManagers {
+		managers = append(managers, manager.Name+"="+manager.Image)
+	}
+	return strings.Join(managers, ",")
+}
+
+// ironicAgentEnvVars returns the customizations of the ramdisk passed to
+// the image customization controller. They are only set when used, so
+// that its pod does not change otherwise.
+func ironicAgentEnvVars(config *metal3iov1alpha1.ProvisioningSpec) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	if params := getIronicAgentKernelParams(config); params != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: ironicAgentKernelParams, Value: params})
+	}
+	if managers := getIronicAgentHardwareManagers(config); managers != "" {
+		envVars = append(envVars, corev1.EnvVar{Name: ironicAgentHardwareManagers, Value: managers})
+	}
+	return envVars
+}
+
+// RamdiskSSHKeySources are the SSH keys authorized in the ramdisk read from
+// outside the Provisioning spec, in the authorized_keys format.
+type RamdiskSSHKeySources struct {
+	// InstallConfig is the SSH key of the install-config.
+	InstallConfig string
+	// Secret is the content of the Secret referenced by the spec.
+	Secret string
+}
+
+// RamdiskSSHKeys returns the SSH keys authorized in the ramdisk, in the
+// authorized_keys format, and the status of the keys considered: all the
+// keys of the spec, of the referenced Secret and of the install-config,
+// and the debug SSH key until it expires. A key found in several
+// sources is only authorized once. The keys that cannot be parsed are
+// reported as rejected. It also returns how long until the debug SSH key
+// expires, zero when there is none left to expire.
+func RamdiskSSHKeys(sources RamdiskSSHKeySources, config *metal3iov1alpha1.ProvisioningSpec, now time.Time) (string, []metal3iov1alpha1.RamdiskSSHKeyStatus, time.Duration) {
+	var authorized []string
+	var status []metal3iov1alpha1.RamdiskSSHKeyStatus
+	fingerprints := sets.New[string]()
+	authorize := func(authorizedKeys string, source metal3iov1alpha1.RamdiskSSHKeySource, expires *metav1.Time) {
+		for i, key := range strings.Split(authorizedKeys, "\n") {
+			key = strings.TrimSpace(key)
+			if key == "" || strings.HasPrefix(key, "#") {
+				continue
+			}
+			keyStatus := metal3iov1alpha1.RamdiskSSHKeyStatus{Source: source}
+			fingerprint, err := metal3iov1alpha1.SSHKeyFingerprint(key)
+			if err != nil {
+				keyStatus.Error = fmt.Sprintf("line %d: %v", i+1, err)
+			} else {
+				keyStatus.Fingerprint = fingerprint
+				keyStatus.Expires = expires
+				if !fingerprints.Has(fingerprint) {
+					fingerprints.Insert(fingerprint)
+					authorized = append(authorized, key)
+				}
+			}
+			status = append(status, keyStatus)
+		}
+	}
+
+	var sshKey *metal3iov1alpha1.RamdiskSSHKey
+	var debugKey *metal3iov1alpha1.DebugSSHKey
+	if config.IronicAgent != nil {
+		sshKey = config.IronicAgent.SSHKey
+		debugKey = config.IronicAgent.DebugSSHKey
+	}
+
+	if sshKey != nil {
+		authorize(sshKey.PublicKey, metal3iov1alpha1.RamdiskSSHKeySourceProvisioning, nil)
+		authorize(sources.Secret, metal3iov1alpha1.RamdiskSSHKeySourceSecret, nil)
+	}
+	authorize(sources.InstallConfig, metal3iov1alpha1.RamdiskSSHKeySourceInstallConfig, nil)
+
+	var expiresIn time.Duration
+	if debugKey != nil && debugKey.Expires.After(now) {
+		expiresIn = debugKey.Expires.Sub(now)
+		authorize(debugKey.PublicKey, metal3iov1alpha1.RamdiskSSHKeySourceDebug, debugKey.Expires.DeepCopy())
+	}
+	return strings.Join(authorized, "\n"), status, expiresIn
+}
@@ -0,0 +1,146 @@
+package provisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func TestRamdiskSSHKeys(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	installConfigKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIF+aT/6HJ3ID9ZiClfzw1pFMcT3hjve5/EpS8Vj4Lk3t core"
+	installConfigStatus := metal3iov1alpha1.RamdiskSSHKeyStatus{
+		Fingerprint: "SHA256:TjBjmt80ujX2k/6eeDhjf5CgC0zXAqocHBcyzJuGFJ0",
+		Source:      metal3iov1alpha1.RamdiskSSHKeySourceInstallConfig,
+	}
+	adminKey := "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBKJ7R2UcnK55M0fkx5O+ehBaH6nw6U64DOYuvt6YxukNibIcmvFz8ODXOBqs0VWS1UN759kE+HVRpVlhLP/3SZg= admin"
+	adminFingerprint := "SHA256:ZDC/I4bYJcNsp+74LFSMgljUi6zNC1rIpET7FdAdupc"
+	debugKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGjYNtTbZsTM8qKcPaXcRDnCAB4amM/JG1yzvZIAed9g debug"
+	debugFingerprint := "SHA256:PyzccvXUlxXLwrmbjR7RELJ90S/hEjP5Plk7lTxIK1k"
+
+	withDebugKey := func(expires time.Time) *metal3iov1alpha1.ProvisioningSpec {
+		return managedProvisioning().IronicAgent(&metal3iov1alpha1.IronicAgent{
+			DebugSSHKey: &metal3iov1alpha1.DebugSSHKey{
+				PublicKey: debugKey + "\n",
+				Expires:   metav1.NewTime(expires),
+			},
+		}).build()
+	}
+	withSSHKey := func(key *metal3iov1alpha1.RamdiskSSHKey) *metal3iov1alpha1.ProvisioningSpec {
+		return managedProvisioning().IronicAgent(&metal3iov1alpha1.IronicAgent{SSHKey: key}).build()
+	}
+
+	tCases := []struct {
+		name              string
+		sources           RamdiskSSHKeySources
+		spec              *metal3iov1alpha1.ProvisioningSpec
+		expectedKey       string
+		expectedStatus    []metal3iov1alpha1.RamdiskSSHKeyStatus
+		expectedExpiresIn time.Duration
+	}{
+		{
+			name:           "Install-config key",
+			sources:        RamdiskSSHKeySources{InstallConfig: installConfigKey},
+			spec:           managedProvisioning().build(),
+			expectedKey:    installConfigKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{installConfigStatus},
+		},
+		{
+			name:    "No keys",
+			sources: RamdiskSSHKeySources{},
+			spec:    managedProvisioning().build(),
+		},
+		{
+			name:        "Several install-config keys",
+			sources:     RamdiskSSHKeySources{InstallConfig: installConfigKey + "\n" + adminKey},
+			spec:        managedProvisioning().build(),
+			expectedKey: installConfigKey + "\n" + adminKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{
+				installConfigStatus,
+				{Fingerprint: adminFingerprint, Source: metal3iov1alpha1.RamdiskSSHKeySourceInstallConfig},
+			},
+		},
+		{
+			name:        "Debug SSH key",
+			sources:     RamdiskSSHKeySources{InstallConfig: installConfigKey},
+			spec:        withDebugKey(now.Add(2 * time.Hour)),
+			expectedKey: installConfigKey + "\n" + debugKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{
+				installConfigStatus,
+				{
+					Fingerprint: debugFingerprint,
+					Source:      metal3iov1alpha1.RamdiskSSHKeySourceDebug,
+					Expires:     &metav1.Time{Time: now.Add(2 * time.Hour)},
+				},
+			},
+			expectedExpiresIn: 2 * time.Hour,
+		},
+		{
+			name:           "Expired debug SSH key",
+			sources:        RamdiskSSHKeySources{InstallConfig: installConfigKey},
+			spec:           withDebugKey(now),
+			expectedKey:    installConfigKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{installConfigStatus},
+		},
+		{
+			name:        "Spec key",
+			sources:     RamdiskSSHKeySources{InstallConfig: installConfigKey},
+			spec:        withSSHKey(&metal3iov1alpha1.RamdiskSSHKey{PublicKey: adminKey}),
+			expectedKey: adminKey + "\n" + installConfigKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{
+				{Fingerprint: adminFingerprint, Source: metal3iov1alpha1.RamdiskSSHKeySourceProvisioning},
+				installConfigStatus,
+			},
+		},
+		{
+			name:        "Spec key also in the install-config",
+			sources:     RamdiskSSHKeySources{InstallConfig: installConfigKey},
+			spec:        withSSHKey(&metal3iov1alpha1.RamdiskSSHKey{PublicKey: installConfigKey}),
+			expectedKey: installConfigKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{
+				{Fingerprint: installConfigStatus.Fingerprint, Source: metal3iov1alpha1.RamdiskSSHKeySourceProvisioning},
+				installConfigStatus,
+			},
+		},
+		{
+			name: "Secret key",
+			sources: RamdiskSSHKeySources{
+				InstallConfig: installConfigKey,
+				Secret:        "# admins\nnot a key\n\n" + adminKey + "\n" + debugKey + "\n",
+			},
+			spec:        withSSHKey(&metal3iov1alpha1.RamdiskSSHKey{SecretName: "ramdisk-ssh-key"}),
+			expectedKey: adminKey + "\n" + debugKey + "\n" + installConfigKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{
+				{Source: metal3iov1alpha1.RamdiskSSHKeySourceSecret, Error: "line 2: unsupported key type \"not\""},
+				{Fingerprint: adminFingerprint, Source: metal3iov1alpha1.RamdiskSSHKeySourceSecret},
+				{Fingerprint: debugFingerprint, Source: metal3iov1alpha1.RamdiskSSHKeySourceSecret},
+				installConfigStatus,
+			},
+		},
+		{
+			name: "Secret without a valid key",
+			sources: RamdiskSSHKeySources{
+				InstallConfig: installConfigKey,
+				Secret:        "ssh-dss AAAAB3NzaC1kc3M= old\n",
+			},
+			spec:        withSSHKey(&metal3iov1alpha1.RamdiskSSHKey{SecretName: "ramdisk-ssh-key"}),
+			expectedKey: installConfigKey,
+			expectedStatus: []metal3iov1alpha1.RamdiskSSHKeyStatus{
+				{Source: metal3iov1alpha1.RamdiskSSHKeySourceSecret, Error: "line 1: unsupported key type \"ssh-dss\""},
+				installConfigStatus,
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, status, expiresIn := RamdiskSSHKeys(tc.sources, tc.spec, now)
+			assert.Equal(t, tc.expectedKey, key)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedExpiresIn, expiresIn)
+		})
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/pkg/errors"
-
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -27,12 +25,23 @@ import (
 const (
 	ironicProxyService       = "ironic-proxy"
 	ironicPrivatePort        = 6388
+	ironicUpstreamHostEnvVar = "IRONIC_UPSTREAM_HOST"
 	ironicUpstreamIPEnvVar   = "IRONIC_UPSTREAM_IP"
 	ironicUpstreamPortEnvVar = "IRONIC_UPSTREAM_PORT"
 	ironicProxyPortEnvVar    = "IRONIC_PROXY_PORT"
 )
 
-func createContainerIronicProxy(ironicIP string, images *Images, tlsProfileSpec *configv1.TLSProfileSpec) corev1.Container {
+// ironicUpstreamHost returns the host ironic-proxy forwards the requests to.
+// ironic-proxy has a single upstream and does not check its health itself:
+// the metal3-state service load-balances the requests across the Ironic
+// endpoints that pass their readiness probe, so the proxy pods do not depend
+// on where these endpoints run. The name is part of the Ironic TLS
+// certificate.
+func ironicUpstreamHost(targetNamespace string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", stateService, targetNamespace, defaultClusterDomain)
+}
+
+func createContainerIronicProxy(upstreamHost string, images *Images, tlsProfileSpec *configv1.TLSProfileSpec) corev1.Container {
 	container := corev1.Container{
 		Name:            "ironic-proxy",
 		Image:           images.Ironic,
@@ -61,9 +70,15 @@ func createContainerIronicProxy(ironicIP string, images *Images, tlsProfileSpec
 				Name:  ironicProxyPortEnvVar,
 				Value: fmt.Sprint(baremetalIronicPort),
 			},
+			{
+				Name:  ironicUpstreamHostEnvVar,
+				Value: upstreamHost,
+			},
+			// The upstream is a host name, only named an IP for the
+			// ironic-proxy images that predate IRONIC_UPSTREAM_HOST.
 			{
 				Name:  ironicUpstreamIPEnvVar,
-				Value: ironicIP,
+				Value: fmt.Sprintf("$(%s)", ironicUpstreamHostEnvVar),
 			},
 			{
 				Name:  ironicUpstreamPortEnvVar,
@@ -96,15 +111,9 @@ func createContainerIronicProxy(ironicIP string, images *Images, tlsProfileSpec
 	return container
 }
 
-func newIronicProxyPodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateSpec, error) {
-	ironicIPs, err := getPodIPs(info.Client.CoreV1(), info.Namespace)
-	if err != nil {
-		return nil, errors.Wrap(err, "cannot figure out the upstream IP for ironic proxy")
-	}
-
+func newIronicProxyPodTemplateSpec(info *ProvisioningInfo) *corev1.PodTemplateSpec {
 	containers := []corev1.Container{
-		// Even in a dual-stack environment, we don't really care which IP address to use since both are accessible internally.
-		createContainerIronicProxy(ironicIPs[0], info.Images, info.TLSProfileSpec),
+		createContainerIronicProxy(ironicUpstreamHost(info.Namespace), info.Images, info.TLSProfileSpec),
 	}
 
 	tolerations := []corev1.Toleration{
@@ -186,14 +195,11 @@ func newIronicProxyPodTemplateSpec(info *ProvisioningInfo) (*corev1.PodTemplateS
 			ServiceAccountName: "cluster-baremetal-operator",
 			Tolerations:        tolerations,
 		},
-	}, nil
+	}
 }
 
-func newIronicProxyDaemonSet(info *ProvisioningInfo) (*appsv1.DaemonSet, error) {
-	template, err := newIronicProxyPodTemplateSpec(info)
-	if err != nil {
-		return nil, err
-	}
+func newIronicProxyDaemonSet(info *ProvisioningInfo) *appsv1.DaemonSet {
+	template := newIronicProxyPodTemplateSpec(info)
 
 	maxUnavail := intstr.FromString("100%")
 	return &appsv1.DaemonSet{
@@ -218,27 +224,47 @@ func newIronicProxyDaemonSet(info *ProvisioningInfo) (*appsv1.DaemonSet, error)
 				},
 			},
 		},
-	}, nil
+	}
 }
 
+// UseIronicProxy tells whether the Ironic API is served by ironic-proxy on
+// the control plane nodes, as selected by the IronicProxy mode. It is never
+// used with an external control plane, as there are no nodes to run it on.
 func UseIronicProxy(info *ProvisioningInfo) bool {
-	// TODO(dtantsur): is it safe to use VirtualMediaViaExternalNetwork here?
 	if info.IsHyperShift {
 		return false
-	} else {
-		return info.ProvConfig.Spec.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled || info.ProvConfig.Spec.VirtualMediaViaExternalNetwork
+	}
+	switch info.ProvConfig.Spec.IronicProxy {
+	case metal3iov1alpha1.IronicProxyEnabled:
+		return true
+	case metal3iov1alpha1.IronicProxyDisabled:
+		return false
+	default:
+		return hostsUseExternalNetwork(&info.ProvConfig.Spec)
 	}
 }
 
+// hostsUseExternalNetwork tells whether the hosts reach Ironic over the
+// external network, where they may reach any of the control plane nodes,
+// rather than over the provisioning network.
+func hostsUseExternalNetwork(config *metal3iov1alpha1.ProvisioningSpec) bool {
+	return config.ProvisioningNetwork == metal3iov1alpha1.ProvisioningNetworkDisabled || config.VirtualMediaViaExternalNetwork
+}
+
+// GetIronicProxyMode returns the ironic-proxy mode in effect.
+func GetIronicProxyMode(info *ProvisioningInfo) metal3iov1alpha1.IronicProxyMode {
+	if UseIronicProxy(info) {
+		return metal3iov1alpha1.IronicProxyEnabled
+	}
+	return metal3iov1alpha1.IronicProxyDisabled
+}
+
 func EnsureIronicProxy(info *ProvisioningInfo) (updated bool, err error) {
 	if !UseIronicProxy(info) {
 		return
 	}
 
-	ironicProxyDaemonSet, err := newIronicProxyDaemonSet(info)
-	if err != nil {
-		return
-	}
+	ironicProxyDaemonSet := newIronicProxyDaemonSet(info)
 	expectedGeneration := resourcemerge.ExpectedDaemonSetGeneration(ironicProxyDaemonSet, info.ProvConfig.Status.Generations)
 
 	err = controllerutil.SetControllerReference(info.ProvConfig, ironicProxyDaemonSet, info.Scheme)
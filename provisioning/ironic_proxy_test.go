@@ -19,7 +19,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
 
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
@@ -70,6 +73,43 @@ func TestUseIronicProxy(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name: "Enabled with managed provisioning",
+			info: &ProvisioningInfo{
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: *managedProvisioning().IronicProxy(metal3iov1alpha1.IronicProxyEnabled).build(),
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "Disabled with ProvisioningNetwork Disabled",
+			info: &ProvisioningInfo{
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: *disabledProvisioning().IronicProxy(metal3iov1alpha1.IronicProxyDisabled).build(),
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "Auto with ProvisioningNetwork Disabled",
+			info: &ProvisioningInfo{
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: *disabledProvisioning().IronicProxy(metal3iov1alpha1.IronicProxyAuto).build(),
+				},
+			},
+			expected: true,
+		},
+		{
+			name: "HyperShift mode - ironic proxy disabled even when Enabled",
+			info: &ProvisioningInfo{
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: *managedProvisioning().IronicProxy(metal3iov1alpha1.IronicProxyEnabled).build(),
+				},
+				IsHyperShift: true,
+			},
+			expected: false,
+		},
 	}
 
 	for _, tc := range tCases {
@@ -80,6 +120,68 @@ func TestUseIronicProxy(t *testing.T) {
 	}
 }
 
+// TestIronicProxyModeConsistency checks that the Ironic port of the metal3
+// pod and service and the names in the Ironic certificate all follow the
+// ironic-proxy mode.
+func TestIronicProxyModeConsistency(t *testing.T) {
+	proxyHost := ironicProxyService + "." + testNamespace + ".svc"
+
+	tCases := []struct {
+		name       string
+		spec       *metal3iov1alpha1.ProvisioningSpec
+		expectedUp bool
+	}{
+		{
+			name:       "Auto with managed provisioning",
+			spec:       managedProvisioning().build(),
+			expectedUp: false,
+		},
+		{
+			name:       "Enabled with managed provisioning",
+			spec:       managedProvisioning().IronicProxy(metal3iov1alpha1.IronicProxyEnabled).build(),
+			expectedUp: true,
+		},
+		{
+			name:       "Auto with ProvisioningNetwork Disabled",
+			spec:       disabledProvisioning().build(),
+			expectedUp: true,
+		},
+		{
+			name:       "Disabled with ProvisioningNetwork Disabled",
+			spec:       disabledProvisioning().IronicProxy(metal3iov1alpha1.IronicProxyDisabled).build(),
+			expectedUp: false,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				Images:     &Images{},
+				Namespace:  testNamespace,
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.spec},
+			}
+			expectedPort, expectedMode := int32(baremetalIronicPort), metal3iov1alpha1.IronicProxyDisabled
+			if tc.expectedUp {
+				expectedPort, expectedMode = int32(ironicPrivatePort), metal3iov1alpha1.IronicProxyEnabled
+			}
+
+			assert.Equal(t, expectedMode, GetIronicProxyMode(info))
+
+			container := createContainerMetal3Httpd(info.Images, info)
+			assert.Equal(t, "ironic", container.Ports[0].Name)
+			assert.Equal(t, expectedPort, container.Ports[0].ContainerPort)
+
+			svc := newMetal3StateService(info)
+			assert.Equal(t, "ironic", svc.Spec.Ports[0].Name)
+			assert.Equal(t, expectedPort, svc.Spec.Ports[0].Port)
+
+			hosts, err := buildTlsHosts(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedUp, hosts.Has(proxyHost))
+		})
+	}
+}
+
 func TestNewIronicProxyService(t *testing.T) {
 	info := &ProvisioningInfo{
 		ProvConfig: &metal3iov1alpha1.Provisioning{
@@ -105,3 +207,72 @@ func TestNewIronicProxyService(t *testing.T) {
 	assert.Equal(t, "ironic-api", svc.Spec.Ports[0].Name, "Port name mismatch")
 	assert.Equal(t, int32(baremetalIronicPort), svc.Spec.Ports[0].Port, "Port should be 6385")
 }
+
+func TestNewIronicProxyPodTemplateSpec(t *testing.T) {
+	info := &ProvisioningInfo{
+		Client:    fakekube.NewSimpleClientset(),
+		Images:    &Images{Ironic: "ironic"},
+		Namespace: testNamespace,
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			Spec: *disabledProvisioning().build(),
+		},
+	}
+
+	// The template does not depend on the metal3 pod, which may not even
+	// have an IP yet.
+	template := newIronicProxyPodTemplateSpec(info)
+	assert.Len(t, template.Spec.Containers, 1)
+	assert.Contains(t, template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  ironicUpstreamHostEnvVar,
+		Value: "metal3-state." + testNamespace + ".svc.cluster.local",
+	})
+	assert.Contains(t, template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  ironicUpstreamIPEnvVar,
+		Value: "$(IRONIC_UPSTREAM_HOST)",
+	})
+	assert.Contains(t, template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  ironicUpstreamPortEnvVar,
+		Value: "6388",
+	})
+}
+
+func TestGetIronicProxyState(t *testing.T) {
+	tCases := []struct {
+		name     string
+		ready    int32
+		expected appsv1.DaemonSetConditionType
+	}{
+		{
+			name:     "proxy pods not ready",
+			ready:    2,
+			expected: DaemonSetProgressing,
+		},
+		{
+			name:     "proxy pods ready",
+			ready:    3,
+			expected: DaemonSetAvailable,
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			daemonSet := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: ironicProxyService, Namespace: testNamespace},
+				Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: tc.ready},
+			}
+			client := fakekube.NewSimpleClientset(daemonSet)
+			info := &ProvisioningInfo{
+				Client: client,
+				ProvConfig: &metal3iov1alpha1.Provisioning{
+					Spec: *disabledProvisioning().build(),
+				},
+			}
+
+			// The state of the proxy pods does not depend on Ironic being
+			// ready behind the metal3-state service.
+			state, err := GetIronicProxyState(client.AppsV1(), testNamespace, info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, state)
+		})
+	}
+}
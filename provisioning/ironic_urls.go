@@ -0,0 +1,135 @@
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
+)
+
+// The URLs under which the hosts reach Ironic may embed the IPs of the metal3
+// pod, which change whenever it moves to another node. They are kept in a
+// ConfigMap the dependent containers read their environment from, so that
+// their pod templates do not change with them. The ConfigMap is also mounted
+// in the containers, whose liveness probe fails once the mounted URLs differ
+// from those of their environment: they are then restarted in place and read
+// the new URLs, without rolling the components out.
+const (
+	ironicURLsConfigMapName = "metal3-ironic-urls"
+	ironicURLsMountPath     = "/etc/metal3-ironic-urls"
+	ironicIPsKey            = "IRONIC_IPS"
+)
+
+// ironicURLsLivenessScript fails when one of the given environment variables
+// differs from the key of the mounted Ironic URLs ConfigMap it was read from.
+const ironicURLsLivenessScript = `dir="$1"; shift
+for key in "$@"; do
+  [[ "$(cat "${dir}/${key}")" == "${!key}" ]] || exit 1
+done`
+
+// ironicURLsEnvVar returns an environment variable reading the given key of
+// the Ironic URLs ConfigMap.
+func ironicURLsEnvVar(key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: key,
+		ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ironicURLsConfigMapName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+func newIronicURLsConfigMap(info *ProvisioningInfo) (*corev1.ConfigMap, error) {
+	ironicIPs, err := GetIronicIPs(info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine Ironic's IP: %w", err)
+	}
+	externalIPv6Var, err := setIronicExternalIPv6(info)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine Ironic's external IPv6 URL: %w", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ironicURLsConfigMapName,
+			Namespace: info.Namespace,
+			Labels: map[string]string{
+				"k8s-app":    metal3AppName,
+				cboLabelName: stateService,
+			},
+		},
+		Data: map[string]string{
+			ironicIPsKey:       strings.Join(ironicIPs, ","),
+			ironicBaseUrl:      getUrlFromIP(ironicIPs, baremetalIronicPort),
+			ironicRootfsEnvVar: getRootfsURL(ironicIPs),
+			externalUrlEnvVar:  externalIPv6Var.Value,
+		},
+	}, nil
+}
+
+func ironicURLsVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: ironicURLsConfigMapName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ironicURLsConfigMapName},
+			},
+		},
+	}
+}
+
+// withIronicURLsLivenessProbe mounts the Ironic URLs ConfigMap in the
+// container and restarts it when the URLs of its environment are outdated.
+func withIronicURLsLivenessProbe(container corev1.Container) corev1.Container {
+	command := []string{"/bin/bash", "-c", ironicURLsLivenessScript, "liveness", ironicURLsMountPath}
+	for _, envVar := range container.Env {
+		if envVar.ValueFrom != nil && envVar.ValueFrom.ConfigMapKeyRef != nil &&
+			envVar.ValueFrom.ConfigMapKeyRef.Name == ironicURLsConfigMapName {
+			command = append(command, envVar.Name)
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      ironicURLsConfigMapName,
+		MountPath: ironicURLsMountPath,
+		ReadOnly:  true,
+	})
+	container.LivenessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: command},
+		},
+		PeriodSeconds:    30,
+		TimeoutSeconds:   5,
+		FailureThreshold: 1,
+	}
+	return container
+}
+
+// EnsureIronicURLs publishes the URLs of Ironic in the ConfigMap read by the
+// dependent components.
+func EnsureIronicURLs(info *ProvisioningInfo) (updated bool, err error) {
+	configMap, err := newIronicURLsConfigMap(info)
+	if err != nil {
+		return false, err
+	}
+	if err := controllerutil.SetControllerReference(info.ProvConfig, configMap, info.Scheme); err != nil {
+		return false, fmt.Errorf("unable to set controllerReference on Ironic URLs ConfigMap: %w", err)
+	}
+
+	_, updated, err = resourceapply.ApplyConfigMap(context.Background(), info.Client.CoreV1(), info.EventRecorder, configMap)
+	if err != nil {
+		return false, fmt.Errorf("unable to apply Ironic URLs ConfigMap: %w", err)
+	}
+	return updated, nil
+}
+
+func DeleteIronicURLs(info *ProvisioningInfo) error {
+	return client.IgnoreNotFound(info.Client.CoreV1().ConfigMaps(info.Namespace).Delete(context.Background(), ironicURLsConfigMapName, metav1.DeleteOptions{}))
+}
@@ -0,0 +1,231 @@
+package provisioning
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/clock"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+	fakeconfigclientset "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
+)
+
+func metal3TestPod(ips ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metal3",
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				"k8s-app":    metal3AppName,
+				cboLabelName: stateService,
+			},
+		},
+	}
+	for _, ip := range ips {
+		pod.Status.PodIPs = append(pod.Status.PodIPs, corev1.PodIP{IP: ip})
+	}
+	return pod
+}
+
+func newIronicURLsTestInfo(config *metal3iov1alpha1.ProvisioningSpec, objects ...runtime.Object) *ProvisioningInfo {
+	return &ProvisioningInfo{
+		Client:        fakekube.NewSimpleClientset(objects...),
+		EventRecorder: events.NewLoggingEventRecorder("test", clock.RealClock{}),
+		Scheme:        mirrorTestScheme,
+		Namespace:     testNamespace,
+		Images:        &Images{BaremetalOperator: expectedBaremetalOperator, IronicAgent: expectedIronicAgent},
+		NetworkStack:  NetworkStackV4,
+		Proxy: &osconfigv1.Proxy{
+			Status: osconfigv1.ProxyStatus{HTTPSProxy: "https://172.2.0.1:3128", NoProxy: ".example.com"},
+		},
+		ProvConfig: &metal3iov1alpha1.Provisioning{
+			ObjectMeta: metav1.ObjectMeta{Name: metal3iov1alpha1.ProvisioningSingletonName},
+			Spec:       *config,
+		},
+		// No API VIP, the metal3 pod IPs are used.
+		OSClient: fakeconfigclientset.NewSimpleClientset(&osconfigv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status: osconfigv1.InfrastructureStatus{
+				PlatformStatus: &osconfigv1.PlatformStatus{Type: osconfigv1.NonePlatformType},
+			},
+		}),
+	}
+}
+
+func TestNewIronicURLsConfigMap(t *testing.T) {
+	tCases := []struct {
+		name     string
+		config   *metal3iov1alpha1.ProvisioningSpec
+		expected map[string]string
+	}{
+		{
+			name:   "ManagedSpec with IPv4",
+			config: managedProvisioning().build(),
+			expected: map[string]string{
+				"IRONIC_IPS":             testProvisioningIP,
+				"IRONIC_BASE_URL":        "https://" + testProvisioningIP + ":6385",
+				"IRONIC_ROOTFS_URL":      "http://" + testProvisioningIP + ":6180/images/ironic-python-agent.rootfs",
+				"IRONIC_EXTERNAL_URL_V6": "",
+			},
+		},
+		{
+			name:   "ManagedSpec with IPv6",
+			config: managedIPv6Provisioning().build(),
+			expected: map[string]string{
+				"IRONIC_IPS":             testProvisioningIPv6,
+				"IRONIC_BASE_URL":        "https://[" + testProvisioningIPv6 + "]:6385",
+				"IRONIC_ROOTFS_URL":      "http://[" + testProvisioningIPv6 + "]:6180/images/ironic-python-agent.rootfs",
+				"IRONIC_EXTERNAL_URL_V6": "https://[" + testProvisioningIPv6 + "]:6183",
+			},
+		},
+		{
+			name:   "DisabledSpec",
+			config: disabledProvisioning().build(),
+			expected: map[string]string{
+				"IRONIC_IPS":             "192.168.111.22,fd2e:6f44:5dd8:c956::16",
+				"IRONIC_BASE_URL":        "https://192.168.111.22:6385,https://[fd2e:6f44:5dd8:c956::16]:6385",
+				"IRONIC_ROOTFS_URL":      "http://192.168.111.22:6180/images/ironic-python-agent.rootfs",
+				"IRONIC_EXTERNAL_URL_V6": "https://[fd2e:6f44:5dd8:c956::16]:6183",
+			},
+		},
+		{
+			name:   "DisabledSpec with external IPs",
+			config: disabledProvisioning().ExternalIPs([]string{"192.168.111.5"}).build(),
+			expected: map[string]string{
+				"IRONIC_IPS":             "192.168.111.5",
+				"IRONIC_BASE_URL":        "https://192.168.111.5:6385",
+				"IRONIC_ROOTFS_URL":      "http://192.168.111.5:6180/images/ironic-python-agent.rootfs",
+				"IRONIC_EXTERNAL_URL_V6": "",
+			},
+		},
+	}
+
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := newIronicURLsTestInfo(tc.config, metal3TestPod("192.168.111.22", "fd2e:6f44:5dd8:c956::16"))
+			configMap, err := newIronicURLsConfigMap(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, configMap.Data)
+		})
+	}
+}
+
+func TestIronicURLsConsumersStableOnPodIPChange(t *testing.T) {
+	type rendered struct {
+		bmo, icc, proxy *corev1.PodTemplateSpec
+		iccConfig       *corev1.Secret
+		urls            map[string]string
+	}
+	render := func(ip string) rendered {
+		info := newIronicURLsTestInfo(disabledProvisioning().build(), metal3TestPod(ip))
+		_, err := EnsureIronicURLs(info)
+		assert.NoError(t, err)
+		bmoTemplate, err := newBMOPodTemplateSpec(info, &map[string]string{})
+		assert.NoError(t, err)
+		configMap, err := newIronicURLsConfigMap(info)
+		assert.NoError(t, err)
+		return rendered{
+			bmo:       bmoTemplate,
+			icc:       newImageCustomizationPodTemplateSpec(info, &map[string]string{}),
+			proxy:     newIronicProxyPodTemplateSpec(info),
+			iccConfig: newImageCustomizationConfig(info),
+			urls:      configMap.Data,
+		}
+	}
+
+	before := render("192.168.111.21")
+	after := render("192.168.111.22")
+	assert.NotEqual(t, before.urls, after.urls)
+	assert.Equal(t, before.bmo, after.bmo, "the BMO pod template must not depend on the metal3 pod IP")
+	assert.Equal(t, before.icc, after.icc, "the ICC pod template must not depend on the metal3 pod IP")
+	assert.Equal(t, before.proxy, after.proxy, "the ironic-proxy pod template must not depend on the metal3 pod IP")
+	assert.Equal(t, before.iccConfig, after.iccConfig, "the ICC config must not depend on the metal3 pod IP")
+}
+
+func TestWithIronicURLsLivenessProbe(t *testing.T) {
+	container := withIronicURLsLivenessProbe(corev1.Container{
+		Env: []corev1.EnvVar{
+			{Name: "IRONIC_INSECURE", Value: "true"},
+			ironicURLsEnvVar(ironicBaseUrl),
+			ironicURLsEnvVar(ironicRootfsEnvVar),
+		},
+	})
+
+	assert.Equal(t, []corev1.VolumeMount{{Name: ironicURLsConfigMapName, MountPath: ironicURLsMountPath, ReadOnly: true}}, container.VolumeMounts)
+	assert.Equal(t,
+		[]string{"/bin/bash", "-c", ironicURLsLivenessScript, "liveness", ironicURLsMountPath, ironicBaseUrl, ironicRootfsEnvVar},
+		container.LivenessProbe.Exec.Command)
+}
+
+func TestIronicURLsLivenessScript(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ironicBaseUrl), []byte("https://192.168.111.22:6385"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, externalUrlEnvVar), []byte(""), 0o644))
+
+	testCases := []struct {
+		name    string
+		baseURL string
+		live    bool
+	}{
+		{
+			name:    "current URLs",
+			baseURL: "https://192.168.111.22:6385",
+			live:    true,
+		},
+		{
+			name:    "outdated URLs",
+			baseURL: "https://192.168.111.21:6385",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command("/bin/bash", "-c", ironicURLsLivenessScript, "liveness", dir, ironicBaseUrl, externalUrlEnvVar)
+			cmd.Env = []string{ironicBaseUrl + "=" + tc.baseURL, externalUrlEnvVar + "="}
+			err := cmd.Run()
+			assert.Equal(t, tc.live, err == nil, "%v", err)
+		})
+	}
+}
+
+func TestEnsureIronicURLs(t *testing.T) {
+	info := newIronicURLsTestInfo(disabledProvisioning().build(), metal3TestPod("192.168.111.21"))
+	client := info.Client.(*fakekube.Clientset)
+
+	updated, err := EnsureIronicURLs(info)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	updated, err = EnsureIronicURLs(info)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	// metal3 moves to another node
+	pod := metal3TestPod("192.168.111.22")
+	_, err = client.CoreV1().Pods(testNamespace).UpdateStatus(context.Background(), pod, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+
+	updated, err = EnsureIronicURLs(info)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	for _, action := range client.Actions() {
+		assert.False(t, action.Matches("delete-collection", "pods"), "pods must not be deleted")
+		assert.False(t, action.Matches("delete", "pods"), "pods must not be deleted")
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(testNamespace).Get(context.Background(), ironicURLsConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://192.168.111.22:6385", configMap.Data[ironicBaseUrl])
+}
@@ -45,13 +45,17 @@ type ProvisioningInfo struct {
 	// TLSProfileSpec is the cluster-wide TLS security profile to enforce on
 	// managed components (Ironic, BMO). When nil, no TLS profile env vars or
 	// args are injected and components use their defaults.
-	TLSProfileSpec          *configv1.TLSProfileSpec
-	NetworkStack            NetworkStackType
-	MasterMacAddresses      []string
-	SSHKey                  string
-	BaremetalWebhookEnabled bool
-	OSClient                osclientset.Interface
-	ResourceCache           resourceapply.ResourceCache
-	IsHyperShift            bool
-	MirrorConfigHash        string
+	TLSProfileSpec     *configv1.TLSProfileSpec
+	NetworkStack       NetworkStackType
+	MasterMacAddresses []string
+	// UnusableProvisioningNodes are the master nodes known to have no
+	// network interface usable for provisioning. The metal3 pod is kept
+	// off these nodes.
+	UnusableProvisioningNodes []string
+	SSHKey                    string
+	BaremetalWebhookEnabled   bool
+	OSClient                  osclientset.Interface
+	ResourceCache             resourceapply.ResourceCache
+	IsHyperShift              bool
+	MirrorConfigHash          string
 }
@@ -0,0 +1,38 @@
+#!/bin/bash
+#
+# Creates the provisioning VLAN subinterface PROVISIONING_INTERFACE, with
+# the ID PROVISIONING_VLAN_ID, on top of PROVISIONING_VLAN_PARENT or, when it
+# is not set, on top of the interface with one of the comma separated
+# PROVISIONING_MACS. The slaves of a bond share its MAC address, so they are
+# never picked as the parent. An existing subinterface is kept as is.
+
+set -eu
+
+if ip link show dev "${PROVISIONING_INTERFACE}" >/dev/null 2>&1; then
+    echo "${PROVISIONING_INTERFACE} already exists"
+    ip link set dev "${PROVISIONING_INTERFACE}" up
+    exit 0
+fi
+
+parent=${PROVISIONING_VLAN_PARENT:-}
+if [[ -z "${parent}" ]]; then
+    IFS=, read -ra macs <<< "${PROVISIONING_MACS:-}"
+    for dev in /sys/class/net/*; do
+        [[ -e "${dev}/master" ]] && continue
+        address=$(cat "${dev}/address" 2>/dev/null || true)
+        for mac in "${macs[@]}"; do
+            if [[ -n "${address}" && "${address}" == "${mac,,}" ]]; then
+                parent=$(basename "${dev}")
+                break 2
+            fi
+        done
+    done
+fi
+if [[ -z "${parent}" ]]; then
+    echo "no interface matches ${PROVISIONING_MACS:-}, cannot create ${PROVISIONING_INTERFACE}" >&2
+    exit 1
+fi
+
+echo "creating ${PROVISIONING_INTERFACE} with VLAN ${PROVISIONING_VLAN_ID} on ${parent}"
+ip link add link "${parent}" name "${PROVISIONING_INTERFACE}" type vlan id "${PROVISIONING_VLAN_ID}"
+ip link set dev "${PROVISIONING_INTERFACE}" up
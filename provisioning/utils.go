@@ -15,12 +15,18 @@ import (
 	metal3iov1alpha1 "github.com/openshift/cluster-baremetal-operator/api/v1alpha1"
 )
 
+// Metal3PodLabels returns the labels of the metal3 pod.
+func Metal3PodLabels() map[string]string {
+	return map[string]string{
+		"k8s-app":    metal3AppName,
+		cboLabelName: stateService,
+	}
+}
+
 func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.Pod, error) {
 	labelSelector := &metav1.LabelSelector{
-		MatchLabels: map[string]string{
-			"k8s-app":    metal3AppName,
-			cboLabelName: stateService,
-		}}
+		MatchLabels: Metal3PodLabels(),
+	}
 
 	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
 	if err != nil {
@@ -56,6 +62,11 @@ func getPod(podClient coreclientv1.PodsGetter, targetNamespace string) (corev1.P
 	return pods[0], nil
 }
 
+// ErrNoMetal3PodIPs is returned when the URLs of Ironic depend on the IPs of
+// the metal3 pod, and it has none yet: it is still starting, or moving to
+// another node.
+var ErrNoMetal3PodIPs = errors.New("the metal3 pod does not have any podIP's yet")
+
 // getPodIPs returns pod IPs for the Metal3 pod (and thus Ironic and its httpd).
 func getPodIPs(podClient coreclientv1.PodsGetter, targetNamespace string) (ips []string, err error) {
 	pod, err := getPod(podClient, targetNamespace)
@@ -70,7 +81,7 @@ func getPodIPs(podClient coreclientv1.PodsGetter, targetNamespace string) (ips [
 	}
 	if len(ips) == 0 {
 		// This is basically a safeguard to be able to assume that the returned slice is not empty later on
-		err = errors.New("the metal3 pod does not have any podIP's yet")
+		err = ErrNoMetal3PodIPs
 	}
 	return
 }
@@ -143,7 +154,10 @@ func GetIronicIPs(info *ProvisioningInfo) (ironicIPs []string, err error) {
 		return
 	}
 
-	if UseIronicProxy(info) {
+	// Over the external network, the hosts reach ironic-proxy on the API
+	// VIPs. Over the provisioning network, they reach the one running next
+	// to the metal3 pod.
+	if UseIronicProxy(info) && hostsUseExternalNetwork(&info.ProvConfig.Spec) {
 		ironicIPs, err = getServerInternalIPs(info.OSClient)
 		if err != nil {
 			err = fmt.Errorf("error fetching internalIPs: %w", err)
@@ -174,6 +188,60 @@ func GetImageServerIPs(info *ProvisioningInfo) (imageServerIPs []string, err err
 	return imageServerIPs, nil
 }
 
+// getExternalIP returns the first of the external IPs belonging to the
+// requested IP family, or an empty string if there is none.
+func getExternalIP(externalIPs []string, ipv6 bool) string {
+	for _, ip := range externalIPs {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			continue
+		}
+		if (parsed.To4() == nil) == ipv6 {
+			return ip
+		}
+	}
+	return ""
+}
+
+// GetExternalURLs returns the Ironic and virtual media URLs handed to each
+// consumer, for reporting in the status of the Provisioning CR.
+func GetExternalURLs(info *ProvisioningInfo) ([]metal3iov1alpha1.ExternalURL, error) {
+	var urls []metal3iov1alpha1.ExternalURL
+	config := &info.ProvConfig.Spec
+
+	ironicIPs, err := GetIronicIPs(info)
+	if err != nil {
+		return nil, err
+	}
+	if ironicURL := getUrlFromIP(ironicIPs, baremetalIronicPort); ironicURL != "" {
+		urls = append(urls, metal3iov1alpha1.ExternalURL{
+			Consumer: metal3iov1alpha1.ExternalURLConsumerImageCustomization,
+			URL:      ironicURL,
+		})
+	}
+
+	externalIP := setIronicExternalIp(externalIpEnvVar, config)
+	if externalIP.Value != "" {
+		urls = append(urls, metal3iov1alpha1.ExternalURL{
+			Consumer: metal3iov1alpha1.ExternalURLConsumerVirtualMedia,
+			URL:      getVirtualMediaURL(externalIP.Value, config),
+		})
+	}
+
+	externalURLv6, err := setIronicExternalIPv6(info)
+	if err != nil {
+		return nil, err
+	}
+	if externalURLv6.Value != "" {
+		urls = append(urls, metal3iov1alpha1.ExternalURL{
+			Consumer: metal3iov1alpha1.ExternalURLConsumerVirtualMediaIPv6,
+			URL:      externalURLv6.Value,
+		})
+	}
+
+	return urls, nil
+}
+
 func IpOptionForProvisioning(config *metal3iov1alpha1.ProvisioningSpec, networkStack NetworkStackType) string {
 	var optionValue string
 	ip := net.ParseIP(config.ProvisioningIP)
@@ -189,3 +189,77 @@ func TestGetIronicIPs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExternalURLs(t *testing.T) {
+	metal3Pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-machine-api",
+			Labels: map[string]string{
+				"k8s-app":    metal3AppName,
+				cboLabelName: stateService,
+			},
+		},
+		Status: corev1.PodStatus{
+			PodIPs: []corev1.PodIP{
+				{IP: "192.168.111.22"},
+			},
+		},
+	}
+
+	tCases := []struct {
+		name         string
+		spec         *metal3iov1alpha1.ProvisioningSpec
+		expectedURLs []metal3iov1alpha1.ExternalURL
+	}{
+		{
+			name: "dual-stack ExternalIPs",
+			spec: disabledProvisioning().ExternalIPs([]string{"fd00::5", "10.0.0.5"}).build(),
+			expectedURLs: []metal3iov1alpha1.ExternalURL{
+				{Consumer: metal3iov1alpha1.ExternalURLConsumerImageCustomization, URL: "https://[fd00::5]:6385,https://10.0.0.5:6385"},
+				{Consumer: metal3iov1alpha1.ExternalURLConsumerVirtualMedia, URL: "https://10.0.0.5:6183"},
+				{Consumer: metal3iov1alpha1.ExternalURLConsumerVirtualMediaIPv6, URL: "https://[fd00::5]:6183"},
+			},
+		},
+		{
+			name: "IPv4 ExternalIP without TLS",
+			spec: disabledProvisioning().ExternalIPs([]string{"10.0.0.5"}).DisableVirtualMediaTLS(true).build(),
+			expectedURLs: []metal3iov1alpha1.ExternalURL{
+				{Consumer: metal3iov1alpha1.ExternalURLConsumerImageCustomization, URL: "https://10.0.0.5:6385"},
+				{Consumer: metal3iov1alpha1.ExternalURLConsumerVirtualMedia, URL: "http://10.0.0.5:6180"},
+			},
+		},
+		{
+			name: "no ExternalIPs",
+			spec: managedProvisioning().build(),
+			expectedURLs: []metal3iov1alpha1.ExternalURL{
+				{Consumer: metal3iov1alpha1.ExternalURLConsumerImageCustomization, URL: "https://172.30.20.3:6385"},
+			},
+		},
+	}
+	for _, tc := range tCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := &ProvisioningInfo{
+				ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *tc.spec},
+				Namespace:  "openshift-machine-api",
+				Client:     fakekube.NewSimpleClientset(metal3Pod),
+				OSClient:   fakeconfigclientset.NewSimpleClientset(),
+			}
+			urls, err := GetExternalURLs(info)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedURLs, urls)
+		})
+	}
+
+	t.Run("metal3 pod without IPs", func(t *testing.T) {
+		pod := metal3Pod.DeepCopy()
+		pod.Status.PodIPs = nil
+		info := &ProvisioningInfo{
+			ProvConfig: &metal3iov1alpha1.Provisioning{Spec: *disabledProvisioning().build()},
+			Namespace:  "openshift-machine-api",
+			Client:     fakekube.NewSimpleClientset(pod),
+			OSClient:   fakeconfigclientset.NewSimpleClientset(),
+		}
+		_, err := GetExternalURLs(info)
+		assert.ErrorIs(t, err, ErrNoMetal3PodIPs)
+	})
+}
@@ -32,28 +32,44 @@ import (
 	"github.com/openshift/library-go/pkg/operator/resource/resourceapply"
 )
 
-func EnableValidatingWebhook(info *ProvisioningInfo, mgr manager.Manager, enabledFeatures metal3iov1alpha1.EnabledFeatures) error {
+var webhookConfigurationAnnotations = map[string]string{
+	"include.release.openshift.io/self-managed-high-availability": "true",
+	"include.release.openshift.io/single-node-developer":          "true",
+	"service.beta.openshift.io/inject-cabundle":                   "true",
+}
+
+var provisioningWebhookRules = []admissionregistration.RuleWithOperations{
+	{
+		Operations: []admissionregistration.OperationType{
+			admissionregistration.Create,
+			admissionregistration.Update,
+		},
+		Rule: admissionregistration.Rule{
+			Resources:   []string{"provisionings"},
+			APIGroups:   []string{"metal3.io"},
+			APIVersions: []string{"v1alpha1"},
+		},
+	},
+}
+
+func newValidatingWebhookConfiguration(namespace string) *admissionregistration.ValidatingWebhookConfiguration {
 	ignore := admissionregistration.Ignore
 	noSideEffects := admissionregistration.SideEffectClassNone
-	instance := &admissionregistration.ValidatingWebhookConfiguration{
+	return &admissionregistration.ValidatingWebhookConfiguration{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ValidatingWebhookConfiguration",
 			APIVersion: "admissionregistration.k8s.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "cluster-baremetal-validating-webhook-configuration",
-			Annotations: map[string]string{
-				"include.release.openshift.io/self-managed-high-availability": "true",
-				"include.release.openshift.io/single-node-developer":          "true",
-				"service.beta.openshift.io/inject-cabundle":                   "true",
-			},
+			Name:        "cluster-baremetal-validating-webhook-configuration",
+			Annotations: webhookConfigurationAnnotations,
 		},
 		Webhooks: []admissionregistration.ValidatingWebhook{
 			{
 				ClientConfig: admissionregistration.WebhookClientConfig{
 					Service: &admissionregistration.ServiceReference{
 						Name:      "cluster-baremetal-webhook-service",
-						Namespace: info.Namespace,
+						Namespace: namespace,
 						Path:      ptr.To("/validate-metal3-io-v1alpha1-provisioning"),
 					},
 				},
@@ -61,6 +77,51 @@ func EnableValidatingWebhook(info *ProvisioningInfo, mgr manager.Manager, enable
 				FailurePolicy:           &ignore,
 				AdmissionReviewVersions: []string{"v1", "v1beta1"},
 				Name:                    "vprovisioning.kb.io",
+				Rules:                   provisioningWebhookRules,
+			},
+		},
+	}
+}
+
+func newMutatingWebhookConfiguration(namespace string) *admissionregistration.MutatingWebhookConfiguration {
+	ignore := admissionregistration.Ignore
+	noSideEffects := admissionregistration.SideEffectClassNone
+	return &admissionregistration.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MutatingWebhookConfiguration",
+			APIVersion: "admissionregistration.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster-baremetal-mutating-webhook-configuration",
+			Annotations: webhookConfigurationAnnotations,
+		},
+		Webhooks: []admissionregistration.MutatingWebhook{
+			{
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					Service: &admissionregistration.ServiceReference{
+						Name:      "cluster-baremetal-webhook-service",
+						Namespace: namespace,
+						Path:      ptr.To("/mutate-metal3-io-v1alpha1-provisioning"),
+					},
+				},
+				SideEffects:             &noSideEffects,
+				FailurePolicy:           &ignore,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+				Name:                    "mprovisioning.kb.io",
+				Rules:                   provisioningWebhookRules,
+			},
+			{
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					Service: &admissionregistration.ServiceReference{
+						Name:      "cluster-baremetal-webhook-service",
+						Namespace: namespace,
+						Path:      ptr.To(hostWebhookPath),
+					},
+				},
+				SideEffects:             &noSideEffects,
+				FailurePolicy:           &ignore,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+				Name:                    "mbaremetalhost.kb.io",
 				Rules: []admissionregistration.RuleWithOperations{
 					{
 						Operations: []admissionregistration.OperationType{
@@ -68,21 +129,40 @@ func EnableValidatingWebhook(info *ProvisioningInfo, mgr manager.Manager, enable
 							admissionregistration.Update,
 						},
 						Rule: admissionregistration.Rule{
-							Resources:   []string{"provisionings"},
+							Resources:   []string{"baremetalhosts"},
 							APIGroups:   []string{"metal3.io"},
 							APIVersions: []string{"v1alpha1"},
 						},
 					},
 				},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"kubernetes.io/metadata.name": namespace},
+				},
 			},
 		},
 	}
+}
+
+// EnableWebhooks registers the validating and defaulting webhooks of the
+// Provisioning CR, and the defaulting webhook of the BareMetalHosts, with
+// the API server and serves them from the manager.
+func EnableWebhooks(info *ProvisioningInfo, mgr manager.Manager, enabledFeatures metal3iov1alpha1.EnabledFeatures) error {
 	_, _, err := resourceapply.ApplyValidatingWebhookConfigurationImproved(context.Background(),
-		info.Client.AdmissionregistrationV1(), info.EventRecorder, instance, info.ResourceCache)
+		info.Client.AdmissionregistrationV1(), info.EventRecorder, newValidatingWebhookConfiguration(info.Namespace), info.ResourceCache)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = resourceapply.ApplyMutatingWebhookConfigurationImproved(context.Background(),
+		info.Client.AdmissionregistrationV1(), info.EventRecorder, newMutatingWebhookConfiguration(info.Namespace), info.ResourceCache)
 	if err != nil {
 		return err
 	}
 
+	if err := setupHostWebhook(mgr); err != nil {
+		return err
+	}
+
 	return (&metal3iov1alpha1.Provisioning{}).SetupWebhookWithManager(mgr, enabledFeatures)
 }
 